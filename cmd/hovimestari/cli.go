@@ -7,15 +7,25 @@ type CLI struct {
 	Config   string `kong:"help='Path to configuration file',short='c'"`
 	LogLevel string `kong:"help='Log level (debug, info, warn, error)',default='debug'"`
 
-	Version            VersionCmd                     `kong:"cmd,help='Print version information and exit'"`
-	ImportCalendar     commands.ImportCalendarCmd     `kong:"cmd,help='Import calendar events from WebCal URLs'"`
-	ImportWeather      commands.ImportWeatherCmd      `kong:"cmd,help='Import weather forecasts from MET Norway API'"`
-	ImportWaterQuality commands.ImportWaterQualityCmd `kong:"cmd,help='Import water quality data for specific locations'"`
+	Version                VersionCmd                         `kong:"cmd,help='Print version information and exit'"`
+	ImportCalendar         commands.ImportCalendarCmd         `kong:"cmd,help='Import calendar events from WebCal URLs'"`
+	ImportWeather          commands.ImportWeatherCmd          `kong:"cmd,help='Import weather forecasts from MET Norway API'"`
+	ImportWaterQuality     commands.ImportWaterQualityCmd     `kong:"cmd,help='Import water quality data for specific locations'"`
 	ImportSchoolLunch      commands.ImportSchoolLunchCmd      `kong:"cmd,help='Import school lunch menus'"`
 	ImportElectricityPrice commands.ImportElectricityPriceCmd `kong:"cmd,help='Import electricity prices from ENTSO-E'"`
-	GenerateBrief      commands.GenerateBriefCmd      `kong:"cmd,help='Generate and send daily brief'"`
-	ShowBriefContext   commands.ShowBriefContextCmd   `kong:"cmd,help='Show context given to LLM without generating brief'"`
-	AddMemory          commands.AddMemoryCmd          `kong:"cmd,help='Add memory manually to database'"`
-	InitConfig         commands.InitConfigCmd         `kong:"cmd,help='Initialize configuration file'"`
-	ListModels         commands.ListModelsCmd         `kong:"cmd,help='List available Gemini models'"`
+	GenerateBrief          commands.GenerateBriefCmd          `kong:"cmd,help='Generate and send daily brief'"`
+	Ask                    commands.AskCmd                    `kong:"cmd,help='Answer a question from stored memories and calendar events'"`
+	Chat                   commands.ChatCmd                   `kong:"cmd,help='Have a conversation with the butler'"`
+	ShowBriefContext       commands.ShowBriefContextCmd       `kong:"cmd,help='Show context given to LLM without generating brief'"`
+	AddMemory              commands.AddMemoryCmd              `kong:"cmd,help='Add memory manually to database'"`
+	Memory                 commands.MemoryCmd                 `kong:"cmd,help='List, show, edit and delete memories'"`
+	Prune                  commands.PruneCmd                  `kong:"cmd,help='Delete expired and superseded memories according to the retention rules'"`
+	Search                 commands.SearchCmd                 `kong:"cmd,help='Search memories and calendar events by keywords'"`
+	Remember               commands.RememberCmd               `kong:"cmd,help='Add a memory from natural language, with the date extracted by the LLM'"`
+	InitConfig             commands.InitConfigCmd             `kong:"cmd,help='Initialize configuration file'"`
+	ListModels             commands.ListModelsCmd             `kong:"cmd,help='List available LLM models'"`
+	Prompts                commands.PromptsCmd                `kong:"cmd,help='Manage LLM prompt templates'"`
+	Usage                  commands.UsageCmd                  `kong:"cmd,help='Show LLM token usage and estimated cost by day and month'"`
+	Eval                   commands.EvalCmd                   `kong:"cmd,help='Run dailyBrief prompt variants against saved brief contexts and check the results'"`
+	DB                     commands.DBCmd                     `kong:"cmd,name='db',help='Manage the database'"`
 }
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -12,21 +13,30 @@ import (
 
 // AddMemoryCmd defines the add memory command for Kong
 type AddMemoryCmd struct {
-	Content       string `kong:"help='Memory content',required"`
-	RelevanceDate string `kong:"help='Relevance date (YYYY-MM-DD)'"`
-	Source        string `kong:"help='Memory source',default='manual'"`
+	Content        string `kong:"help='Memory content',required"`
+	RelevanceDate  string `kong:"help='Relevance date (YYYY-MM-DD)'"`
+	RelevanceStart string `kong:"help='First day of a relevance range (YYYY-MM-DD)'"`
+	RelevanceEnd   string `kong:"help='Last day of a relevance range (YYYY-MM-DD)'"`
+	Source         string `kong:"help='Memory source',default='manual'"`
 }
 
 // Run executes the add memory command
 func (cmd *AddMemoryCmd) Run() error {
-	return runAddMemory(context.Background(), cmd.Content, cmd.RelevanceDate, cmd.Source)
+	return runAddMemory(context.Background(), cmd.Content, cmd.RelevanceDate, cmd.RelevanceStart, cmd.RelevanceEnd, cmd.Source)
 }
 
 // runAddMemory runs the add memory command, adding a new memory to the database with
-// the specified content, relevance date, and source. The relevance date is optional
-// and can be provided in YYYY-MM-DD format. If not provided, the memory will be
-// considered relevant for all dates.
-func runAddMemory(ctx context.Context, content, relevanceDateStr, source string) error {
+// the specified content, relevance date or range, and source. The relevance date is
+// optional and can be provided in YYYY-MM-DD format, or a range can be given with a
+// start and an end date. If neither is provided, the memory will be considered relevant
+// for all dates.
+func runAddMemory(ctx context.Context, content, relevanceDateStr, relevanceStartStr, relevanceEndStr, source string) error {
+	// Parse the relevance date or range if provided
+	start, end, err := parseRelevance(relevanceDateStr, relevanceStartStr, relevanceEndStr)
+	if err != nil {
+		return err
+	}
+
 	// Get the configuration
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -49,18 +59,8 @@ func runAddMemory(ctx context.Context, content, relevanceDateStr, source string)
 		return fmt.Errorf("failed to initialize store: %w", err)
 	}
 
-	// Parse the relevance date if provided
-	var relevanceDate *time.Time
-	if relevanceDateStr != "" {
-		date, err := time.Parse("2006-01-02", relevanceDateStr)
-		if err != nil {
-			return fmt.Errorf("failed to parse relevance date: %w", err)
-		}
-		relevanceDate = &date
-	}
-
 	// Add the memory
-	id, err := store.AddMemory(content, relevanceDate, source, nil)
+	id, err := store.AddMemoryRange(content, start, end, source, nil)
 	if err != nil {
 		return fmt.Errorf("failed to add memory: %w", err)
 	}
@@ -68,3 +68,38 @@ func runAddMemory(ctx context.Context, content, relevanceDateStr, source string)
 	slog.Info("Memory added successfully", "id", id)
 	return nil
 }
+
+// parseRelevance parses a relevance date, or a range from start to end, into the first
+// and last day of relevance. A start without an end is a single day, and nothing at all
+// means relevant on all dates.
+func parseRelevance(dateStr, startStr, endStr string) (*time.Time, *time.Time, error) {
+	if dateStr != "" && (startStr != "" || endStr != "") {
+		return nil, nil, errors.New("give either a relevance date or a relevance start and end, not both")
+	}
+	if dateStr != "" {
+		startStr = dateStr
+	}
+	if startStr == "" {
+		if endStr != "" {
+			return nil, nil, errors.New("a relevance end needs a relevance start")
+		}
+		return nil, nil, nil
+	}
+	if endStr == "" {
+		endStr = startStr
+	}
+
+	start, err := time.Parse("2006-01-02", startStr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse relevance date: %w", err)
+	}
+	end, err := time.Parse("2006-01-02", endStr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse relevance end date: %w", err)
+	}
+	if end.Before(start) {
+		return nil, nil, fmt.Errorf("relevance range ends on %s before it starts on %s", endStr, startStr)
+	}
+
+	return &start, &end, nil
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/lepinkainen/hovimestari/internal/brief"
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// AskCmd defines the ask command for Kong
+type AskCmd struct {
+	Query string `kong:"arg,help='Question to ask in natural language'"`
+	Fresh bool   `kong:"help='Always call the LLM instead of reusing a cached response for an identical prompt'"`
+}
+
+// Run executes the ask command
+func (cmd *AskCmd) Run() error {
+	return runAsk(context.Background(), cmd.Query, cmd.Fresh)
+}
+
+// runAsk runs the ask command, answering a question with the LLM based on the stored
+// memories and calendar events, and printing the answer.
+func runAsk(ctx context.Context, query string, fresh bool) error {
+	// Get the configuration
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	// Create the store
+	store, err := store.NewStore(cfg.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			slog.Error("Failed to close store", "error", err)
+		}
+	}()
+
+	// Initialize the store
+	if err := store.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize store: %w", err)
+	}
+
+	llmClient, err := newLLMProvider(cfg, store)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := llmClient.Close(); err != nil {
+			slog.Error("Failed to close LLM client", "error", err)
+		}
+	}()
+
+	// Retrieve the memories most similar to the question, if embeddings are configured
+	generator := brief.NewGenerator(store, llmClient, cfg)
+	enableResponseCache(cfg, store, llmClient, generator, fresh, "userQuery")
+	closeEmbedder, err := enableMemoryRetrieval(cfg, store, generator)
+	if err != nil {
+		return err
+	}
+	defer closeEmbedder()
+
+	if err := enableTools(cfg, generator); err != nil {
+		return err
+	}
+
+	// Answer the question
+	answer, err := generator.GenerateResponse(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(answer)
+	return nil
+}
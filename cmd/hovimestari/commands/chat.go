@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/lepinkainen/hovimestari/internal/brief"
+	"github.com/lepinkainen/hovimestari/internal/chat"
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// ChatCmd defines the chat command for Kong
+type ChatCmd struct {
+	Session string `kong:"help='Name of the conversation session to continue',default='cli'"`
+	Reset   bool   `kong:"help='Forget the history of the session before starting'"`
+}
+
+// Run executes the chat command
+func (cmd *ChatCmd) Run() error {
+	return runChat(context.Background(), cmd.Session, cmd.Reset)
+}
+
+// runChat runs the chat command, an interactive conversation with the butler. Each
+// message is answered from the stored memories and calendar events together with the
+// earlier conversation, which is kept in the database between runs.
+func runChat(ctx context.Context, sessionID string, reset bool) error {
+	// Get the configuration
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	// Create the store
+	store, err := store.NewStore(cfg.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			slog.Error("Failed to close store", "error", err)
+		}
+	}()
+
+	// Initialize the store
+	if err := store.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize store: %w", err)
+	}
+
+	llmClient, err := newLLMProvider(cfg, store)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := llmClient.Close(); err != nil {
+			slog.Error("Failed to close LLM client", "error", err)
+		}
+	}()
+
+	prompts, err := config.LoadPrompts(cfg.PromptFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	// Get output language from config, default to Finnish if not specified
+	outputLanguage := cfg.OutputLanguage
+	if outputLanguage == "" {
+		outputLanguage = "Finnish"
+	}
+
+	generator := brief.NewGenerator(store, llmClient, cfg)
+	closeEmbedder, err := enableMemoryRetrieval(cfg, store, generator)
+	if err != nil {
+		return err
+	}
+	defer closeEmbedder()
+
+	session := chat.NewSession(sessionID, store, llmClient, prompts, generator.BuildQueryContext, outputLanguage, cfg.ChatHistoryTurns)
+
+	if reset {
+		if err := session.Reset(); err != nil {
+			return fmt.Errorf("failed to reset session: %w", err)
+		}
+	}
+
+	fmt.Printf("Chatting in session %q. Type \"exit\" or press Ctrl-D to quit.\n", session.ID())
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print("> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			break
+		}
+
+		message := strings.TrimSpace(scanner.Text())
+		if message == "" {
+			continue
+		}
+		if message == "exit" || message == "quit" {
+			break
+		}
+
+		reply, err := session.Send(ctx, message)
+		if err != nil {
+			// Keep the conversation going, the next message may well succeed
+			slog.Error("Failed to answer", "error", err)
+			continue
+		}
+
+		fmt.Printf("\n%s\n\n", reply)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// DBCmd groups the database management subcommands for Kong
+type DBCmd struct {
+	Migrate DBMigrateCmd `kong:"cmd,help='Apply pending database schema migrations'"`
+}
+
+// DBMigrateCmd defines the db migrate command for Kong
+type DBMigrateCmd struct {
+	Status bool `kong:"help='Show the applied and pending migrations without applying anything'"`
+}
+
+// Run executes the db migrate command
+func (cmd *DBMigrateCmd) Run() error {
+	return runDBMigrate(context.Background(), cmd.Status)
+}
+
+// runDBMigrate runs the db migrate command, applying the pending schema migrations or,
+// with status, listing every migration and when it was applied. Other commands apply the
+// pending migrations when they open the database, so this is mostly useful for checking
+// the schema version or upgrading ahead of time.
+func runDBMigrate(ctx context.Context, status bool) error {
+	// Get the configuration
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	// Create the store
+	s, err := store.NewStore(cfg.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+	defer func() {
+		if err := s.Close(); err != nil {
+			slog.Error("Failed to close store", "error", err)
+		}
+	}()
+
+	if status {
+		statuses, err := s.MigrationStatus()
+		if err != nil {
+			return fmt.Errorf("failed to get migration status: %w", err)
+		}
+		printMigrationStatus(statuses)
+		return nil
+	}
+
+	applied, err := s.Migrate()
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	if len(applied) == 0 {
+		fmt.Println("Database schema is up to date.")
+		return nil
+	}
+	for _, migration := range applied {
+		fmt.Printf("Applied migration %04d %s\n", migration.Version, migration.Name)
+	}
+
+	return nil
+}
+
+// printMigrationStatus prints the migrations as a table
+func printMigrationStatus(statuses []store.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+
+	pending := 0
+	for _, status := range statuses {
+		applied := "pending"
+		if status.Applied() {
+			applied = status.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		} else {
+			pending++
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, applied)
+	}
+
+	if err := w.Flush(); err != nil {
+		slog.Error("Failed to write migration table", "error", err)
+	}
+
+	fmt.Printf("\n%d applied, %d pending\n", len(statuses)-pending, pending)
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/eval"
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// EvalCmd defines the eval command for Kong
+type EvalCmd struct {
+	Fixtures  string   `kong:"help='Directory of brief context fixtures saved with show-brief-context --json',default='eval/fixtures'"`
+	Out       string   `kong:"help='Directory to write the generated briefs and the summary to',default='eval/out'"`
+	Variant   []string `kong:"help='dailyBrief prompt variants to run, e.g. dailyBrief:concise (all variants if omitted)'"`
+	Provider  string   `kong:"help='LLM provider to use instead of the configured chain'"`
+	Model     string   `kong:"help='Model to use instead of the configured one'"`
+	MaxLength int      `kong:"help='Longest brief in characters that passes the length check, 0 disables the check',default=1500"`
+}
+
+// Run executes the eval command
+func (cmd *EvalCmd) Run() error {
+	return runEval(context.Background(), cmd)
+}
+
+// runEval runs the eval command, generating a brief from every fixture with every
+// dailyBrief prompt variant and writing the briefs and the results of the automatic
+// checks to the output directory. Nothing is sent to the outputs.
+func runEval(ctx context.Context, cmd *EvalCmd) error {
+	// Get the configuration
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	// Use the provider and model given on the command line, if any
+	if cmd.Provider != "" || cmd.Model != "" {
+		evalCfg := *cfg
+		evalCfg.LLMChain = []config.LLMChainEntry{{Provider: cmp.Or(cmd.Provider, cfg.LLMProvider), Model: cmd.Model}}
+		cfg = &evalCfg
+	}
+
+	// Load the prompts and pick the variants
+	prompts, err := config.LoadPrompts(cfg.PromptFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	available := llm.PromptVariants(prompts, "dailyBrief")
+	variants := available
+	if len(cmd.Variant) > 0 {
+		variants = cmd.Variant
+		for _, variant := range variants {
+			if !slices.Contains(available, variant) {
+				return fmt.Errorf("prompt variant %q not found, available variants: %s", variant, strings.Join(available, ", "))
+			}
+		}
+	}
+
+	fixtures, err := eval.LoadFixtures(cmd.Fixtures)
+	if err != nil {
+		return err
+	}
+
+	// Create the store for the call ledger and the budget
+	store, err := store.NewStore(cfg.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			slog.Error("Failed to close store", "error", err)
+		}
+	}()
+
+	// Initialize the store
+	if err := store.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize store: %w", err)
+	}
+
+	// No response cache, a cached response says nothing about the prompt
+	llmClient, err := newLLMProvider(cfg, store)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := llmClient.Close(); err != nil {
+			slog.Error("Failed to close LLM client", "error", err)
+		}
+	}()
+
+	slog.Info("Running prompt evaluation", "fixtures", len(fixtures), "variants", variants, "model", llmClient.Model())
+	results := eval.NewRunner(llmClient, prompts, cmd.MaxLength).Run(ctx, fixtures, variants)
+
+	if err := eval.WriteResults(cmd.Out, results); err != nil {
+		return err
+	}
+
+	fmt.Print(eval.FormatSummary(results))
+	fmt.Printf("\nBriefs written to %s\n", cmd.Out)
+
+	return nil
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
@@ -14,7 +15,9 @@ import (
 
 // GenerateBriefCmd defines the generate brief command for Kong
 type GenerateBriefCmd struct {
-	DaysAhead int `kong:"help='Number of days ahead to include in the brief (overrides config value)',default=0"`
+	DaysAhead int  `kong:"help='Number of days ahead to include in the brief (overrides config value)',default=0"`
+	NoLLM     bool `kong:"name='no-llm',help='Render a template-based brief without calling the LLM'"`
+	Fresh     bool `kong:"help='Always call the LLM instead of reusing a cached response for an identical prompt'"`
 }
 
 // Run executes the generate brief command
@@ -37,14 +40,16 @@ func (cmd *GenerateBriefCmd) Run() error {
 		daysAhead = 2
 	}
 
-	return runGenerateBrief(context.Background(), daysAhead)
+	return runGenerateBrief(context.Background(), daysAhead, cmd.NoLLM, cmd.Fresh)
 }
 
 // runGenerateBrief runs the generate brief command, generating a daily brief based on
 // memories stored in the database. It retrieves relevant memories for the current date
 // and the specified number of days ahead, then uses the LLM to generate a natural language
-// brief. The brief is then sent to all configured output channels (CLI, Discord, Telegram).
-func runGenerateBrief(ctx context.Context, daysAhead int) error {
+// brief. The brief is then sent to all configured output channels (CLI, Discord, Telegram, email).
+// If the LLM fails, or noLLM is set, a template-based brief is sent instead. Unless fresh
+// is set, a cached response is reused if the prompt is identical to a recent one.
+func runGenerateBrief(ctx context.Context, daysAhead int, noLLM, fresh bool) error {
 	// Get the configuration
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -67,32 +72,43 @@ func runGenerateBrief(ctx context.Context, daysAhead int) error {
 		return fmt.Errorf("failed to initialize store: %w", err)
 	}
 
-	// Load the prompts
-	prompts, err := config.LoadPrompts(cfg.PromptFilePath)
-	if err != nil {
-		return fmt.Errorf("failed to load prompts: %w", err)
+	var briefContent *output.Brief
+	if noLLM {
+		generator := brief.NewGenerator(store, nil, cfg)
+		briefContent, err = generator.GenerateTemplateBrief(ctx, daysAhead)
+		if err != nil {
+			return fmt.Errorf("failed to generate brief: %w", err)
+		}
+	} else {
+		briefContent, err = generateLLMBrief(ctx, cfg, store, daysAhead, fresh)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Create the LLM client
-	llmClient, err := llm.NewClient(cfg.GeminiAPIKey, cfg.GeminiModel, prompts)
-	if err != nil {
-		return fmt.Errorf("failed to create LLM client: %w", err)
-	}
-	defer func() {
-		if err := llmClient.Close(); err != nil {
-			slog.Error("Failed to close LLM client", "error", err)
-		}
-	}()
+	outputters := buildOutputters(cfg)
+	slog.Debug("Total outputters configured", "count", len(outputters))
 
-	// Create the brief generator
-	generator := brief.NewGenerator(store, llmClient, cfg)
+	// Send the brief to all configured outputters
+	var outputErrors []error
+	for _, outputter := range outputters {
+		if err := outputter.Send(ctx, briefContent); err != nil {
+			outputErrors = append(outputErrors, err)
+			slog.Error("Error sending brief", "error", err)
+		}
+	}
 
-	// Generate the brief
-	briefText, err := generator.GenerateDailyBrief(ctx, daysAhead)
-	if err != nil {
-		return fmt.Errorf("failed to generate brief: %w", err)
+	// If all outputs failed, return an error
+	if len(outputErrors) > 0 && len(outputErrors) == len(outputters) {
+		return fmt.Errorf("all outputs failed: %v", outputErrors[0])
 	}
 
+	return nil
+}
+
+// buildOutputters creates the outputters for all configured channels. Each channel can
+// leave out brief sections with its exclude_sections setting.
+func buildOutputters(cfg *config.Config) []output.Outputter {
 	// Create a list of outputters based on the configuration
 	var outputters []output.Outputter
 
@@ -100,9 +116,9 @@ func runGenerateBrief(ctx context.Context, daysAhead int) error {
 	slog.Debug("Configuration loaded", "output_format", cfg.OutputFormat)
 
 	// Add CLI outputter if enabled
-	if cfg.Outputs.EnableCLI || (cfg.OutputFormat == "cli" && len(cfg.Outputs.DiscordWebhookURLs) == 0 && len(cfg.Outputs.TelegramBots) == 0) {
+	if cfg.Outputs.EnableCLI || (cfg.OutputFormat == "cli" && !cfg.Outputs.HasChannels()) {
 		slog.Debug("Adding CLI outputter")
-		outputters = append(outputters, output.NewCLIOutputter())
+		outputters = append(outputters, output.WithExcludedSections(output.NewCLIOutputter(), cfg.Outputs.CLIExcludeSections))
 	}
 
 	// Add Discord outputters
@@ -112,36 +128,85 @@ func runGenerateBrief(ctx context.Context, daysAhead int) error {
 			outputters = append(outputters, output.NewDiscordOutputter(webhookURL))
 		}
 	}
+	for _, discordCfg := range cfg.Outputs.DiscordWebhooks {
+		if discordCfg.WebhookURL != "" {
+			slog.Debug("Adding Discord outputter", "webhook_url_length", len(discordCfg.WebhookURL))
+			outputters = append(outputters, output.WithExcludedSections(output.NewDiscordOutputter(discordCfg.WebhookURL), discordCfg.ExcludeSections))
+		}
+	}
 
 	// Add Telegram outputters
 	for _, telegramCfg := range cfg.Outputs.TelegramBots {
 		if telegramCfg.BotToken != "" && telegramCfg.ChatID != "" {
 			slog.Debug("Adding Telegram outputter", "chat_id", telegramCfg.ChatID)
-			outputters = append(outputters, output.NewTelegramOutputter(telegramCfg.BotToken, telegramCfg.ChatID))
+			outputters = append(outputters, output.WithExcludedSections(output.NewTelegramOutputter(telegramCfg.BotToken, telegramCfg.ChatID), telegramCfg.ExcludeSections))
 		}
 	}
 
+	// Add email outputters
+	for _, emailCfg := range cfg.Outputs.Emails {
+		slog.Debug("Adding email outputter", "host", emailCfg.SMTPHost, "recipients", len(emailCfg.To))
+		emailOutputter := output.NewEmailOutputter(emailCfg.SMTPHost, emailCfg.SMTPPort, emailCfg.Username, emailCfg.Password, emailCfg.From, emailCfg.To, emailCfg.Subject)
+		outputters = append(outputters, output.WithExcludedSections(emailOutputter, emailCfg.ExcludeSections))
+	}
+
 	// If no outputters were configured, default to CLI
 	if len(outputters) == 0 {
 		slog.Debug("No outputters configured, defaulting to CLI")
 		outputters = append(outputters, output.NewCLIOutputter())
 	}
 
-	slog.Debug("Total outputters configured", "count", len(outputters))
+	return outputters
+}
 
-	// Send the brief to all configured outputters
-	var outputErrors []error
-	for _, outputter := range outputters {
-		if err := outputter.Send(ctx, briefText); err != nil {
-			outputErrors = append(outputErrors, err)
-			slog.Error("Error sending brief", "error", err)
+// generateLLMBrief generates the brief with the configured LLM provider, falling back to
+// the template-based brief if the LLM can't produce one
+func generateLLMBrief(ctx context.Context, cfg *config.Config, store *store.Store, daysAhead int, fresh bool) (*output.Brief, error) {
+	llmClient, err := newLLMProvider(cfg, store)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := llmClient.Close(); err != nil {
+			slog.Error("Failed to close LLM client", "error", err)
 		}
+	}()
+
+	// Create the brief generator
+	generator := brief.NewGenerator(store, llmClient, cfg)
+	enableResponseCache(cfg, store, llmClient, generator, fresh, "dailyBrief")
+	closeEmbedder, err := enableMemoryRetrieval(cfg, store, generator)
+	if err != nil {
+		return nil, err
 	}
+	defer closeEmbedder()
 
-	// If all outputs failed, return an error
-	if len(outputErrors) > 0 && len(outputErrors) == len(outputters) {
-		return fmt.Errorf("all outputs failed: %v", outputErrors[0])
+	if err := enableTools(cfg, generator); err != nil {
+		return nil, err
 	}
 
-	return nil
+	if err := enableVerification(cfg, generator); err != nil {
+		return nil, err
+	}
+
+	// Generate the brief
+	briefContent, err := generator.GenerateDailyBrief(ctx, daysAhead)
+	if err == nil {
+		return briefContent, nil
+	}
+
+	// Sending a plain brief is better than sending nothing
+	slog.Error("LLM brief generation failed, falling back to template brief", "error", err)
+	briefContent, fallbackErr := generator.GenerateTemplateBrief(ctx, daysAhead)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("failed to generate brief: %w", errors.Join(err, fallbackErr))
+	}
+
+	// Let the readers know why the brief looks different
+	if budgetErr, ok := errors.AsType[*llm.BudgetExceededError](err); ok {
+		briefContent.AddWarning(fmt.Sprintf("The monthly LLM budget has been used up (%.2f of %.2f EUR), so this brief was generated without the LLM.",
+			budgetErr.Spent, budgetErr.Budget))
+	}
+
+	return briefContent, nil
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/lepinkainen/hovimestari/internal/config"
 	electricityimporter "github.com/lepinkainen/hovimestari/internal/importer/electricityprice"
+	"github.com/lepinkainen/hovimestari/internal/retention"
 	"github.com/lepinkainen/hovimestari/internal/store"
 )
 
@@ -58,5 +59,8 @@ func runImportElectricityPrice(ctx context.Context) error {
 	}
 
 	slog.Info("Electricity prices imported successfully")
+
+	// Apply the retention rules now that the import has added memories
+	retention.AutoPrune(cfg, s)
 	return nil
 }
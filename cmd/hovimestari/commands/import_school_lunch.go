@@ -7,6 +7,7 @@ import (
 
 	"github.com/lepinkainen/hovimestari/internal/config"
 	schoollunchimporter "github.com/lepinkainen/hovimestari/internal/importer/schoollunch"
+	"github.com/lepinkainen/hovimestari/internal/retention"
 	"github.com/lepinkainen/hovimestari/internal/store"
 )
 
@@ -61,5 +62,8 @@ func runImportSchoolLunch(ctx context.Context) error {
 	}
 
 	slog.Info("School lunch menus imported successfully")
+
+	// Apply the retention rules now that the import has added memories
+	retention.AutoPrune(cfg, store)
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/retention"
 	"github.com/lepinkainen/hovimestari/internal/store"
 )
 
@@ -51,5 +52,8 @@ func runImportWaterQuality(ctx context.Context, location, quality string) error
 	}
 
 	slog.Info("Water quality memory added successfully", "location", location)
+
+	// Apply the retention rules now that the import has added memories
+	retention.AutoPrune(cfg, s)
 	return nil
 }
\ No newline at end of file
@@ -7,6 +7,7 @@ import (
 
 	"github.com/lepinkainen/hovimestari/internal/config"
 	weatherimporter "github.com/lepinkainen/hovimestari/internal/importer/weather"
+	"github.com/lepinkainen/hovimestari/internal/retention"
 	"github.com/lepinkainen/hovimestari/internal/store"
 )
 
@@ -55,5 +56,8 @@ func runImportWeather(ctx context.Context) error {
 	}
 
 	slog.Info("Weather forecasts imported successfully")
+
+	// Apply the retention rules now that the import has added memories
+	retention.AutoPrune(cfg, store)
 	return nil
 }
@@ -107,65 +107,12 @@ func runInitConfig(configPath, geminiAPIKey, outputFormat string) error {
 		return fmt.Errorf("failed to encode config: %w", err)
 	}
 
-	// Create a default prompts.json file if it doesn't exist
-	promptsPath := filepath.Join(configDir, "prompts.json")
-	if _, err := os.Stat(promptsPath); os.IsNotExist(err) {
-		// Copy the existing prompts.json file if it exists in the current directory
-		if promptsData, err := os.ReadFile("prompts.json"); err == nil {
-			if err := os.WriteFile(promptsPath, promptsData, 0644); err != nil {
-				return fmt.Errorf("failed to create prompts.json: %w", err)
-			}
-			slog.Info("Created prompts.json", "path", promptsPath)
-		} else {
-			// If the file doesn't exist, create a basic one
-			basicPrompts := map[string][]string{
-				"dailyBrief": {
-					"You are Hovimestari, a helpful butler assistant. Your task is to generate a daily brief in %LANG% for your user based on the following information:",
-					"",
-					"Context Information:",
-					"%CONTEXT%",
-					"",
-					"Relevant Information:",
-					"%NOTES%",
-					"",
-					"Please generate a concise, well-organized daily brief in %LANG%.",
-				},
-				"userQuery": {
-					"You are Hovimestari, a helpful butler assistant. Your task is to respond to the user's query in %LANG% based on the following information:",
-					"",
-					"User Query: %QUERY%",
-					"",
-					"Relevant Information:",
-					"%NOTES%",
-					"",
-					"Please respond in %LANG% using a formal, butler-like tone.",
-				},
-			}
-
-			promptsFile, err := os.Create(promptsPath)
-			if err != nil {
-				return fmt.Errorf("failed to create prompts.json: %w", err)
-			}
-			defer func() {
-				if err := promptsFile.Close(); err != nil {
-					slog.Error("Failed to close prompts file", "error", err)
-				}
-			}()
-
-			encoder := json.NewEncoder(promptsFile)
-			encoder.SetIndent("", "  ")
-			if err := encoder.Encode(basicPrompts); err != nil {
-				return fmt.Errorf("failed to encode prompts: %w", err)
-			}
-			slog.Info("Created default prompts.json", "path", promptsPath)
-		}
-	}
-
 	slog.Info("Settings saved to file", "path", targetConfigPath)
 	slog.Info("NOTE: Edit the file manually to add the correct calendars, family members, and location information")
 	slog.Info("The application will look for configuration files in the following locations:")
 	slog.Info("1. The path specified with --config flag")
 	slog.Info("2. $XDG_CONFIG_HOME/hovimestari/ (usually ~/.config/hovimestari/)")
 	slog.Info("3. The directory containing the executable")
+	slog.Info("Default prompts are built in; to override them, put the changed keys in prompts.json in the config directory", "path", filepath.Join(configDir, "prompts.json"))
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/lepinkainen/hovimestari/internal/config"
 	"github.com/lepinkainen/hovimestari/internal/llm"
@@ -17,9 +18,9 @@ func (cmd *ListModelsCmd) Run() error {
 	return runListModels(context.Background())
 }
 
-// runListModels runs the list models command, querying the Gemini API for available
-// models and displaying them to the user. It also shows the currently configured model
-// from the configuration file.
+// runListModels runs the list models command, querying the configured LLM provider for
+// available models and displaying them to the user. It also shows the currently configured
+// model from the configuration file.
 func runListModels(ctx context.Context) error {
 	// Get the configuration
 	cfg, err := config.GetConfig()
@@ -27,9 +28,20 @@ func runListModels(ctx context.Context) error {
 		return fmt.Errorf("failed to get configuration: %w", err)
 	}
 
+	// Create the LLM provider
+	provider, err := llm.NewProvider(cfg, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create LLM client: %w", err)
+	}
+	defer func() {
+		if err := provider.Close(); err != nil {
+			slog.Error("Failed to close LLM client", "error", err)
+		}
+	}()
+
 	// List the models
-	slog.Info("Listing available Gemini models")
-	models, err := llm.ListModels(ctx, cfg.GeminiAPIKey)
+	slog.Info("Listing available models", "provider", provider.Name())
+	models, err := provider.ListModels(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to list models: %w", err)
 	}
@@ -41,8 +53,9 @@ func runListModels(ctx context.Context) error {
 	}
 
 	// Print the current model
-	fmt.Printf("\nCurrent model configured: %s\n", cfg.GeminiModel)
-	slog.Info("To change the model, edit the config.json file or set the HOVIMESTARI_GEMINI_MODEL environment variable")
+	fmt.Printf("\nCurrent model configured: %s (%s)\n", provider.Model(), provider.Name())
+	slog.Info("To change the model, edit the config.json file or set the environment variable",
+		"env", "HOVIMESTARI_"+strings.ToUpper(provider.Name())+"_MODEL")
 
 	return nil
 }
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/brief"
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// newLLMProvider creates the configured LLM provider with the monthly budget and the call
+// ledger set up. The caller must close the provider.
+func newLLMProvider(cfg *config.Config, store *store.Store) (llm.Provider, error) {
+	// Load the prompts
+	prompts, err := config.LoadPrompts(cfg.PromptFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	// Create the LLM provider
+	provider, err := llm.NewProvider(cfg, prompts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	// Enforce the monthly LLM budget, if configured
+	budgeted, err := llm.WithBudget(cfg, prompts, provider, store)
+	if err != nil {
+		_ = provider.Close()
+		return nil, err
+	}
+
+	// Record every LLM call in the call ledger
+	budgeted.SetRecorder(llm.NewLedger(store, cfg.LLMPrices))
+
+	return budgeted, nil
+}
+
+// enableResponseCache reuses the responses to identical prompts with the given prompt
+// keys, e.g. when re-sending a brief after an output failure, if llm_cache_ttl_minutes is
+// set. Unless fresh is set, a cached response is reused. The generator only commits the
+// responses it accepts to the cache.
+func enableResponseCache(cfg *config.Config, store *store.Store, provider llm.Provider, generator *brief.Generator, fresh bool, promptKeys ...string) {
+	if cfg.LLMCacheTTLMinutes <= 0 {
+		return
+	}
+
+	cache := llm.NewCache(store, time.Duration(cfg.LLMCacheTTLMinutes)*time.Minute, fresh, promptKeys...)
+	provider.SetCache(cache)
+	generator.SetCache(cache)
+}
+
+// enableMemoryRetrieval sets up semantic retrieval of undated memories on the generator
+// if an embedding provider is configured. The returned function releases the embedder.
+func enableMemoryRetrieval(cfg *config.Config, store *store.Store, generator *brief.Generator) (func(), error) {
+	embedder, err := llm.NewEmbedder(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedder: %w", err)
+	}
+	if embedder == nil {
+		return func() {}, nil
+	}
+
+	generator.SetRetriever(brief.NewRetriever(store, embedder, cfg.MemoryTopK))
+
+	return func() {
+		if err := embedder.Close(); err != nil {
+			slog.Error("Failed to close embedder", "error", err)
+		}
+	}, nil
+}
+
+// enableTools lets the model call tools while generating with the generator, if
+// llm_tools is enabled
+func enableTools(cfg *config.Config, generator *brief.Generator) error {
+	if !cfg.LLMTools {
+		return nil
+	}
+
+	prompts, err := config.LoadPrompts(cfg.PromptFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	generator.SetToolbox(llm.NewToolbox(prompts, generator.Tools(), cfg.LLMMaxToolRounds))
+	return nil
+}
+
+// enableVerification checks generated briefs against their context, unless
+// brief_verification is off
+func enableVerification(cfg *config.Config, generator *brief.Generator) error {
+	if cfg.BriefVerification == config.BriefVerificationOff {
+		return nil
+	}
+
+	prompts, err := config.LoadPrompts(cfg.PromptFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	generator.SetVerifier(brief.NewVerifier(prompts, cfg.BriefVerification == config.BriefVerificationRegenerate))
+	return nil
+}
@@ -0,0 +1,397 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// memoryContentWidth is the longest content shown in the memory list table, in characters
+const memoryContentWidth = 80
+
+// MemoryCmd groups the memory management subcommands for Kong
+type MemoryCmd struct {
+	List   MemoryListCmd   `kong:"cmd,help='List memories, newest first'"`
+	Show   MemoryShowCmd   `kong:"cmd,help='Show a single memory'"`
+	Edit   MemoryEditCmd   `kong:"cmd,help='Change the content or relevance date of a memory'"`
+	Delete MemoryDeleteCmd `kong:"cmd,help='Delete a memory, or the memories of a source'"`
+}
+
+// MemoryListCmd defines the memory list command for Kong
+type MemoryListCmd struct {
+	Source string `kong:"help='Only memories from the source, e.g. manual or calendar (covers calendar:Family)'"`
+	From   string `kong:"help='Only memories relevant on or after the date (YYYY-MM-DD)'"`
+	To     string `kong:"help='Only memories relevant on or before the date (YYYY-MM-DD)'"`
+	Text   string `kong:"help='Only memories containing the text, ignoring case'"`
+	Limit  int    `kong:"help='Maximum number of memories to list, 0 for all',default=50"`
+	JSON   bool   `kong:"name='json',help='Print the memories as JSON'"`
+}
+
+// Run executes the memory list command
+func (cmd *MemoryListCmd) Run() error {
+	return runMemoryList(context.Background(), cmd)
+}
+
+// runMemoryList runs the memory list command, printing the memories matching the filters
+// as a table or as JSON
+func runMemoryList(ctx context.Context, cmd *MemoryListCmd) error {
+	filter := store.MemoryFilter{Source: cmd.Source, Text: cmd.Text, Limit: cmd.Limit}
+
+	if cmd.From != "" {
+		from, err := time.Parse("2006-01-02", cmd.From)
+		if err != nil {
+			return fmt.Errorf("failed to parse from date: %w", err)
+		}
+		filter.From = &from
+	}
+	if cmd.To != "" {
+		to, err := time.Parse("2006-01-02", cmd.To)
+		if err != nil {
+			return fmt.Errorf("failed to parse to date: %w", err)
+		}
+		filter.To = &to
+	}
+
+	_, s, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer closeStore(s)
+
+	memories, err := s.ListMemories(filter)
+	if err != nil {
+		return fmt.Errorf("failed to list memories: %w", err)
+	}
+
+	if cmd.JSON {
+		return printJSON(toMemoryJSON(memories))
+	}
+
+	printMemories(memories)
+	return nil
+}
+
+// MemoryShowCmd defines the memory show command for Kong
+type MemoryShowCmd struct {
+	ID   int64 `kong:"arg,help='ID of the memory'"`
+	JSON bool  `kong:"name='json',help='Print the memory as JSON'"`
+}
+
+// Run executes the memory show command
+func (cmd *MemoryShowCmd) Run() error {
+	return runMemoryShow(context.Background(), cmd.ID, cmd.JSON)
+}
+
+// runMemoryShow runs the memory show command, printing every field of a single memory
+func runMemoryShow(ctx context.Context, id int64, asJSON bool) error {
+	_, s, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer closeStore(s)
+
+	memory, err := s.GetMemory(id)
+	if err != nil {
+		return fmt.Errorf("failed to get memory %d: %w", id, err)
+	}
+
+	if asJSON {
+		return printJSON(toMemoryJSON([]store.Memory{memory})[0])
+	}
+
+	printMemory(memory)
+	return nil
+}
+
+// MemoryEditCmd defines the memory edit command for Kong
+type MemoryEditCmd struct {
+	ID                 int64  `kong:"arg,help='ID of the memory'"`
+	Content            string `kong:"help='New content'"`
+	RelevanceDate      string `kong:"help='New relevance date (YYYY-MM-DD), replacing any range'"`
+	RelevanceStart     string `kong:"help='New first day of the relevance range (YYYY-MM-DD)'"`
+	RelevanceEnd       string `kong:"help='New last day of the relevance range (YYYY-MM-DD)'"`
+	ClearRelevanceDate bool   `kong:"help='Remove the relevance date, making the memory relevant for all dates'"`
+}
+
+// Run executes the memory edit command
+func (cmd *MemoryEditCmd) Run() error {
+	return runMemoryEdit(context.Background(), cmd)
+}
+
+// runMemoryEdit runs the memory edit command, changing the content and relevance range of
+// a memory. Fields without a flag are kept as they are, so --relevance-start and
+// --relevance-end move only one end of the range.
+func runMemoryEdit(ctx context.Context, cmd *MemoryEditCmd) error {
+	relevanceChanged := cmd.RelevanceDate != "" || cmd.RelevanceStart != "" || cmd.RelevanceEnd != ""
+	if cmd.Content == "" && !relevanceChanged && !cmd.ClearRelevanceDate {
+		return errors.New("nothing to change, use --content, --relevance-date, --relevance-start, --relevance-end or --clear-relevance-date")
+	}
+	if relevanceChanged && cmd.ClearRelevanceDate {
+		return errors.New("--clear-relevance-date can't be used with a new relevance date")
+	}
+
+	_, s, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer closeStore(s)
+
+	memory, err := s.GetMemory(cmd.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get memory %d: %w", cmd.ID, err)
+	}
+
+	if cmd.Content != "" {
+		memory.Content = cmd.Content
+	}
+	if relevanceChanged {
+		startStr, endStr := cmd.RelevanceStart, cmd.RelevanceEnd
+		if cmd.RelevanceDate == "" && memory.RelevanceStart != nil {
+			if startStr == "" {
+				startStr = memory.RelevanceStart.Format("2006-01-02")
+			}
+			if endStr == "" {
+				endStr = memory.RelevanceEnd.Format("2006-01-02")
+			}
+		}
+
+		memory.RelevanceStart, memory.RelevanceEnd, err = parseRelevance(cmd.RelevanceDate, startStr, endStr)
+		if err != nil {
+			return err
+		}
+	}
+	if cmd.ClearRelevanceDate {
+		memory.RelevanceStart, memory.RelevanceEnd = nil, nil
+	}
+
+	if err := s.UpdateMemory(memory.ID, memory.Content, memory.RelevanceStart, memory.RelevanceEnd); err != nil {
+		return fmt.Errorf("failed to update memory %d: %w", memory.ID, err)
+	}
+
+	slog.Info("Memory updated successfully", "id", memory.ID)
+	printMemory(memory)
+	return nil
+}
+
+// MemoryDeleteCmd defines the memory delete command for Kong
+type MemoryDeleteCmd struct {
+	ID     int64  `kong:"arg,optional,help='ID of the memory to delete'"`
+	Source string `kong:"help='Delete the memories from the source instead (covers calendar:Family for calendar)'"`
+	Before string `kong:"help='With --source, only delete memories whose relevance ended before the date (YYYY-MM-DD)'"`
+	Yes    bool   `kong:"short='y',help='Delete without asking for confirmation'"`
+}
+
+// Run executes the memory delete command
+func (cmd *MemoryDeleteCmd) Run() error {
+	return runMemoryDelete(context.Background(), cmd)
+}
+
+// runMemoryDelete runs the memory delete command, deleting a single memory by ID or every
+// memory from a source, optionally only those no longer relevant on a date. Deleting by source
+// asks for confirmation with the number of memories first.
+func runMemoryDelete(ctx context.Context, cmd *MemoryDeleteCmd) error {
+	switch {
+	case cmd.ID != 0 && cmd.Source != "":
+		return errors.New("give either a memory ID or --source, not both")
+	case cmd.ID == 0 && cmd.Source == "":
+		return errors.New("give a memory ID or --source")
+	case cmd.Before != "" && cmd.Source == "":
+		return errors.New("--before can only be used with --source")
+	}
+
+	_, s, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer closeStore(s)
+
+	if cmd.ID != 0 {
+		memory, err := s.GetMemory(cmd.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get memory %d: %w", cmd.ID, err)
+		}
+
+		printMemory(memory)
+		if !cmd.Yes && !confirm("Delete this memory?") {
+			fmt.Println("Memory not deleted")
+			return nil
+		}
+
+		if err := s.DeleteMemory(cmd.ID); err != nil {
+			return fmt.Errorf("failed to delete memory %d: %w", cmd.ID, err)
+		}
+		slog.Info("Memory deleted successfully", "id", cmd.ID)
+		return nil
+	}
+
+	filter := store.MemoryFilter{Source: cmd.Source}
+	if cmd.Before != "" {
+		before, err := time.Parse("2006-01-02", cmd.Before)
+		if err != nil {
+			return fmt.Errorf("failed to parse before date: %w", err)
+		}
+		filter.EndsBefore = &before
+	}
+
+	memories, err := s.ListMemories(filter)
+	if err != nil {
+		return fmt.Errorf("failed to list memories: %w", err)
+	}
+	if len(memories) == 0 {
+		fmt.Println("No matching memories.")
+		return nil
+	}
+
+	if !cmd.Yes && !confirm(fmt.Sprintf("Delete %d memories?", len(memories))) {
+		fmt.Println("Memories not deleted")
+		return nil
+	}
+
+	deleted, err := s.DeleteMemories(filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete memories: %w", err)
+	}
+
+	slog.Info("Memories deleted successfully", "source", cmd.Source, "count", deleted)
+	return nil
+}
+
+// openStore gets the configuration and opens and initializes the configured database.
+// Close the store with closeStore.
+func openStore() (*config.Config, *store.Store, error) {
+	// Get the configuration
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	// Create the store
+	s, err := store.NewStore(cfg.DBPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create store: %w", err)
+	}
+
+	// Initialize the store
+	if err := s.Initialize(); err != nil {
+		closeStore(s)
+		return nil, nil, fmt.Errorf("failed to initialize store: %w", err)
+	}
+
+	return cfg, s, nil
+}
+
+// closeStore closes the store, logging any error
+func closeStore(s *store.Store) {
+	if err := s.Close(); err != nil {
+		slog.Error("Failed to close store", "error", err)
+	}
+}
+
+// memoryJSON is the JSON representation of a memory
+type memoryJSON struct {
+	ID             int64     `json:"id"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
+	RelevanceStart *string   `json:"relevance_start"`
+	RelevanceEnd   *string   `json:"relevance_end"`
+	Source         string    `json:"source"`
+	UID            *string   `json:"uid,omitempty"`
+}
+
+// toMemoryJSON converts the memories to their JSON representation, with the relevance
+// range as YYYY-MM-DD dates
+func toMemoryJSON(memories []store.Memory) []memoryJSON {
+	result := make([]memoryJSON, len(memories))
+	for i, memory := range memories {
+		result[i] = memoryJSON{
+			ID:        memory.ID,
+			Content:   memory.Content,
+			CreatedAt: memory.CreatedAt,
+			Source:    memory.Source,
+			UID:       memory.UID,
+		}
+		if memory.RelevanceStart != nil {
+			start := memory.RelevanceStart.Format("2006-01-02")
+			end := memory.RelevanceEnd.Format("2006-01-02")
+			result[i].RelevanceStart, result[i].RelevanceEnd = &start, &end
+		}
+	}
+	return result
+}
+
+// printJSON prints the value as indented JSON
+func printJSON(value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// printMemories prints the memories as a table, with long content shortened to one line
+func printMemories(memories []store.Memory) {
+	if len(memories) == 0 {
+		fmt.Println("No memories found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDATE\tSOURCE\tCONTENT")
+	for _, memory := range memories {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", memory.ID, formatRelevance(memory), memory.Source, shortenContent(memory.Content, memoryContentWidth))
+	}
+
+	if err := w.Flush(); err != nil {
+		slog.Error("Failed to write memory table", "error", err)
+	}
+}
+
+// printMemory prints every field of the memory
+func printMemory(memory store.Memory) {
+	uid := ""
+	if memory.UID != nil {
+		uid = *memory.UID
+	}
+
+	fmt.Printf("ID:      %d\n", memory.ID)
+	fmt.Printf("Date:    %s\n", formatRelevance(memory))
+	fmt.Printf("Source:  %s\n", memory.Source)
+	fmt.Printf("UID:     %s\n", valueOrNone(uid))
+	fmt.Printf("Created: %s\n", memory.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("Content: %s\n", memory.Content)
+}
+
+// formatRelevance formats the relevance date as YYYY-MM-DD, a range as
+// YYYY-MM-DD..YYYY-MM-DD, or "-" if the memory is relevant on all dates
+func formatRelevance(memory store.Memory) string {
+	if memory.RelevanceStart == nil {
+		return "-"
+	}
+
+	start := memory.RelevanceStart.Format("2006-01-02")
+	end := memory.RelevanceEnd.Format("2006-01-02")
+	if start == end {
+		return start
+	}
+	return start + ".." + end
+}
+
+// shortenContent puts the content on a single line and cuts it to width characters
+func shortenContent(content string, width int) string {
+	content = strings.Join(strings.Fields(content), " ")
+	if utf8.RuneCountInString(content) <= width {
+		return content
+	}
+	return string([]rune(content)[:width-1]) + "…"
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/llm"
+)
+
+// PromptsCmd groups the prompt management subcommands for Kong
+type PromptsCmd struct {
+	Show     PromptsShowCmd     `kong:"cmd,help='Print the effective prompts and where each of them came from'"`
+	Validate PromptsValidateCmd `kong:"cmd,help='Render every prompt template against sample data and report errors'"`
+}
+
+// PromptsShowCmd defines the prompts show command for Kong
+type PromptsShowCmd struct {
+	Key string `kong:"arg,optional,help='Prompt key to show (all prompts if omitted)'"`
+}
+
+// Run executes the prompts show command
+func (cmd *PromptsShowCmd) Run() error {
+	return runPromptsShow(context.Background(), cmd.Key)
+}
+
+// runPromptsShow runs the prompts show command, printing the effective prompts after the
+// embedded defaults and overrides have been merged, along with the source of each prompt.
+func runPromptsShow(ctx context.Context, key string) error {
+	// Get the configuration
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	// Resolve the prompts and their sources
+	set, err := config.ResolvePrompts(cfg.PromptFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	keys := slices.Sorted(maps.Keys(set.Prompts))
+	if key != "" {
+		if _, ok := set.Prompts[key]; !ok {
+			return fmt.Errorf("prompt %q not found, available prompts: %s", key, strings.Join(keys, ", "))
+		}
+		keys = []string{key}
+	}
+
+	for _, k := range keys {
+		source := set.Sources[k]
+		if set.Overridden[k] {
+			source += ", overrides the built-in default"
+		}
+		fmt.Printf("=== %s (source: %s) ===\n", k, source)
+		fmt.Println(strings.Join(set.Prompts[k], "\n"))
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// PromptsValidateCmd defines the prompts validate command for Kong
+type PromptsValidateCmd struct{}
+
+// Run executes the prompts validate command
+func (cmd *PromptsValidateCmd) Run() error {
+	return runPromptsValidate(context.Background())
+}
+
+// runPromptsValidate runs the prompts validate command, loading the configured prompts and
+// rendering each of them against sample data. Every prompt is reported as OK or with the
+// template error, and an error is returned if any prompt failed.
+func runPromptsValidate(ctx context.Context) error {
+	// Get the configuration
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	// Load the prompts
+	prompts, err := config.LoadPrompts(cfg.PromptFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	failed := 0
+	for _, result := range llm.ValidatePrompts(prompts) {
+		if result.Err != nil {
+			failed++
+			fmt.Printf("FAIL %s: %v\n", result.Key, result.Err)
+			continue
+		}
+		fmt.Printf("OK   %s\n", result.Key)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d prompt(s) failed validation", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/retention"
+)
+
+// PruneCmd defines the prune command for Kong
+type PruneCmd struct {
+	DryRun bool `kong:"help='Show how many memories would be deleted without deleting them'"`
+}
+
+// Run executes the prune command
+func (cmd *PruneCmd) Run() error {
+	return runPrune(context.Background(), cmd.DryRun)
+}
+
+// runPrune runs the prune command, deleting the memories expired or superseded according
+// to the retention rules and printing the number deleted from each source. Imports do the
+// same automatically unless auto_prune is disabled.
+func runPrune(ctx context.Context, dryRun bool) error {
+	cfg, s, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer closeStore(s)
+
+	if len(cfg.Retention) == 0 {
+		fmt.Println("No retention rules configured, nothing to prune.")
+		return nil
+	}
+
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return fmt.Errorf("failed to load timezone: %w", err)
+	}
+
+	results, err := retention.NewPruner(s, cfg.Retention).Prune(time.Now().In(loc), dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to prune memories: %w", err)
+	}
+
+	printPruneResults(results, dryRun)
+	return nil
+}
+
+// printPruneResults prints the memories pruned from each source as a table
+func printPruneResults(results []retention.Result, dryRun bool) {
+	if len(results) == 0 {
+		fmt.Println("Nothing to prune.")
+		return
+	}
+
+	verb := "DELETED"
+	if dryRun {
+		verb = "WOULD DELETE"
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "SOURCE\tRULE\t%s\n", verb)
+
+	var total int64
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", result.Source, describeRetentionRule(result.Rule), result.Pruned)
+		total += result.Pruned
+	}
+	fmt.Fprintf(w, "TOTAL\t\t%d\n", total)
+
+	if err := w.Flush(); err != nil {
+		slog.Error("Failed to write prune table", "error", err)
+	}
+}
+
+// describeRetentionRule describes what a retention rule keeps
+func describeRetentionRule(rule config.RetentionRule) string {
+	var parts []string
+	if rule.KeepLatestPerDate {
+		parts = append(parts, "latest per date")
+	}
+	if rule.MaxAgeDays > 0 {
+		parts = append(parts, fmt.Sprintf("%d days", rule.MaxAgeDays))
+	}
+	return rule.Source + ": " + strings.Join(parts, ", ")
+}
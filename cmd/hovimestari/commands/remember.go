@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// RememberCmd defines the remember command for Kong
+type RememberCmd struct {
+	Text   string `kong:"arg,help='What to remember in natural language'"`
+	Source string `kong:"help='Memory source',default='manual'"`
+	Yes    bool   `kong:"short='y',help='Store the memory without asking for confirmation'"`
+}
+
+// Run executes the remember command
+func (cmd *RememberCmd) Run() error {
+	return runRemember(context.Background(), cmd.Text, cmd.Source, cmd.Yes)
+}
+
+// runRemember runs the remember command. The LLM extracts the content, relevance date,
+// time and family member from the text, resolving relative dates against today in the
+// configured timezone. The result is shown for confirmation before it's stored.
+func runRemember(ctx context.Context, text, source string, yes bool) error {
+	// Get the configuration
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return fmt.Errorf("failed to load timezone: %w", err)
+	}
+
+	// Create the store
+	store, err := store.NewStore(cfg.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			slog.Error("Failed to close store", "error", err)
+		}
+	}()
+
+	// Initialize the store
+	if err := store.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize store: %w", err)
+	}
+
+	llmClient, err := newLLMProvider(cfg, store)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := llmClient.Close(); err != nil {
+			slog.Error("Failed to close LLM client", "error", err)
+		}
+	}()
+
+	prompts, err := config.LoadPrompts(cfg.PromptFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	family := make([]string, 0, len(cfg.Family))
+	for _, member := range cfg.Family {
+		family = append(family, member.Name)
+	}
+
+	promptContent, err := llm.BuildMemoryPrompt(prompts, text, time.Now().In(loc), family)
+	if err != nil {
+		return err
+	}
+
+	response, err := llmClient.Generate(ctx, "parseMemory", cfg.OutputLanguage, promptContent)
+	if err != nil {
+		return fmt.Errorf("failed to extract memory: %w", err)
+	}
+
+	memory, err := llm.ParseExtractedMemory(response, family)
+	if err != nil {
+		return err
+	}
+
+	id, err := storeExtractedMemory(os.Stdout, store, memory, source, func() bool {
+		return yes || confirm("Store this memory?")
+	})
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		fmt.Println("Memory not stored")
+		return nil
+	}
+
+	slog.Info("Memory added successfully", "id", id)
+	return nil
+}
+
+// storeExtractedMemory prints the memory with its content exactly as it will be stored
+// and adds it to the store if confirmed returns true. It returns the ID of the new
+// memory, or 0 if it wasn't confirmed.
+func storeExtractedMemory(w io.Writer, s *store.Store, memory *llm.ExtractedMemory, source string, confirmed func() bool) (int64, error) {
+	content := memory.MemoryContent()
+
+	fmt.Fprintf(w, "Content: %s\n", content)
+	fmt.Fprintf(w, "Date:    %s\n", valueOrNone(memory.Date))
+	fmt.Fprintf(w, "Time:    %s\n", valueOrNone(memory.Time))
+	fmt.Fprintf(w, "Person:  %s\n", valueOrNone(memory.Person))
+
+	if !confirmed() {
+		return 0, nil
+	}
+
+	id, err := s.AddMemory(content, memory.RelevanceDate(), source, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add memory: %w", err)
+	}
+
+	return id, nil
+}
+
+// valueOrNone returns the value, or "-" if it's empty
+func valueOrNone(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+func TestStoreExtractedMemory(t *testing.T) {
+	tests := []struct {
+		name      string
+		memory    llm.ExtractedMemory
+		confirmed bool
+	}{
+		{
+			name:      "time and person added to the content",
+			memory:    llm.ExtractedMemory{Content: "Parent-teacher meeting", Date: "2025-04-22", Time: "18:00", Person: "Maija"},
+			confirmed: true,
+		},
+		{
+			name:      "content only",
+			memory:    llm.ExtractedMemory{Content: "The car is serviced at Autohuolto"},
+			confirmed: true,
+		},
+		{
+			name:   "not confirmed",
+			memory: llm.ExtractedMemory{Content: "Dentist", Date: "2025-04-23"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := store.NewStore(filepath.Join(t.TempDir(), "test.db"))
+			if err != nil {
+				t.Fatalf("NewStore() error = %v", err)
+			}
+			defer func() {
+				_ = s.Close()
+			}()
+			if err := s.Initialize(); err != nil {
+				t.Fatalf("Initialize() error = %v", err)
+			}
+
+			var out bytes.Buffer
+			id, err := storeExtractedMemory(&out, s, &tt.memory, "manual", func() bool { return tt.confirmed })
+			if err != nil {
+				t.Fatalf("storeExtractedMemory() error = %v", err)
+			}
+
+			confirmedContent, _, _ := strings.Cut(strings.TrimPrefix(out.String(), "Content: "), "\n")
+
+			if !tt.confirmed {
+				if id != 0 {
+					t.Errorf("storeExtractedMemory() stored memory %d without confirmation", id)
+				}
+				return
+			}
+
+			stored, err := s.GetMemory(id)
+			if err != nil {
+				t.Fatalf("GetMemory() error = %v", err)
+			}
+			if stored.Content != confirmedContent {
+				t.Errorf("stored content %q, but the confirmation showed %q", stored.Content, confirmedContent)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// ansiBold and ansiReset highlight the matching words on a terminal
+const (
+	ansiBold  = "\x1b[1m"
+	ansiReset = "\x1b[0m"
+)
+
+// SearchCmd defines the search command for Kong
+type SearchCmd struct {
+	Query  string `kong:"arg,help='Words to search for, each matched as a prefix'"`
+	Kind   string `kong:"help='Only search memory or event results'"`
+	Source string `kong:"help='Only results from the source, e.g. manual or calendar (covers calendar:Family)'"`
+	From   string `kong:"help='Only results on or after the date (YYYY-MM-DD)'"`
+	To     string `kong:"help='Only results on or before the date (YYYY-MM-DD)'"`
+	Limit  int    `kong:"help='Maximum number of results',default=20"`
+	JSON   bool   `kong:"name='json',help='Print the results as JSON'"`
+}
+
+// Run executes the search command
+func (cmd *SearchCmd) Run() error {
+	return runSearch(context.Background(), cmd)
+}
+
+// runSearch runs the search command, printing the memories and calendar events matching
+// the query, best matches first, with the matching words highlighted
+func runSearch(ctx context.Context, cmd *SearchCmd) error {
+	cfg, s, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer closeStore(s)
+
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return fmt.Errorf("failed to load timezone: %w", err)
+	}
+
+	// The dates are days in the configured timezone, so an event just after local
+	// midnight counts for the day it's on locally
+	filter := store.SearchFilter{Kind: cmd.Kind, Source: cmd.Source, Limit: cmd.Limit}
+	if cmd.From != "" {
+		from, err := time.ParseInLocation("2006-01-02", cmd.From, loc)
+		if err != nil {
+			return fmt.Errorf("failed to parse from date: %w", err)
+		}
+		filter.From = &from
+	}
+	if cmd.To != "" {
+		to, err := time.ParseInLocation("2006-01-02", cmd.To, loc)
+		if err != nil {
+			return fmt.Errorf("failed to parse to date: %w", err)
+		}
+		filter.To = &to
+	}
+
+	results, err := s.Search(cmd.Query, filter)
+	if err != nil {
+		return fmt.Errorf("failed to search: %w", err)
+	}
+
+	if cmd.JSON {
+		return printJSON(toSearchResultJSON(results, loc))
+	}
+
+	printSearchResults(results, loc, isTerminal(os.Stdout))
+	return nil
+}
+
+// searchResultJSON is the JSON representation of a search result
+type searchResultJSON struct {
+	Kind    string  `json:"kind"`
+	ID      int64   `json:"id"`
+	Source  string  `json:"source"`
+	Date    *string `json:"date"`
+	Title   string  `json:"title,omitempty"`
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+// toSearchResultJSON converts the search results to their JSON representation, with the
+// dates formatted like in the table
+func toSearchResultJSON(results []store.SearchResult, loc *time.Location) []searchResultJSON {
+	converted := make([]searchResultJSON, len(results))
+	for i, result := range results {
+		converted[i] = searchResultJSON{
+			Kind:    result.Kind,
+			ID:      result.ID,
+			Source:  result.Source,
+			Title:   result.Title,
+			Snippet: result.Snippet,
+			Rank:    result.Rank,
+		}
+		if result.Date != nil {
+			date := formatSearchDate(result, loc)
+			converted[i].Date = &date
+		}
+	}
+	return converted
+}
+
+// printSearchResults prints the search results with their snippets. On a terminal the
+// matching words are shown in bold, elsewhere the highlight markers are kept.
+func printSearchResults(results []store.SearchResult, loc *time.Location, terminal bool) {
+	if len(results) == 0 {
+		fmt.Println("No results found.")
+		return
+	}
+
+	for i, result := range results {
+		header := fmt.Sprintf("%d. [%s %d] %s %s", i+1, result.Kind, result.ID, formatSearchDate(result, loc), result.Source)
+		if result.Title != "" {
+			header += " - " + result.Title
+		}
+		fmt.Println(header)
+
+		snippet := strings.Join(strings.Fields(result.Snippet), " ")
+		if terminal {
+			snippet = highlightANSI(snippet)
+		}
+		fmt.Printf("   %s\n", snippet)
+	}
+}
+
+// formatSearchDate formats the date of a search result: the start time of an event, or
+// the relevance date or range of a memory
+func formatSearchDate(result store.SearchResult, loc *time.Location) string {
+	if result.Date == nil {
+		return "-"
+	}
+	if result.Kind == store.SearchKindEvent {
+		return result.Date.In(loc).Format("2006-01-02 15:04")
+	}
+
+	start := result.Date.Format("2006-01-02")
+	if result.EndDate != nil && result.EndDate.Format("2006-01-02") != start {
+		return start + ".." + result.EndDate.Format("2006-01-02")
+	}
+	return start
+}
+
+// highlightANSI replaces the highlight markers of a snippet with bold text. The start and
+// end markers are the same, so every other marker closes a highlight.
+func highlightANSI(snippet string) string {
+	parts := strings.Split(snippet, store.HighlightStart)
+
+	var builder strings.Builder
+	for i, part := range parts {
+		if i == 0 {
+			builder.WriteString(part)
+			continue
+		}
+		if i%2 == 1 {
+			builder.WriteString(ansiBold)
+		} else {
+			builder.WriteString(ansiReset)
+		}
+		builder.WriteString(part)
+	}
+	return builder.String()
+}
+
+// isTerminal reports whether the file is a terminal rather than a pipe or a file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -2,8 +2,10 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"os"
 
 	"github.com/lepinkainen/hovimestari/internal/brief"
 	"github.com/lepinkainen/hovimestari/internal/config"
@@ -13,19 +15,20 @@ import (
 
 // ShowBriefContextCmd defines the show brief context command for Kong
 type ShowBriefContextCmd struct {
-	DaysAhead int `kong:"help='Number of days ahead to include in the brief context',default=2"`
+	DaysAhead int  `kong:"help='Number of days ahead to include in the brief context',default=2"`
+	JSON      bool `kong:"name='json',help='Print the structured brief context as JSON instead of the prompt'"`
 }
 
 // Run executes the show brief context command
 func (cmd *ShowBriefContextCmd) Run() error {
-	return runShowBriefContext(context.Background(), cmd.DaysAhead)
+	return runShowBriefContext(context.Background(), cmd.DaysAhead, cmd.JSON)
 }
 
 // runShowBriefContext runs the show brief context command, building the same context
 // that would be used for brief generation but displaying it to the user instead of
 // sending it to the LLM. This is useful for debugging and understanding what information
-// is included in the brief.
-func runShowBriefContext(ctx context.Context, daysAhead int) error {
+// is included in the brief. With asJSON the structured context is printed instead of the prompt.
+func runShowBriefContext(ctx context.Context, daysAhead int, asJSON bool) error {
 	// Get the configuration
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -48,39 +51,54 @@ func runShowBriefContext(ctx context.Context, daysAhead int) error {
 		return fmt.Errorf("failed to initialize store: %w", err)
 	}
 
-	// Load the prompts
-	prompts, err := config.LoadPrompts(cfg.PromptFilePath)
+	// Create the brief generator, the LLM isn't needed to build the context
+	generator := brief.NewGenerator(store, nil, cfg)
+	closeEmbedder, err := enableMemoryRetrieval(cfg, store, generator)
 	if err != nil {
-		return fmt.Errorf("failed to load prompts: %w", err)
+		return err
 	}
+	defer closeEmbedder()
 
-	// Create the LLM client
-	llmClient, err := llm.NewClient(cfg.GeminiAPIKey, cfg.GeminiModel, prompts)
+	// Build the brief context
+	bc, err := generator.BuildBriefContext(ctx, daysAhead)
 	if err != nil {
-		return fmt.Errorf("failed to create LLM client: %w", err)
+		return fmt.Errorf("failed to build brief context: %w", err)
 	}
-	defer func() {
-		if err := llmClient.Close(); err != nil {
-			slog.Error("Failed to close LLM client", "error", err)
-		}
-	}()
 
-	// Create the brief generator
-	generator := brief.NewGenerator(store, llmClient, cfg)
+	contextTokens := llm.EstimateBriefContextTokens(bc)
+	budget := "no budget"
+	if tokens := generator.ContextTokenBudget(); tokens > 0 {
+		budget = fmt.Sprintf("budget %d", tokens)
+	}
 
-	// Build the brief context
-	memoryStrings, userInfo, outputLanguage, err := generator.BuildBriefContext(ctx, daysAhead)
+	if asJSON {
+		data, err := json.MarshalIndent(bc, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal brief context: %w", err)
+		}
+		fmt.Println(string(data))
+		// Keep stdout valid JSON
+		fmt.Fprintf(os.Stderr, "Estimated context tokens: %d (%s)\n", contextTokens, budget)
+		return nil
+	}
+
+	// Load the prompts
+	prompts, err := config.LoadPrompts(cfg.PromptFilePath)
 	if err != nil {
-		return fmt.Errorf("failed to build brief context: %w", err)
+		return fmt.Errorf("failed to load prompts: %w", err)
 	}
 
 	// Build the prompt content
-	promptContent := llmClient.BuildBriefPrompt(memoryStrings, userInfo, outputLanguage)
+	promptContent, err := llm.BuildBriefPrompt(prompts, bc)
+	if err != nil {
+		return fmt.Errorf("failed to build prompt: %w", err)
+	}
 
 	// Print the prompt content
 	fmt.Println("=== CONTEXT GIVEN TO LLM ===")
 	fmt.Println(promptContent)
 	fmt.Println("===========================")
+	fmt.Printf("Estimated tokens: %d for the prompt, %d for the context (%s)\n", llm.EstimateTokens(promptContent), contextTokens, budget)
 
 	return nil
 }
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// UsageCmd defines the usage command for Kong
+type UsageCmd struct {
+	Days   int `kong:"help='Number of days to show daily totals for',default=30"`
+	Months int `kong:"help='Number of months to show monthly totals for',default=12"`
+}
+
+// Run executes the usage command
+func (cmd *UsageCmd) Run() error {
+	return runUsage(context.Background(), cmd.Days, cmd.Months)
+}
+
+// runUsage runs the usage command, reporting the LLM calls, token usage and estimated
+// cost recorded in the call ledger, totalled by day and by month.
+func runUsage(ctx context.Context, days, months int) error {
+	// Get the configuration
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	// Create the store
+	s, err := store.NewStore(cfg.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+	defer func() {
+		if err := s.Close(); err != nil {
+			slog.Error("Failed to close store", "error", err)
+		}
+	}()
+
+	// Initialize the store
+	if err := s.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize store: %w", err)
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	daily, err := s.GetLLMUsage(store.UsagePeriodDay, today.AddDate(0, 0, -(days-1)))
+	if err != nil {
+		return fmt.Errorf("failed to get daily usage: %w", err)
+	}
+
+	monthly, err := s.GetLLMUsage(store.UsagePeriodMonth, thisMonth.AddDate(0, -(months-1), 0))
+	if err != nil {
+		return fmt.Errorf("failed to get monthly usage: %w", err)
+	}
+
+	if len(cfg.LLMPrices) == 0 {
+		slog.Info("No llm_prices configured, costs are shown as zero")
+	}
+
+	fmt.Printf("Daily usage (last %d days):\n", days)
+	printUsage("DAY", daily)
+	fmt.Printf("\nMonthly usage (last %d months):\n", months)
+	printUsage("MONTH", monthly)
+
+	return nil
+}
+
+// printUsage prints the usage rows as a table
+func printUsage(periodHeader string, usage []store.LLMUsage) {
+	if len(usage) == 0 {
+		fmt.Println("No LLM calls recorded.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "%s\tCALLS\tERRORS\tINPUT TOKENS\tOUTPUT TOKENS\tCOST (EUR)\t\n", periodHeader)
+
+	var total store.LLMUsage
+	for _, u := range usage {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%.4f\t\n", u.Period, u.Calls, u.Errors, u.InputTokens, u.OutputTokens, u.Cost)
+		total.Calls += u.Calls
+		total.Errors += u.Errors
+		total.InputTokens += u.InputTokens
+		total.OutputTokens += u.OutputTokens
+		total.Cost += u.Cost
+	}
+	fmt.Fprintf(w, "TOTAL\t%d\t%d\t%d\t%d\t%.4f\t\n", total.Calls, total.Errors, total.InputTokens, total.OutputTokens, total.Cost)
+
+	if err := w.Flush(); err != nil {
+		slog.Error("Failed to write usage table", "error", err)
+	}
+}
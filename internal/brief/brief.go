@@ -3,348 +3,193 @@ package brief
 import (
 	"context"
 	"fmt"
-	"strings"
+	"log/slog"
 	"time"
 
 	"github.com/lepinkainen/hovimestari/internal/config"
-	weatherimporter "github.com/lepinkainen/hovimestari/internal/importer/weather"
 	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/output"
 	"github.com/lepinkainen/hovimestari/internal/store"
-	"github.com/lepinkainen/hovimestari/internal/weather"
 )
 
 // Generator handles generating briefs based on memories
 type Generator struct {
-	store *store.Store
-	llm   *llm.Client
-	cfg   *config.Config
+	store     *store.Store
+	llm       llm.Provider
+	cfg       *config.Config
+	retriever *Retriever
+	toolbox   *llm.Toolbox
+	verifier  *Verifier
+	trust     *TrustPolicy
+	cache     *llm.Cache
 }
 
 // NewGenerator creates a new brief generator
-func NewGenerator(store *store.Store, llm *llm.Client, cfg *config.Config) *Generator {
+func NewGenerator(store *store.Store, llm llm.Provider, cfg *config.Config) *Generator {
 	return &Generator{
 		store: store,
 		llm:   llm,
 		cfg:   cfg,
+		trust: NewTrustPolicy(cfg.SourceTrust),
 	}
 }
 
-// getRelevantMemoryStrings fetches relevant memories and formats them as strings
-func (g *Generator) getRelevantMemoryStrings(startDate, endDate time.Time) ([]string, []store.Memory, error) {
-	// Get relevant memories
-	memories, err := g.store.GetRelevantMemories(startDate, endDate)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get relevant memories: %w", err)
-	}
-
-	// Convert memories to strings
-	var memoryStrings []string
-	for _, memory := range memories {
-		// Skip weather memories as they are handled separately in getWeatherData()
-		if strings.HasPrefix(memory.Source, "weather-metno:") {
-			continue
-		}
-		
-		var dateInfo string
-		if memory.RelevanceDate != nil {
-			dateInfo = fmt.Sprintf(" (relevant on %s)", memory.RelevanceDate.Format("2006-01-02"))
-		}
-		memoryStrings = append(memoryStrings, fmt.Sprintf("%s%s [Source: %s]", memory.Content, dateInfo, memory.Source))
-	}
-
-	return memoryStrings, memories, nil
-}
-
-// findBirthdaysToday checks for family members' birthdays on the given date
-func (g *Generator) findBirthdaysToday(now time.Time) []string {
-	var birthdaysToday []string
-	for _, member := range g.cfg.Family {
-		if member.Birthday != "" {
-			birthday, err := time.Parse("2006-01-02", member.Birthday)
-			if err != nil {
-				continue // Skip invalid birthdays
-			}
-
-			// Check if today is their birthday (ignore year)
-			if birthday.Month() == now.Month() && birthday.Day() == now.Day() {
-				age := now.Year() - birthday.Year()
-				birthdaysToday = append(birthdaysToday, fmt.Sprintf("%s (%d years)", member.Name, age))
-			}
-		}
-	}
-	return birthdaysToday
+// SetRetriever enables semantic retrieval of undated memories. Without a retriever every
+// undated memory is included.
+func (g *Generator) SetRetriever(retriever *Retriever) {
+	g.retriever = retriever
 }
 
-// getFamilyNames returns a list of family member names
-func (g *Generator) getFamilyNames() []string {
-	var familyNames []string
-	for _, member := range g.cfg.Family {
-		familyNames = append(familyNames, member.Name)
-	}
-	return familyNames
+// SetToolbox lets the model call tools while generating briefs and answers
+func (g *Generator) SetToolbox(toolbox *llm.Toolbox) {
+	g.toolbox = toolbox
 }
 
-// getOngoingCalendarEvents retrieves calendar events that are currently ongoing
-func (g *Generator) getOngoingCalendarEvents(now time.Time) ([]string, error) {
-	// Get ongoing calendar events directly from the database
-	events, err := g.store.GetOngoingCalendarEvents(now)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get ongoing calendar events: %w", err)
-	}
-
-	var ongoingEvents []string
-	for _, event := range events {
-		// Format the ongoing event
-		var endTimeStr string
-		if event.EndTime != nil {
-			endTimeStr = fmt.Sprintf(" (until %s)", event.EndTime.Format("15:04"))
-		}
-
-		ongoingEvent := fmt.Sprintf("%s%s", event.Summary, endTimeStr)
-		ongoingEvents = append(ongoingEvents, ongoingEvent)
-	}
-
-	return ongoingEvents, nil
+// SetCache sets the response cache of the LLM provider. The responses it holds back are
+// committed once the generator accepts a brief or an answer, and dropped otherwise.
+func (g *Generator) SetCache(cache *llm.Cache) {
+	g.cache = cache
 }
 
-// getWeatherData fetches weather forecasts and changes
-func (g *Generator) getWeatherData(now, endDate time.Time) (map[string]string, string, error) {
-	// Get weather forecasts from memories
-	weatherForecasts, err := weatherimporter.GetLatestForecasts(g.store, now, endDate, g.cfg.LocationName)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to get weather forecasts: %w", err)
+// settleCache commits the responses held back by the response cache if the result they
+// produced was accepted, and drops them otherwise
+func (g *Generator) settleCache(accepted bool) {
+	switch {
+	case g.cache == nil:
+	case accepted:
+		g.cache.Commit()
+	default:
+		g.cache.Discard()
 	}
-
-	// Get hourly forecast for today
-	hourlyForecast, err := weather.GetCurrentDayHourlyForecast(g.cfg.Latitude, g.cfg.Longitude)
-	if err != nil {
-		return weatherForecasts, "", fmt.Errorf("failed to get hourly forecast: %w", err)
-	}
-
-	return weatherForecasts, hourlyForecast, nil
 }
 
-// assembleUserInfo creates the userInfo map with all relevant information
-func (g *Generator) assembleUserInfo(
-	now time.Time,
-	familyNames []string,
-	ongoingEvents []string,
-	birthdaysToday []string,
-	weatherForecasts map[string]string,
-	hourlyForecast string,
-) map[string]string {
-	// Format the current date and time in standard format (LLM will handle translation)
-	formattedDate := now.Format("Monday, 2 January 2006")
-	formattedTime := now.Format("15:04")
-
-	// Add user information
-	userInfo := map[string]string{
-		"Date":        formattedDate,
-		"CurrentTime": formattedTime,
-		"Timezone":    g.cfg.Timezone,
-		"Location":    g.cfg.LocationName,
-		"Family":      strings.Join(familyNames, ", "),
-	}
-
-	// Add ongoing events if any
-	if len(ongoingEvents) > 0 {
-		userInfo["OngoingEvents"] = strings.Join(ongoingEvents, "\n")
-	}
-
-	// Add today's weather if available
-	todayStr := now.Format("2006-01-02")
-	if forecast, ok := weatherForecasts[todayStr]; ok {
-		userInfo["Weather"] = forecast
-	} else {
-		userInfo["Weather"] = "Weather information not available"
+// ContextTokenBudget returns the token budget for the brief context of the model the
+// brief is generated with, 0 if there's no limit
+func (g *Generator) ContextTokenBudget() int {
+	model := llm.PrimaryModel(g.cfg)
+	if g.llm != nil {
+		model = g.llm.Model()
 	}
-
-	// Add hourly forecast for today if available
-	if hourlyForecast != "" {
-		userInfo["HourlyForecastToday"] = hourlyForecast
-	}
-
-	// Future weather forecasts are already included in the relevant information
-	// so we don't need to add them separately to avoid duplication
-
-	// Add birthdays if any
-	if len(birthdaysToday) > 0 {
-		userInfo["Birthdays"] = strings.Join(birthdaysToday, ", ")
-	}
-
-	return userInfo
+	return llm.ContextTokenBudget(g.cfg, model)
 }
 
-// formatCalendarEventString formats a calendar event as a string for LLM context
-func formatCalendarEventString(event store.CalendarEvent) string {
-	var builder strings.Builder
-
-	// Add the event summary
-	fmt.Fprintf(&builder, "Calendar Event: %s", event.Summary)
-
-	// Add the event time
-	startTime := event.StartTime.Format("2006-01-02 15:04")
-
-	if event.EndTime != nil {
-		endTime := event.EndTime.Format("15:04")
-		// Check if the event spans multiple days
-		if event.StartTime.Day() != event.EndTime.Day() {
-			endTime = event.EndTime.Format("2006-01-02 15:04")
-		}
-		fmt.Fprintf(&builder, " from %s to %s", startTime, endTime)
-	} else {
-		fmt.Fprintf(&builder, " at %s", startTime)
-	}
-
-	// Add the location if available
-	if event.Location != nil && *event.Location != "" {
-		fmt.Fprintf(&builder, " at %s", *event.Location)
+// contextProviders returns the providers used to fill in the brief context
+func (g *Generator) contextProviders() []ContextProvider {
+	return []ContextProvider{
+		NewFamilyProvider(g.cfg.Family),
+		NewCalendarProvider(g.store, g.trust),
+		NewMemoryProvider(g.store, g.retriever, g.trust),
+		NewWeatherProvider(g.store, g.cfg.LocationName, g.cfg.Latitude, g.cfg.Longitude),
 	}
-
-	// Add the description if available
-	if event.Description != nil && *event.Description != "" {
-		fmt.Fprintf(&builder, ". Description: %s", *event.Description)
-	}
-
-	return builder.String()
 }
 
-// getCalendarEventStrings fetches relevant calendar events and formats them as strings
-func (g *Generator) getCalendarEventStrings(startDate, endDate time.Time) ([]string, error) {
-	// Get relevant calendar events
-	events, err := g.store.GetRelevantCalendarEvents(startDate, endDate)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get relevant calendar events: %w", err)
+// newBriefContext creates an empty brief context covering today and daysAhead days after it
+func newBriefContext(now time.Time, daysAhead int, timezone, location, language string) *llm.BriefContext {
+	bc := &llm.BriefContext{
+		Now:      now,
+		Timezone: timezone,
+		Location: location,
+		Language: language,
 	}
 
-	// Convert events to strings
-	var eventStrings []string
-	for _, event := range events {
-		eventStr := formatCalendarEventString(event)
-		dateInfo := fmt.Sprintf(" (relevant on %s)", event.StartTime.Format("2006-01-02"))
-		eventStrings = append(eventStrings, fmt.Sprintf("%s%s [Source: %s]", eventStr, dateInfo, event.Source))
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for i := 0; i <= daysAhead; i++ {
+		bc.Days = append(bc.Days, llm.BriefDay{Date: startOfDay.AddDate(0, 0, i)})
 	}
 
-	return eventStrings, nil
+	return bc
 }
 
 // BuildBriefContext builds the context for a daily brief without generating it
-func (g *Generator) BuildBriefContext(ctx context.Context, daysAhead int) ([]string, map[string]string, string, error) {
-	// Get the date range for relevant memories
+func (g *Generator) BuildBriefContext(ctx context.Context, daysAhead int) (*llm.BriefContext, error) {
 	loc, err := time.LoadLocation(g.cfg.Timezone)
 	if err != nil {
-		return nil, nil, "", fmt.Errorf("failed to load timezone: %w", err)
-	}
-
-	now := time.Now().In(loc)
-	startDate := now
-	endDate := startDate.AddDate(0, 0, daysAhead)
-
-	// Get relevant memories and convert to strings
-	memoryStrings, _, err := g.getRelevantMemoryStrings(startDate, endDate)
-	if err != nil {
-		return nil, nil, "", err
+		return nil, fmt.Errorf("failed to load timezone: %w", err)
 	}
 
-	// Get relevant calendar events and convert to strings
-	calendarEventStrings, err := g.getCalendarEventStrings(startDate, endDate)
-	if err != nil {
-		return nil, nil, "", err
+	// Get output language from config, default to Finnish if not specified
+	outputLanguage := g.cfg.OutputLanguage
+	if outputLanguage == "" {
+		outputLanguage = "Finnish"
 	}
 
-	// Combine memory strings and calendar event strings
-	allMemoryStrings := append(memoryStrings, calendarEventStrings...)
-
-	// Find birthdays for today
-	birthdaysToday := g.findBirthdaysToday(now)
-
-	// Get family names
-	familyNames := g.getFamilyNames()
-
-	// Get ongoing calendar events
-	ongoingEvents, err := g.getOngoingCalendarEvents(now)
-	if err != nil {
-		// Log the error but continue - ongoing events are non-critical
-		fmt.Printf("Warning: %v\n", err)
-		ongoingEvents = []string{}
-	}
+	bc := newBriefContext(time.Now().In(loc), daysAhead, g.cfg.Timezone, g.cfg.LocationName, outputLanguage)
 
-	// Get weather data
-	weatherForecasts, hourlyForecast, err := g.getWeatherData(now, endDate)
-	if err != nil {
-		// Log the error but continue - weather data is non-critical
-		fmt.Printf("Warning: %v\n", err)
+	for _, provider := range g.contextProviders() {
+		if err := provider.Fill(ctx, bc); err != nil {
+			// A single failing source shouldn't prevent the brief
+			slog.Warn("Failed to fill brief context", "provider", provider.Name(), "error", err)
+		}
 	}
 
-	// Assemble the user info map
-	userInfo := g.assembleUserInfo(
-		now,
-		familyNames,
-		ongoingEvents,
-		birthdaysToday,
-		weatherForecasts,
-		hourlyForecast,
-	)
-
-	// Get output language from config, default to Finnish if not specified
-	outputLanguage := g.cfg.OutputLanguage
-	if outputLanguage == "" {
-		outputLanguage = "Finnish"
+	// Keep the context within the token budget of the model
+	if budget := g.ContextTokenBudget(); budget > 0 {
+		dropped := trimBriefContext(bc, budget)
+		logDroppedItems(dropped, budget, llm.EstimateBriefContextTokens(bc))
 	}
 
-	return allMemoryStrings, userInfo, outputLanguage, nil
+	return bc, nil
 }
 
-// GenerateDailyBrief generates a daily brief based on memories
-func (g *Generator) GenerateDailyBrief(ctx context.Context, daysAhead int) (string, error) {
+// GenerateDailyBrief generates a daily brief based on memories. The LLM is asked for a
+// JSON brief, which is validated; a free-form answer is returned as a plain brief. With a
+// verifier set, the brief is checked against the context it was generated from. Only a
+// JSON brief without issues is kept in the response cache.
+func (g *Generator) GenerateDailyBrief(ctx context.Context, daysAhead int) (*output.Brief, error) {
 	// Build the context
-	memoryStrings, userInfo, outputLanguage, err := g.BuildBriefContext(ctx, daysAhead)
+	bc, err := g.BuildBriefContext(ctx, daysAhead)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Generate the brief
-	brief, err := g.llm.GenerateBrief(ctx, memoryStrings, userInfo, outputLanguage)
+	brief, err := g.generateBrief(ctx, bc, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate brief: %w", err)
+		g.settleCache(false)
+		return nil, err
 	}
 
+	accepted := !brief.IsPlain()
+	if g.verifier != nil {
+		var verified bool
+		brief, verified = g.verifyBrief(ctx, bc, brief)
+		accepted = accepted && verified
+	}
+	g.settleCache(accepted)
+
 	return brief, nil
 }
 
-// GenerateResponse generates a response to a user query
-func (g *Generator) GenerateResponse(ctx context.Context, query string) (string, error) {
-	// Get all memories (we could be more selective here)
-	startDate := time.Now().AddDate(-1, 0, 0) // Look back 1 year
-	endDate := time.Now().AddDate(0, 1, 0)    // Look ahead 1 month
-
-	// Get relevant memories
-	memories, err := g.store.GetRelevantMemories(startDate, endDate)
-	if err != nil {
-		return "", fmt.Errorf("failed to get memories: %w", err)
+// generateBrief asks the LLM for a brief and parses it. With issues given, the brief is
+// regenerated with the issues of the previous one as feedback.
+func (g *Generator) generateBrief(ctx context.Context, bc *llm.BriefContext, issues []string) (*output.Brief, error) {
+	var text string
+	var err error
+	switch {
+	case len(issues) > 0:
+		text, err = g.regenerateBrief(ctx, bc, issues)
+	case g.toolbox != nil:
+		text, err = g.toolbox.GenerateBrief(ctx, g.llm, bc)
+	default:
+		text, err = g.llm.GenerateBrief(ctx, bc)
 	}
-
-	// Convert memories to strings
-	var memoryStrings []string
-	for _, memory := range memories {
-		var dateInfo string
-		if memory.RelevanceDate != nil {
-			dateInfo = fmt.Sprintf(" (relevant on %s)", memory.RelevanceDate.Format("2006-01-02"))
-		}
-		memoryStrings = append(memoryStrings, fmt.Sprintf("%s%s [Source: %s]", memory.Content, dateInfo, memory.Source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate brief: %w", err)
 	}
 
-	// Get output language from config, default to Finnish if not specified
-	outputLanguage := g.cfg.OutputLanguage
-	if outputLanguage == "" {
-		outputLanguage = "Finnish"
+	// Record which model finally answered - with a fallback chain it may not be the first one
+	slog.Info("Brief generated", "provider", g.llm.Name(), "model", g.llm.Model())
+	if _, err := g.store.AddBrief(text, g.llm.Name(), g.llm.Model()); err != nil {
+		slog.Warn("Failed to record generated brief", "error", err)
 	}
 
-	// Generate the response
-	response, err := g.llm.GenerateResponse(ctx, query, memoryStrings, outputLanguage)
+	brief, err := output.ParseBrief(text)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate response: %w", err)
+		return nil, err
+	}
+	if brief.IsPlain() {
+		slog.Warn("LLM returned a free-form brief instead of JSON, sending it as plain text")
 	}
 
-	return response, nil
+	return brief, nil
 }
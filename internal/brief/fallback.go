@@ -0,0 +1,167 @@
+package brief
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/output"
+)
+
+// TemplateProvider is the provider name recorded for briefs rendered without an LLM
+const TemplateProvider = "template"
+
+// templateBriefText is the Go template used to render the non-LLM brief
+const templateBriefText = `Daily brief for {{.Now.Format "Monday, 2 January 2006"}}{{with .Location}}, {{.}}{{end}}
+{{- with .Birthdays}}
+
+🎂 Birthdays today:{{range $i, $b := .}}{{if $i}},{{end}} {{$b.Name}} ({{$b.Age}} years){{end}}
+{{- end}}
+{{- with .Ongoing}}
+
+Ongoing:
+{{- range .}}
+- {{.Summary}}{{with .End}} (until {{.Format "15:04"}}){{end}}
+{{- end}}
+{{- end}}
+{{- range $i, $day := .Days}}
+
+== {{dayTitle $i $day}} ==
+{{- with $day.Weather}}
+🌤️ {{.}}
+{{- end}}
+{{- with $day.Events}}
+📅 Events:
+{{- range .}}
+- {{eventLine .}}
+{{- end}}
+{{- end}}
+{{- with lunchLines $day.Meals}}
+🍽️ Lunch:
+{{- range .}}
+- {{.}}
+{{- end}}
+{{- end}}
+{{- with priceLines $day.Prices}}
+⚡ {{join . " "}}
+{{- end}}
+{{- with $day.Notes}}
+📝 Notes:
+{{- range .}}
+- {{.}}
+{{- end}}
+{{- end}}
+{{- if emptyDay $day}}
+Nothing scheduled.
+{{- end}}
+{{- end}}
+`
+
+var briefTemplate = template.Must(template.New("brief").
+	Funcs(template.FuncMap{
+		"join":       strings.Join,
+		"dayTitle":   dayTitle,
+		"eventLine":  eventLine,
+		"lunchLines": lunchLines,
+		"priceLines": priceLines,
+		"emptyDay":   emptyDay,
+	}).
+	Parse(templateBriefText))
+
+// GenerateTemplateBrief renders a plain structured brief from the same context used for
+// the LLM brief, without calling an LLM. It's used when every LLM fails or when explicitly
+// requested.
+func (g *Generator) GenerateTemplateBrief(ctx context.Context, daysAhead int) (*output.Brief, error) {
+	bc, err := g.BuildBriefContext(ctx, daysAhead)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := renderTemplateBrief(bc)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := g.store.AddBrief(text, TemplateProvider, TemplateProvider); err != nil {
+		slog.Warn("Failed to record generated brief", "error", err)
+	}
+
+	return output.PlainBrief(text), nil
+}
+
+// renderTemplateBrief executes the brief template with the given context
+func renderTemplateBrief(bc *llm.BriefContext) (string, error) {
+	var sb strings.Builder
+	if err := briefTemplate.Execute(&sb, bc); err != nil {
+		return "", fmt.Errorf("failed to render template brief: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// dayTitle returns the heading for the day at the given index of the brief
+func dayTitle(index int, day llm.BriefDay) string {
+	title := day.Date.Format("Monday 2 January")
+	switch index {
+	case 0:
+		return "Today, " + title
+	case 1:
+		return "Tomorrow, " + title
+	default:
+		return title
+	}
+}
+
+// eventLine formats a calendar event as a single line for the template brief
+func eventLine(event llm.BriefEvent) string {
+	var when string
+	switch {
+	case event.AllDay():
+		when = "All day"
+	case event.End != nil:
+		when = fmt.Sprintf("%s–%s", event.Start.Format("15:04"), event.End.Format("15:04"))
+	default:
+		when = event.Start.Format("15:04")
+	}
+
+	line := fmt.Sprintf("%s %s", when, event.Summary)
+	if event.Location != "" {
+		line = fmt.Sprintf("%s (%s)", line, event.Location)
+	}
+	return line
+}
+
+// lunchLines returns the dish names from the school lunch memories, skipping the
+// component listings
+func lunchLines(meals []string) []string {
+	var lines []string
+	for _, meal := range meals {
+		for line := range strings.SplitSeq(meal, "\n") {
+			if line == "" || strings.HasPrefix(line, "Osat:") {
+				continue
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// priceLines returns the summary lines of the electricity price memory, skipping the
+// full hourly listing
+func priceLines(prices string) []string {
+	var lines []string
+	for line := range strings.SplitSeq(prices, "\n") {
+		if line == "" || strings.HasPrefix(line, "Hourly prices") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// emptyDay reports whether the day has nothing to show
+func emptyDay(day llm.BriefDay) bool {
+	return day.Weather == "" && len(day.Events) == 0 && len(day.Meals) == 0 && day.Prices == "" && len(day.Notes) == 0
+}
@@ -0,0 +1,70 @@
+package brief
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+)
+
+func TestRenderTemplateBrief(t *testing.T) {
+	now := time.Date(2025, 3, 10, 7, 30, 0, 0, time.UTC)
+	bc := newBriefContext(now, 2, "UTC", "Helsinki", "English")
+	bc.Birthdays = []llm.Birthday{{Name: "Alice", Age: 40}}
+
+	end := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+	bc.Days[0].Weather = "cloudy, temperature 1-5°C"
+	bc.Days[0].Events = []llm.BriefEvent{
+		{Summary: "Dentist", Start: time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC), End: &end, Location: "Clinic"},
+	}
+	bc.Days[0].Meals = []string{"Lounas: Pasta (L)\nOsat: pasta, sauce\nKasvislounas: Soup"}
+	bc.Days[1].Prices = "Electricity prices on 2025-03-11 (CHEAP DAY - avg 3.0 c/kWh).\nMin: 1.0 c/kWh at 03:00\nHourly prices (c/kWh): 00=1.0"
+
+	got, err := renderTemplateBrief(bc)
+	if err != nil {
+		t.Fatalf("renderTemplateBrief() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"Daily brief for Monday, 10 March 2025, Helsinki",
+		"Birthdays today: Alice (40 years)",
+		"== Today, Monday 10 March ==\n🌤️ cloudy, temperature 1-5°C",
+		"- 09:00–10:00 Dentist (Clinic)",
+		"- Lounas: Pasta (L)\n- Kasvislounas: Soup",
+		"⚡ Electricity prices on 2025-03-11 (CHEAP DAY - avg 3.0 c/kWh). Min: 1.0 c/kWh at 03:00",
+		"== Wednesday 12 March ==\nNothing scheduled.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered brief missing %q:\n%s", want, got)
+		}
+	}
+
+	for _, unwanted := range []string{"Osat:", "Hourly prices"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("rendered brief should not contain %q:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestEventLine(t *testing.T) {
+	start := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	allDayEnd := start.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name     string
+		event    llm.BriefEvent
+		expected string
+	}{
+		{"all day", llm.BriefEvent{Summary: "Holiday", Start: start, End: &allDayEnd}, "All day Holiday"},
+		{"no end time", llm.BriefEvent{Summary: "Call", Start: start.Add(14 * time.Hour)}, "14:00 Call"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := eventLine(test.event); got != test.expected {
+				t.Errorf("eventLine() = %q, expected %q", got, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package brief
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/importer/electricityprice"
+	"github.com/lepinkainen/hovimestari/internal/importer/schoollunch"
+	weatherimporter "github.com/lepinkainen/hovimestari/internal/importer/weather"
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/store"
+	"github.com/lepinkainen/hovimestari/internal/weather"
+)
+
+// ContextProvider fills in part of the brief context from a single data source
+type ContextProvider interface {
+	// Name returns the name of the provider, used in log messages
+	Name() string
+
+	// Fill adds the provider's data to the brief context
+	Fill(ctx context.Context, bc *llm.BriefContext) error
+}
+
+// briefRange returns the start of the first day and the end of the last day in the brief
+func briefRange(bc *llm.BriefContext) (time.Time, time.Time) {
+	if len(bc.Days) == 0 {
+		return bc.Now, bc.Now
+	}
+	start := bc.Days[0].Date
+	end := bc.Days[len(bc.Days)-1].Date.AddDate(0, 0, 1).Add(-time.Second)
+	return start, end
+}
+
+// FamilyProvider adds family member names and today's birthdays
+type FamilyProvider struct {
+	family []config.FamilyMember
+}
+
+// NewFamilyProvider creates a new family provider
+func NewFamilyProvider(family []config.FamilyMember) *FamilyProvider {
+	return &FamilyProvider{family: family}
+}
+
+// Name returns the provider name
+func (p *FamilyProvider) Name() string {
+	return "family"
+}
+
+// Fill adds family names and birthdays on the brief date
+func (p *FamilyProvider) Fill(ctx context.Context, bc *llm.BriefContext) error {
+	for _, member := range p.family {
+		bc.Family = append(bc.Family, member.Name)
+
+		if member.Birthday == "" {
+			continue
+		}
+
+		birthday, err := time.Parse("2006-01-02", member.Birthday)
+		if err != nil {
+			continue // Skip invalid birthdays
+		}
+
+		// Check if today is their birthday (ignore year)
+		if birthday.Month() == bc.Now.Month() && birthday.Day() == bc.Now.Day() {
+			bc.Birthdays = append(bc.Birthdays, llm.Birthday{
+				Name: member.Name,
+				Age:  bc.Now.Year() - birthday.Year(),
+			})
+		}
+	}
+
+	return nil
+}
+
+// CalendarProvider adds calendar events for each day and the currently ongoing events
+type CalendarProvider struct {
+	store *store.Store
+	trust *TrustPolicy
+}
+
+// NewCalendarProvider creates a new calendar provider. The event texts are cleaned with
+// the trust policy.
+func NewCalendarProvider(store *store.Store, trust *TrustPolicy) *CalendarProvider {
+	return &CalendarProvider{store: store, trust: trust}
+}
+
+// Name returns the provider name
+func (p *CalendarProvider) Name() string {
+	return "calendar"
+}
+
+// Fill adds the ongoing events and the events starting on each day of the brief
+func (p *CalendarProvider) Fill(ctx context.Context, bc *llm.BriefContext) error {
+	ongoing, err := p.store.GetOngoingCalendarEvents(bc.Now)
+	if err != nil {
+		return fmt.Errorf("failed to get ongoing calendar events: %w", err)
+	}
+	for _, event := range p.trust.Events(ongoing) {
+		bc.Ongoing = append(bc.Ongoing, toBriefEvent(event, bc.Now.Location()))
+	}
+
+	start, end := briefRange(bc)
+	events, err := p.store.GetRelevantCalendarEvents(start, end)
+	if err != nil {
+		return fmt.Errorf("failed to get relevant calendar events: %w", err)
+	}
+	addEventsToDays(bc, p.trust.Events(events))
+
+	return nil
+}
+
+// addEventsToDays adds events to the day they start on. Events that started before the
+// brief are skipped since the ongoing ones are already listed separately.
+func addEventsToDays(bc *llm.BriefContext, events []store.CalendarEvent) {
+	loc := bc.Now.Location()
+	for _, event := range events {
+		briefEvent := toBriefEvent(event, loc)
+		if day := bc.Day(briefEvent.Start); day != nil {
+			day.Events = append(day.Events, briefEvent)
+		}
+	}
+}
+
+// toBriefEvent converts a stored calendar event to a brief event in the given timezone
+func toBriefEvent(event store.CalendarEvent, loc *time.Location) llm.BriefEvent {
+	briefEvent := llm.BriefEvent{
+		Summary: event.Summary,
+		Start:   event.StartTime.In(loc),
+		Source:  event.Source,
+	}
+	if event.EndTime != nil {
+		end := event.EndTime.In(loc)
+		briefEvent.End = &end
+	}
+	if event.Location != nil {
+		briefEvent.Location = *event.Location
+	}
+	if event.Description != nil {
+		briefEvent.Description = *event.Description
+	}
+	return briefEvent
+}
+
+// MemoryProvider adds school lunches, electricity prices and other stored memories
+type MemoryProvider struct {
+	store     *store.Store
+	retriever *Retriever
+	trust     *TrustPolicy
+}
+
+// NewMemoryProvider creates a new memory provider. If retriever is set, only the undated
+// memories most similar to the brief are included. The memory contents are cleaned with
+// the trust policy.
+func NewMemoryProvider(store *store.Store, retriever *Retriever, trust *TrustPolicy) *MemoryProvider {
+	return &MemoryProvider{store: store, retriever: retriever, trust: trust}
+}
+
+// Name returns the provider name
+func (p *MemoryProvider) Name() string {
+	return "memories"
+}
+
+// Fill adds the memories relevant to the brief to their days
+func (p *MemoryProvider) Fill(ctx context.Context, bc *llm.BriefContext) error {
+	start, end := briefRange(bc)
+	memories, err := p.store.GetRelevantMemories(start, end)
+	if err != nil {
+		return fmt.Errorf("failed to get relevant memories: %w", err)
+	}
+
+	if p.retriever != nil {
+		selected, err := p.retriever.SelectMemories(ctx, briefQuery(bc), memories)
+		if err != nil {
+			// Too many notes is better than none
+			slog.Warn("Failed to select memories by similarity, including all", "error", err)
+		} else {
+			memories = selected
+		}
+	}
+
+	addMemoriesToDays(bc, p.trust.Memories(memories))
+	return nil
+}
+
+// addMemoriesToDays sorts memories into meals, prices and notes for the days they're
+// relevant on. A memory covering several days is added to each of them with its range, so
+// the brief mentions it every day.
+func addMemoriesToDays(bc *llm.BriefContext, memories []store.Memory) {
+	for _, memory := range memories {
+		// Weather memories are handled by the weather provider
+		if strings.HasPrefix(memory.Source, weatherimporter.SourcePrefix+":") {
+			continue
+		}
+
+		if memory.RelevanceStart == nil {
+			bc.Notes = append(bc.Notes, fmt.Sprintf("%s [Source: %s]", memory.Content, memory.Source))
+			continue
+		}
+
+		first := memory.RelevanceStart.Format("2006-01-02")
+		last := memory.RelevanceEnd.Format("2006-01-02")
+		note := fmt.Sprintf("%s [Source: %s]", memory.Content, memory.Source)
+		if first != last {
+			note = fmt.Sprintf("%s (%s to %s) [Source: %s]", memory.Content, first, last, memory.Source)
+		}
+
+		for i := range bc.Days {
+			day := &bc.Days[i]
+			if date := day.Date.Format("2006-01-02"); date < first || date > last {
+				continue
+			}
+
+			switch {
+			case strings.HasPrefix(memory.Source, schoollunch.SourcePrefix+":"):
+				day.Meals = append(day.Meals, memory.Content)
+			case strings.HasPrefix(memory.Source, electricityprice.SourcePrefix+":"):
+				day.Prices = memory.Content
+			default:
+				day.Notes = append(day.Notes, note)
+			}
+		}
+	}
+}
+
+// WeatherProvider adds the daily forecasts and today's hourly forecast
+type WeatherProvider struct {
+	store     *store.Store
+	location  string
+	latitude  float64
+	longitude float64
+}
+
+// NewWeatherProvider creates a new weather provider
+func NewWeatherProvider(store *store.Store, location string, latitude, longitude float64) *WeatherProvider {
+	return &WeatherProvider{
+		store:     store,
+		location:  location,
+		latitude:  latitude,
+		longitude: longitude,
+	}
+}
+
+// Name returns the provider name
+func (p *WeatherProvider) Name() string {
+	return "weather"
+}
+
+// Fill adds the latest stored forecast for each day and today's hourly forecast
+func (p *WeatherProvider) Fill(ctx context.Context, bc *llm.BriefContext) error {
+	start, end := briefRange(bc)
+	forecasts, err := weatherimporter.GetLatestForecasts(p.store, start, end, p.location)
+	if err != nil {
+		return fmt.Errorf("failed to get weather forecasts: %w", err)
+	}
+
+	for i := range bc.Days {
+		bc.Days[i].Weather = trimWeatherPrefix(forecasts[bc.Days[i].Date.Format("2006-01-02")])
+	}
+
+	hourlyForecast, err := weather.GetCurrentDayHourlyForecast(p.latitude, p.longitude)
+	if err != nil {
+		return fmt.Errorf("failed to get hourly forecast: %w", err)
+	}
+	if len(bc.Days) > 0 {
+		bc.Days[0].HourlyForecast = hourlyForecast
+	}
+
+	return nil
+}
+
+// trimWeatherPrefix removes the "Weather YYYY-MM-DD: " prefix from a stored forecast,
+// since the day already carries the date
+func trimWeatherPrefix(forecast string) string {
+	if rest, ok := strings.CutPrefix(forecast, "Weather "); ok {
+		if _, description, found := strings.Cut(rest, ": "); found {
+			return description
+		}
+	}
+	return forecast
+}
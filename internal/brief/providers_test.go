@@ -0,0 +1,135 @@
+package brief
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+func TestNewBriefContext(t *testing.T) {
+	now := time.Date(2025, 3, 10, 7, 30, 0, 0, time.UTC)
+	bc := newBriefContext(now, 2, "UTC", "Helsinki", "English")
+
+	if len(bc.Days) != 3 {
+		t.Fatalf("expected 3 days, got %d", len(bc.Days))
+	}
+	if !bc.Days[0].Date.Equal(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected first day to start at midnight, got %v", bc.Days[0].Date)
+	}
+	if bc.Day(now.AddDate(0, 0, 3)) != nil {
+		t.Error("expected no day outside the brief range")
+	}
+}
+
+func TestFamilyProviderFill(t *testing.T) {
+	now := time.Date(2025, 3, 10, 7, 30, 0, 0, time.UTC)
+	bc := newBriefContext(now, 0, "UTC", "", "English")
+
+	provider := NewFamilyProvider([]config.FamilyMember{
+		{Name: "Alice", Birthday: "1985-03-10"},
+		{Name: "Bob", Birthday: "1990-06-01"},
+		{Name: "Carol", Birthday: "invalid"},
+	})
+	if err := provider.Fill(context.Background(), bc); err != nil {
+		t.Fatalf("Fill() error = %v", err)
+	}
+
+	if len(bc.Family) != 3 {
+		t.Errorf("expected 3 family members, got %v", bc.Family)
+	}
+	if len(bc.Birthdays) != 1 || bc.Birthdays[0].Name != "Alice" || bc.Birthdays[0].Age != 40 {
+		t.Errorf("unexpected birthdays: %v", bc.Birthdays)
+	}
+}
+
+func TestAddEventsToDays(t *testing.T) {
+	now := time.Date(2025, 3, 10, 7, 30, 0, 0, time.UTC)
+	bc := newBriefContext(now, 1, "UTC", "", "English")
+
+	events := []store.CalendarEvent{
+		{Summary: "Dentist", StartTime: time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)},
+		{Summary: "Holiday", StartTime: time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{Summary: "Started yesterday", StartTime: time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC)},
+	}
+	addEventsToDays(bc, events)
+
+	if len(bc.Days[0].Events) != 1 || bc.Days[0].Events[0].Summary != "Dentist" {
+		t.Errorf("unexpected events for today: %v", bc.Days[0].Events)
+	}
+	if len(bc.Days[1].Events) != 1 || bc.Days[1].Events[0].Summary != "Holiday" {
+		t.Errorf("unexpected events for tomorrow: %v", bc.Days[1].Events)
+	}
+}
+
+func TestAddMemoriesToDays(t *testing.T) {
+	now := time.Date(2025, 3, 10, 7, 30, 0, 0, time.UTC)
+	bc := newBriefContext(now, 1, "UTC", "", "English")
+
+	today := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	tomorrow := today.AddDate(0, 0, 1)
+	memories := []store.Memory{
+		{Content: "Lounas: Pasta", RelevanceStart: &today, RelevanceEnd: &today, Source: "schoollunch:School"},
+		{Content: "Electricity prices on 2025-03-11", RelevanceStart: &tomorrow, RelevanceEnd: &tomorrow, Source: "electricity:FI"},
+		{Content: "Weather 2025-03-10: sunny", RelevanceStart: &today, RelevanceEnd: &today, Source: "weather-metno:Helsinki"},
+		{Content: "Buy milk", RelevanceStart: &tomorrow, RelevanceEnd: &tomorrow, Source: "manual"},
+		{Content: "Alice is allergic to nuts", Source: "manual"},
+	}
+	addMemoriesToDays(bc, memories)
+
+	if len(bc.Days[0].Meals) != 1 {
+		t.Errorf("expected one meal today, got %v", bc.Days[0].Meals)
+	}
+	if len(bc.Days[0].Notes) != 0 {
+		t.Errorf("expected weather memories to be skipped, got notes %v", bc.Days[0].Notes)
+	}
+	if bc.Days[1].Prices != "Electricity prices on 2025-03-11" {
+		t.Errorf("unexpected prices: %q", bc.Days[1].Prices)
+	}
+	if len(bc.Days[1].Notes) != 1 || bc.Days[1].Notes[0] != "Buy milk [Source: manual]" {
+		t.Errorf("unexpected notes for tomorrow: %v", bc.Days[1].Notes)
+	}
+	if len(bc.Notes) != 1 {
+		t.Errorf("expected one undated note, got %v", bc.Notes)
+	}
+}
+
+func TestAddMemoriesToDaysRange(t *testing.T) {
+	now := time.Date(2025, 10, 13, 7, 30, 0, 0, time.UTC)
+	bc := newBriefContext(now, 2, "UTC", "", "English")
+
+	start := time.Date(2025, 10, 12, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 10, 14, 0, 0, 0, 0, time.UTC)
+	addMemoriesToDays(bc, []store.Memory{
+		{Content: "Grandma visits", RelevanceStart: &start, RelevanceEnd: &end, Source: "manual"},
+	})
+
+	note := "Grandma visits (2025-10-12 to 2025-10-14) [Source: manual]"
+	for i, expected := range []int{1, 1, 0} {
+		if len(bc.Days[i].Notes) != expected {
+			t.Fatalf("expected %d notes on %s, got %v", expected, bc.Days[i].Date.Format("2006-01-02"), bc.Days[i].Notes)
+		}
+		if expected == 1 && bc.Days[i].Notes[0] != note {
+			t.Errorf("unexpected note on %s: %q", bc.Days[i].Date.Format("2006-01-02"), bc.Days[i].Notes[0])
+		}
+	}
+}
+
+func TestTrimWeatherPrefix(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Weather 2025-03-10: sunny, temperature 1-5°C", "sunny, temperature 1-5°C"},
+		{"sunny", "sunny"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := trimWeatherPrefix(test.input); got != test.expected {
+			t.Errorf("trimWeatherPrefix(%q) = %q, expected %q", test.input, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package brief
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+const (
+	// queryLookbackYears is how far back memories and events are considered for a query
+	queryLookbackYears = 1
+	// queryLookaheadMonths is how far ahead memories and events are considered for a query
+	queryLookaheadMonths = 1
+	// toolQueryWindowDays is how far back and ahead the query context reaches when the
+	// model can look up the rest with tools
+	toolQueryWindowDays = 7
+)
+
+// GenerateResponse generates a response to a user query from the stored memories and
+// calendar events around the current date in the configured timezone
+func (g *Generator) GenerateResponse(ctx context.Context, query string) (string, error) {
+	notes, err := g.BuildQueryContext(ctx, query)
+	if err != nil {
+		return "", err
+	}
+
+	// Get output language from config, default to Finnish if not specified
+	outputLanguage := g.cfg.OutputLanguage
+	if outputLanguage == "" {
+		outputLanguage = "Finnish"
+	}
+
+	// Generate the response
+	var response string
+	if g.toolbox != nil {
+		response, err = g.toolbox.GenerateResponse(ctx, g.llm, query, notes, outputLanguage)
+	} else {
+		response, err = g.llm.GenerateResponse(ctx, query, notes, outputLanguage)
+	}
+	g.settleCache(err == nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate response: %w", err)
+	}
+
+	return response, nil
+}
+
+// BuildQueryContext collects the information given to the LLM for answering a query: the
+// current date and time, followed by the calendar events and memories in the query window.
+// With a retriever, only the undated memories most similar to the query are included.
+func (g *Generator) BuildQueryContext(ctx context.Context, query string) ([]string, error) {
+	loc, err := time.LoadLocation(g.cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load timezone: %w", err)
+	}
+
+	now := time.Now().In(loc)
+	startDate := now.AddDate(-queryLookbackYears, 0, 0)
+	endDate := now.AddDate(0, queryLookaheadMonths, 0)
+	if g.toolbox != nil {
+		startDate = now.AddDate(0, 0, -toolQueryWindowDays)
+		endDate = now.AddDate(0, 0, toolQueryWindowDays)
+	}
+
+	events, err := g.store.GetRelevantCalendarEvents(startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar events: %w", err)
+	}
+
+	memories, err := g.store.GetRelevantMemories(startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memories: %w", err)
+	}
+
+	if g.retriever != nil {
+		selected, err := g.retriever.SelectMemories(ctx, query, memories)
+		if err != nil {
+			// Too many notes is better than none
+			slog.Warn("Failed to select memories by similarity, including all", "error", err)
+		} else {
+			memories = selected
+		}
+	}
+
+	return formatQueryNotes(now, g.trust.Events(events), g.trust.Memories(memories)), nil
+}
+
+// formatQueryNotes formats the current time, calendar events and memories as the notes
+// for a query. Dates are included since a query isn't tied to a single day.
+func formatQueryNotes(now time.Time, events []store.CalendarEvent, memories []store.Memory) []string {
+	loc := now.Location()
+	notes := []string{
+		fmt.Sprintf("Current date and time: %s (%s)", now.Format("Monday, 2 January 2006 15:04"), loc),
+	}
+
+	for _, event := range events {
+		notes = append(notes, formatQueryEvent(toBriefEvent(event, loc)))
+	}
+
+	for _, memory := range memories {
+		notes = append(notes, formatQueryMemory(memory))
+	}
+
+	return notes
+}
+
+// formatQueryMemory formats a memory with its relevance date or range as a single line.
+// Relevance dates are calendar dates, so they're formatted as stored rather than in the
+// configured timezone.
+func formatQueryMemory(memory store.Memory) string {
+	var dateInfo string
+	if memory.RelevanceStart != nil {
+		start := memory.RelevanceStart.Format("2006-01-02")
+		end := memory.RelevanceEnd.Format("2006-01-02")
+		if start == end {
+			dateInfo = fmt.Sprintf(" (relevant on %s)", start)
+		} else {
+			dateInfo = fmt.Sprintf(" (relevant from %s to %s)", start, end)
+		}
+	}
+	return fmt.Sprintf("%s%s [Source: %s]", memory.Content, dateInfo, memory.Source)
+}
+
+// formatQueryEvent formats a calendar event with its date as a single line
+func formatQueryEvent(event llm.BriefEvent) string {
+	line := fmt.Sprintf("Calendar Event: %s on %s", event.Summary, event.Start.Format("Monday 2006-01-02"))
+
+	switch {
+	case event.AllDay():
+		line += " (all day)"
+	case event.End != nil:
+		endFormat := "15:04"
+		if event.Start.Format("2006-01-02") != event.End.Format("2006-01-02") {
+			endFormat = "Monday 2006-01-02 15:04"
+		}
+		line += fmt.Sprintf(" from %s to %s", event.Start.Format("15:04"), event.End.Format(endFormat))
+	default:
+		line += fmt.Sprintf(" at %s", event.Start.Format("15:04"))
+	}
+
+	if event.Location != "" {
+		line += fmt.Sprintf(" at %s", event.Location)
+	}
+
+	if event.Source != "" {
+		line += fmt.Sprintf(" [Source: %s]", event.Source)
+	}
+
+	return line
+}
@@ -0,0 +1,97 @@
+package brief
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+func TestFormatQueryNotes(t *testing.T) {
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+
+	now := time.Date(2025, 4, 18, 7, 30, 0, 0, helsinki)
+	location := "Clinic"
+	start := time.Date(2025, 4, 22, 6, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	// Relevance dates are calendar dates, stored as UTC midnight
+	relevance := time.Date(2025, 4, 19, 0, 0, 0, 0, time.UTC)
+	relevanceEnd := relevance.AddDate(0, 0, 2)
+
+	notes := formatQueryNotes(now,
+		[]store.CalendarEvent{
+			{Summary: "Dentist (Pekka)", StartTime: start, EndTime: &end, Location: &location, Source: "calendar:Family"},
+		},
+		[]store.Memory{
+			{Content: "Return library books", RelevanceStart: &relevance, RelevanceEnd: &relevance, Source: "manual"},
+			{Content: "Grandma visits", RelevanceStart: &relevance, RelevanceEnd: &relevanceEnd, Source: "manual"},
+			{Content: "Bob is allergic to nuts", Source: "manual"},
+		},
+	)
+
+	expected := []string{
+		"Current date and time: Friday, 18 April 2025 07:30 (Europe/Helsinki)",
+		"Calendar Event: Dentist (Pekka) on Tuesday 2025-04-22 from 09:00 to 10:00 at Clinic [Source: calendar:Family]",
+		"Return library books (relevant on 2025-04-19) [Source: manual]",
+		"Grandma visits (relevant from 2025-04-19 to 2025-04-21) [Source: manual]",
+		"Bob is allergic to nuts [Source: manual]",
+	}
+
+	if len(notes) != len(expected) {
+		t.Fatalf("formatQueryNotes() returned %d notes, expected %d: %q", len(notes), len(expected), notes)
+	}
+	for i := range expected {
+		if notes[i] != expected[i] {
+			t.Errorf("note %d = %q, expected %q", i, notes[i], expected[i])
+		}
+	}
+}
+
+func TestFormatQueryNotesNegativeOffset(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+
+	now := time.Date(2025, 4, 18, 20, 0, 0, 0, newYork)
+	start := time.Date(2025, 4, 19, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 4, 21, 0, 0, 0, 0, time.UTC)
+
+	notes := formatQueryNotes(now, nil, []store.Memory{
+		{Content: "Grandma visits", RelevanceStart: &start, RelevanceEnd: &end, Source: "manual"},
+	})
+
+	// The dates aren't shifted to the previous day west of UTC
+	expected := "Grandma visits (relevant from 2025-04-19 to 2025-04-21) [Source: manual]"
+	if len(notes) != 2 || notes[1] != expected {
+		t.Errorf("formatQueryNotes() = %q, expected the memory as %q", notes, expected)
+	}
+}
+
+func TestFormatQueryEvent(t *testing.T) {
+	start := time.Date(2025, 4, 22, 0, 0, 0, 0, time.UTC)
+	allDayEnd := start.AddDate(0, 0, 1)
+	multiDayEnd := start.AddDate(0, 0, 2).Add(12 * time.Hour)
+
+	tests := []struct {
+		name     string
+		event    llm.BriefEvent
+		expected string
+	}{
+		{"all day", llm.BriefEvent{Summary: "Holiday", Start: start, End: &allDayEnd}, "Calendar Event: Holiday on Tuesday 2025-04-22 (all day)"},
+		{"no end time", llm.BriefEvent{Summary: "Call", Start: start.Add(14 * time.Hour)}, "Calendar Event: Call on Tuesday 2025-04-22 at 14:00"},
+		{"multiple days", llm.BriefEvent{Summary: "Trip", Start: start.Add(8 * time.Hour), End: &multiDayEnd}, "Calendar Event: Trip on Tuesday 2025-04-22 from 08:00 to Thursday 2025-04-24 12:00"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := formatQueryEvent(test.event); got != test.expected {
+				t.Errorf("formatQueryEvent() = %q, expected %q", got, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package brief
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// EmbeddingStore is the storage used for memory embeddings
+type EmbeddingStore interface {
+	GetMemoryEmbeddings(model string) (map[int64]store.MemoryEmbedding, error)
+	SetMemoryEmbedding(embedding store.MemoryEmbedding) error
+}
+
+// Retriever narrows down the undated memories given to the LLM to the ones most similar
+// to the query. Memories with a relevance date are already limited by the date window.
+type Retriever struct {
+	store    EmbeddingStore
+	embedder llm.Embedder
+	topK     int
+}
+
+// NewRetriever creates a retriever keeping the topK most similar undated memories
+func NewRetriever(store EmbeddingStore, embedder llm.Embedder, topK int) *Retriever {
+	return &Retriever{
+		store:    store,
+		embedder: embedder,
+		topK:     topK,
+	}
+}
+
+// SelectMemories returns the dated memories as is, followed by the topK undated memories
+// most similar to the query, most similar first. Memories without a stored embedding, or
+// edited since it was stored, are embedded first.
+func (r *Retriever) SelectMemories(ctx context.Context, query string, memories []store.Memory) ([]store.Memory, error) {
+	var selected, undated []store.Memory
+	for _, memory := range memories {
+		if memory.RelevanceStart == nil {
+			undated = append(undated, memory)
+		} else {
+			selected = append(selected, memory)
+		}
+	}
+
+	if len(undated) <= r.topK {
+		return memories, nil
+	}
+
+	vectors, err := r.memoryVectors(ctx, undated)
+	if err != nil {
+		return nil, err
+	}
+
+	queryVectors, err := r.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	type scoredMemory struct {
+		memory store.Memory
+		score  float64
+	}
+	scored := make([]scoredMemory, len(undated))
+	for i, memory := range undated {
+		scored[i] = scoredMemory{memory: memory, score: llm.CosineSimilarity(queryVectors[0], vectors[memory.ID])}
+	}
+	slices.SortStableFunc(scored, func(a, b scoredMemory) int {
+		return cmp.Compare(b.score, a.score)
+	})
+
+	for _, s := range scored[:r.topK] {
+		selected = append(selected, s.memory)
+	}
+
+	slog.Debug("Selected memories by similarity", "undated", len(undated), "kept", r.topK)
+	return selected, nil
+}
+
+// memoryVectors returns the embedding vectors of the memories by memory ID, embedding and
+// storing the ones that are missing or out of date
+func (r *Retriever) memoryVectors(ctx context.Context, memories []store.Memory) (map[int64][]float32, error) {
+	model := r.embedder.Name() + ":" + r.embedder.Model()
+
+	stored, err := r.store.GetMemoryEmbeddings(model)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make(map[int64][]float32, len(memories))
+	var missing []store.Memory
+	var texts []string
+	for _, memory := range memories {
+		embedding, ok := stored[memory.ID]
+		if ok && embedding.ContentHash == llm.PromptHash(memory.Content) {
+			vectors[memory.ID] = embedding.Vector
+			continue
+		}
+		missing = append(missing, memory)
+		texts = append(texts, memory.Content)
+	}
+
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	embedded, err := r.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed memories: %w", err)
+	}
+
+	for i, memory := range missing {
+		vectors[memory.ID] = embedded[i]
+		err := r.store.SetMemoryEmbedding(store.MemoryEmbedding{
+			MemoryID:    memory.ID,
+			Model:       model,
+			ContentHash: llm.PromptHash(memory.Content),
+			Vector:      embedded[i],
+		})
+		if err != nil {
+			// The vector is still used for this query, it's just embedded again next time
+			slog.Warn("Failed to store memory embedding", "memory_id", memory.ID, "error", err)
+		}
+	}
+
+	slog.Debug("Embedded memories", "count", len(missing), "model", model)
+	return vectors, nil
+}
+
+// briefQuery describes the brief for memory retrieval: the dates and the calendar events
+// filled in so far
+func briefQuery(bc *llm.BriefContext) string {
+	var builder strings.Builder
+	builder.WriteString("Daily brief")
+	for _, event := range bc.Ongoing {
+		fmt.Fprintf(&builder, "\n%s", event.Summary)
+	}
+	for _, day := range bc.Days {
+		fmt.Fprintf(&builder, "\n%s", day.Date.Format("Monday 2006-01-02"))
+		for _, event := range day.Events {
+			fmt.Fprintf(&builder, "\n%s", event.Summary)
+		}
+	}
+	return builder.String()
+}
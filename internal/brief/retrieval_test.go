@@ -0,0 +1,106 @@
+package brief
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// fakeEmbeddingStore keeps memory embeddings in memory
+type fakeEmbeddingStore struct {
+	embeddings map[int64]store.MemoryEmbedding
+}
+
+func (f *fakeEmbeddingStore) GetMemoryEmbeddings(model string) (map[int64]store.MemoryEmbedding, error) {
+	embeddings := make(map[int64]store.MemoryEmbedding)
+	for id, embedding := range f.embeddings {
+		if embedding.Model == model {
+			embeddings[id] = embedding
+		}
+	}
+	return embeddings, nil
+}
+
+func (f *fakeEmbeddingStore) SetMemoryEmbedding(embedding store.MemoryEmbedding) error {
+	if f.embeddings == nil {
+		f.embeddings = make(map[int64]store.MemoryEmbedding)
+	}
+	f.embeddings[embedding.MemoryID] = embedding
+	return nil
+}
+
+func TestRetrieverSelectMemories(t *testing.T) {
+	date := time.Date(2025, 4, 18, 0, 0, 0, 0, time.UTC)
+	memories := []store.Memory{
+		{ID: 1, Content: "Return library books", RelevanceStart: &date, RelevanceEnd: &date},
+		{ID: 2, Content: "The car needs new winter tyres"},
+		{ID: 3, Content: "Bob is allergic to nuts"},
+		{ID: 4, Content: "The wifi password is on the fridge"},
+		{ID: 5, Content: "Maija takes the bus to school"},
+	}
+
+	embeddingStore := &fakeEmbeddingStore{}
+	embedder := &llm.FakeEmbedder{}
+	retriever := NewRetriever(embeddingStore, embedder, 2)
+
+	selected, err := retriever.SelectMemories(context.Background(), "Is Bob allergic to nuts?", memories)
+	if err != nil {
+		t.Fatalf("SelectMemories() error = %v", err)
+	}
+
+	// The dated memory is always kept, followed by the two most similar undated ones
+	if len(selected) != 3 {
+		t.Fatalf("SelectMemories() returned %d memories, expected 3: %+v", len(selected), selected)
+	}
+	if selected[0].ID != 1 || selected[1].ID != 3 {
+		t.Errorf("SelectMemories() = %+v, expected the dated memory and then the nut allergy", selected)
+	}
+
+	// Four memories and the query were embedded, and the memory vectors were stored
+	if embedder.Calls != 5 || len(embeddingStore.embeddings) != 4 {
+		t.Errorf("expected 5 embedded texts and 4 stored embeddings, got %d and %d", embedder.Calls, len(embeddingStore.embeddings))
+	}
+
+	// Stored embeddings are reused, an edited memory is embedded again
+	memories[2].Content = "Bob is allergic to peanuts"
+	if _, err := retriever.SelectMemories(context.Background(), "nuts", memories); err != nil {
+		t.Fatalf("SelectMemories() error = %v", err)
+	}
+	if embedder.Calls != 7 {
+		t.Errorf("expected only the edited memory and the query to be embedded, total calls %d", embedder.Calls)
+	}
+}
+
+func TestRetrieverFewMemories(t *testing.T) {
+	memories := []store.Memory{
+		{ID: 1, Content: "Bob is allergic to nuts"},
+		{ID: 2, Content: "The car needs new winter tyres"},
+	}
+
+	embedder := &llm.FakeEmbedder{Err: errors.New("should not be called")}
+	retriever := NewRetriever(&fakeEmbeddingStore{}, embedder, 2)
+
+	selected, err := retriever.SelectMemories(context.Background(), "anything", memories)
+	if err != nil {
+		t.Fatalf("SelectMemories() error = %v", err)
+	}
+	if len(selected) != 2 {
+		t.Errorf("expected all memories when there are no more than top-k, got %d", len(selected))
+	}
+}
+
+func TestBriefQuery(t *testing.T) {
+	now := time.Date(2025, 4, 18, 7, 0, 0, 0, time.UTC)
+	bc := newBriefContext(now, 1, "UTC", "Helsinki", "English")
+	bc.Ongoing = []llm.BriefEvent{{Summary: "Easter holiday"}}
+	bc.Days[1].Events = []llm.BriefEvent{{Summary: "Dentist"}}
+
+	expected := "Daily brief\nEaster holiday\nFriday 2025-04-18\nSaturday 2025-04-19\nDentist"
+	if got := briefQuery(bc); got != expected {
+		t.Errorf("briefQuery() = %q, expected %q", got, expected)
+	}
+}
@@ -0,0 +1,271 @@
+package brief
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/importer/electricityprice"
+	weatherimporter "github.com/lepinkainen/hovimestari/internal/importer/weather"
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// maxToolRangeDays is the longest date range a single tool call can ask for
+const maxToolRangeDays = 366
+
+// Tools returns the tools the model can call to look up stored information
+func (g *Generator) Tools() []llm.Tool {
+	return []llm.Tool{
+		{
+			Name:        "get_events",
+			Description: "List the calendar events in a date range",
+			Parameters: []llm.ToolParameter{
+				{Name: "date_range", Description: "A date as YYYY-MM-DD, or a range as YYYY-MM-DD..YYYY-MM-DD", Required: true},
+				{Name: "person", Description: "Only events mentioning this family member"},
+			},
+			Call: g.getEvents,
+		},
+		{
+			Name:        "get_memories",
+			Description: "List stored memories and notes, either the ones relevant on a date or the ones without a date",
+			Parameters: []llm.ToolParameter{
+				{Name: "source", Description: "Only memories from this source, e.g. manual or schoollunch"},
+				{Name: "date", Description: "Date as YYYY-MM-DD; without it, the memories that aren't tied to a date are listed"},
+			},
+			Call: g.getMemories,
+		},
+		{
+			Name:        "search",
+			Description: "Search all stored memories and calendar events, including past years, for keywords",
+			Parameters: []llm.ToolParameter{
+				{Name: "query", Description: "Keywords that must all appear, e.g. dentist Aino", Required: true},
+				{Name: "source", Description: "Only results from this source, e.g. manual or calendar"},
+			},
+			Call: g.search,
+		},
+		{
+			Name:        "get_weather",
+			Description: "Get the stored weather forecast for a day",
+			Parameters: []llm.ToolParameter{
+				{Name: "date", Description: "Date as YYYY-MM-DD", Required: true},
+			},
+			Call: g.getWeather,
+		},
+		{
+			Name:        "get_prices",
+			Description: "Get the electricity prices for a day",
+			Parameters: []llm.ToolParameter{
+				{Name: "date", Description: "Date as YYYY-MM-DD", Required: true},
+			},
+			Call: g.getPrices,
+		},
+	}
+}
+
+// getEvents implements the get_events tool
+func (g *Generator) getEvents(ctx context.Context, args map[string]string) (string, error) {
+	loc, err := time.LoadLocation(g.cfg.Timezone)
+	if err != nil {
+		return "", fmt.Errorf("failed to load timezone: %w", err)
+	}
+
+	start, end, err := parseDateRange(args["date_range"], loc)
+	if err != nil {
+		return "", err
+	}
+
+	events, err := g.store.GetRelevantCalendarEvents(start, end)
+	if err != nil {
+		return "", fmt.Errorf("failed to get calendar events: %w", err)
+	}
+
+	return formatEventsResult(g.trust.Events(events), args["person"], loc), nil
+}
+
+// formatEventsResult formats calendar events as the result of get_events, keeping only
+// the ones mentioning the person if one is given
+func formatEventsResult(events []store.CalendarEvent, person string, loc *time.Location) string {
+	person = strings.ToLower(strings.TrimSpace(person))
+
+	var lines []string
+	for _, event := range events {
+		if person != "" && !mentions(event, person) {
+			continue
+		}
+		lines = append(lines, formatQueryEvent(toBriefEvent(event, loc)))
+	}
+
+	if len(lines) == 0 {
+		return "No events found."
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mentions reports whether the event's summary or description mentions the lowercase name
+func mentions(event store.CalendarEvent, name string) bool {
+	if strings.Contains(strings.ToLower(event.Summary), name) {
+		return true
+	}
+	return event.Description != nil && strings.Contains(strings.ToLower(*event.Description), name)
+}
+
+// getMemories implements the get_memories tool
+func (g *Generator) getMemories(ctx context.Context, args map[string]string) (string, error) {
+	loc, err := time.LoadLocation(g.cfg.Timezone)
+	if err != nil {
+		return "", fmt.Errorf("failed to load timezone: %w", err)
+	}
+
+	// Undated memories are returned for any range, so a single instant is enough for them
+	start := time.Now().In(loc)
+	end := start
+	if date := args["date"]; date != "" {
+		start, end, err = parseDateRange(date, loc)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	memories, err := g.store.GetRelevantMemories(start, end)
+	if err != nil {
+		return "", fmt.Errorf("failed to get memories: %w", err)
+	}
+
+	return formatMemoriesResult(g.trust.Memories(memories), args["source"], args["date"]), nil
+}
+
+// formatMemoriesResult formats memories as the result of get_memories: the ones relevant
+// on the date, or the undated ones if no date is given
+func formatMemoriesResult(memories []store.Memory, source, date string) string {
+	var lines []string
+	for _, memory := range memories {
+		if source != "" && memory.Source != source && !strings.HasPrefix(memory.Source, source+":") {
+			continue
+		}
+		if (date == "") != (memory.RelevanceStart == nil) {
+			continue
+		}
+		lines = append(lines, formatQueryMemory(memory))
+	}
+
+	if len(lines) == 0 {
+		return "No memories found."
+	}
+	return strings.Join(lines, "\n")
+}
+
+// search implements the search tool
+func (g *Generator) search(ctx context.Context, args map[string]string) (string, error) {
+	loc, err := time.LoadLocation(g.cfg.Timezone)
+	if err != nil {
+		return "", fmt.Errorf("failed to load timezone: %w", err)
+	}
+
+	results, err := g.store.Search(args["query"], store.SearchFilter{Source: args["source"]})
+	if err != nil {
+		return "", fmt.Errorf("failed to search: %w", err)
+	}
+
+	return formatSearchResults(g.trust.SearchResults(results), loc), nil
+}
+
+// formatSearchResults formats search results as the result of the search tool, best
+// matches first. Event times are shown in the location, memory relevance dates as the
+// calendar dates they are.
+func formatSearchResults(results []store.SearchResult, loc *time.Location) string {
+	if len(results) == 0 {
+		return "No results found."
+	}
+
+	lines := make([]string, len(results))
+	for i, result := range results {
+		snippet := strings.Join(strings.Fields(strings.ReplaceAll(result.Snippet, store.HighlightStart, "")), " ")
+
+		var line string
+		switch {
+		case result.Kind == store.SearchKindEvent:
+			line = fmt.Sprintf("Calendar Event: %s on %s", result.Title, result.Date.In(loc).Format("Monday 2006-01-02 15:04"))
+		case result.Date != nil && result.EndDate != nil && result.Date.Format("2006-01-02") != result.EndDate.Format("2006-01-02"):
+			line = fmt.Sprintf("Memory (relevant from %s to %s)", result.Date.Format("2006-01-02"), result.EndDate.Format("2006-01-02"))
+		case result.Date != nil:
+			line = fmt.Sprintf("Memory (relevant on %s)", result.Date.Format("2006-01-02"))
+		default:
+			line = "Memory"
+		}
+		lines[i] = fmt.Sprintf("%s: %s [Source: %s]", line, snippet, result.Source)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// getWeather implements the get_weather tool
+func (g *Generator) getWeather(ctx context.Context, args map[string]string) (string, error) {
+	date, _, err := parseDateRange(args["date"], time.UTC)
+	if err != nil {
+		return "", err
+	}
+
+	forecasts, err := weatherimporter.GetLatestForecasts(g.store, date, date, g.cfg.LocationName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get weather forecasts: %w", err)
+	}
+
+	forecast, ok := forecasts[date.Format("2006-01-02")]
+	if !ok {
+		return fmt.Sprintf("No weather forecast for %s.", date.Format("2006-01-02")), nil
+	}
+	return trimWeatherPrefix(forecast), nil
+}
+
+// getPrices implements the get_prices tool
+func (g *Generator) getPrices(ctx context.Context, args map[string]string) (string, error) {
+	loc, err := time.LoadLocation(g.cfg.Timezone)
+	if err != nil {
+		return "", fmt.Errorf("failed to load timezone: %w", err)
+	}
+
+	start, end, err := parseDateRange(args["date"], loc)
+	if err != nil {
+		return "", err
+	}
+
+	memories, err := g.store.GetRelevantMemories(start, end)
+	if err != nil {
+		return "", fmt.Errorf("failed to get memories: %w", err)
+	}
+
+	for _, memory := range g.trust.Memories(memories) {
+		if memory.RelevanceStart != nil && strings.HasPrefix(memory.Source, electricityprice.SourcePrefix+":") {
+			return memory.Content, nil
+		}
+	}
+	return fmt.Sprintf("No electricity prices for %s.", start.Format("2006-01-02")), nil
+}
+
+// parseDateRange parses a date or a YYYY-MM-DD..YYYY-MM-DD range in the location,
+// returning the start of the first day and the end of the last day
+func parseDateRange(value string, loc *time.Location) (time.Time, time.Time, error) {
+	first, last, isRange := strings.Cut(strings.TrimSpace(value), "..")
+	if !isRange {
+		last = first
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(first), loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", first)
+	}
+	end, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(last), loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", last)
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("date range %q ends before it starts", value)
+	}
+	if end.Sub(start) > maxToolRangeDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("date range %q is longer than %d days", value, maxToolRangeDays)
+	}
+
+	return start, end.AddDate(0, 0, 1).Add(-time.Second), nil
+}
@@ -0,0 +1,133 @@
+package brief
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+func TestParseDateRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		wantStart string
+		wantEnd   string
+		wantErr   bool
+	}{
+		{name: "single date", value: "2025-04-18", wantStart: "2025-04-18 00:00:00", wantEnd: "2025-04-18 23:59:59"},
+		{name: "range", value: "2025-04-18..2025-04-20", wantStart: "2025-04-18 00:00:00", wantEnd: "2025-04-20 23:59:59"},
+		{name: "spaces", value: " 2025-04-18 .. 2025-04-19 ", wantStart: "2025-04-18 00:00:00", wantEnd: "2025-04-19 23:59:59"},
+		{name: "invalid date", value: "tomorrow", wantErr: true},
+		{name: "reversed", value: "2025-04-20..2025-04-18", wantErr: true},
+		{name: "too long", value: "2025-01-01..2027-01-01", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end, err := parseDateRange(test.value, time.UTC)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("parseDateRange(%q) expected an error", test.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDateRange(%q) error = %v", test.value, err)
+			}
+			if start.Format(time.DateTime) != test.wantStart || end.Format(time.DateTime) != test.wantEnd {
+				t.Errorf("parseDateRange(%q) = %s, %s, expected %s, %s", test.value, start.Format(time.DateTime), end.Format(time.DateTime), test.wantStart, test.wantEnd)
+			}
+		})
+	}
+}
+
+func TestFormatEventsResult(t *testing.T) {
+	start := time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC)
+	description := "Bring Pekka's vaccination card"
+	events := []store.CalendarEvent{
+		{Summary: "Dentist (Maija)", StartTime: start, Source: "calendar:Family"},
+		{Summary: "Health check", StartTime: start.Add(2 * time.Hour), Description: &description, Source: "calendar:Family"},
+	}
+
+	got := formatEventsResult(events, "pekka", time.UTC)
+	if strings.Contains(got, "Dentist") || !strings.Contains(got, "Health check") {
+		t.Errorf("expected only the event mentioning Pekka, got:\n%s", got)
+	}
+
+	if got := formatEventsResult(events, "", time.UTC); strings.Count(got, "\n") != 1 {
+		t.Errorf("expected both events without a person, got:\n%s", got)
+	}
+
+	if got := formatEventsResult(events, "Bob", time.UTC); got != "No events found." {
+		t.Errorf("formatEventsResult() = %q, expected no events", got)
+	}
+}
+
+func TestFormatMemoriesResult(t *testing.T) {
+	date := time.Date(2025, 4, 22, 0, 0, 0, 0, time.UTC)
+	memories := []store.Memory{
+		{Content: "Fish soup", RelevanceStart: &date, RelevanceEnd: &date, Source: "schoollunch:Kilo"},
+		{Content: "Return library books", RelevanceStart: &date, RelevanceEnd: &date, Source: "manual"},
+		{Content: "Bob is allergic to nuts", Source: "manual"},
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		date     string
+		expected string
+	}{
+		{"undated", "", "", "Bob is allergic to nuts [Source: manual]"},
+		{"dated", "", "2025-04-22", "Fish soup (relevant on 2025-04-22) [Source: schoollunch:Kilo]\nReturn library books (relevant on 2025-04-22) [Source: manual]"},
+		{"source prefix", "schoollunch", "2025-04-22", "Fish soup (relevant on 2025-04-22) [Source: schoollunch:Kilo]"},
+		{"nothing found", "calendar", "", "No memories found."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := formatMemoriesResult(memories, test.source, test.date); got != test.expected {
+				t.Errorf("formatMemoriesResult() = %q, expected %q", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestFormatSearchResults(t *testing.T) {
+	start := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	date := time.Date(2023, 11, 14, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 11, 18, 0, 0, 0, 0, time.UTC)
+	results := []store.SearchResult{
+		{Kind: store.SearchKindEvent, Date: &start, Title: "Dentist", Snippet: "**Dentist** check-up", Source: "calendar:Family"},
+		{Kind: store.SearchKindMemory, Date: &date, Snippet: "Aino went to the **dentist**,\n no cavities", Source: "manual"},
+		{Kind: store.SearchKindMemory, Snippet: "**Dentist** phone 09 123", Source: "manual"},
+		{Kind: store.SearchKindMemory, Date: &date, EndDate: &end, Snippet: "Grandma visits, **dentist** on Friday", Source: "manual"},
+	}
+
+	expected := "Calendar Event: Dentist on Thursday 2024-05-02 10:00: Dentist check-up [Source: calendar:Family]\n" +
+		"Memory (relevant on 2023-11-14): Aino went to the dentist, no cavities [Source: manual]\n" +
+		"Memory: Dentist phone 09 123 [Source: manual]\n" +
+		"Memory (relevant from 2023-11-14 to 2023-11-18): Grandma visits, dentist on Friday [Source: manual]"
+	if got := formatSearchResults(results, time.UTC); got != expected {
+		t.Errorf("formatSearchResults() = %q, expected %q", got, expected)
+	}
+
+	if got := formatSearchResults(nil, time.UTC); got != "No results found." {
+		t.Errorf("formatSearchResults(nil) = %q, expected %q", got, "No results found.")
+	}
+
+	// West of UTC, event times are shown in local time but memory dates aren't shifted
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+	expected = "Calendar Event: Dentist on Thursday 2024-05-02 06:00: Dentist check-up [Source: calendar:Family]\n" +
+		"Memory (relevant on 2023-11-14): Aino went to the dentist, no cavities [Source: manual]\n" +
+		"Memory: Dentist phone 09 123 [Source: manual]\n" +
+		"Memory (relevant from 2023-11-14 to 2023-11-18): Grandma visits, dentist on Friday [Source: manual]"
+	if got := formatSearchResults(results, newYork); got != expected {
+		t.Errorf("formatSearchResults() in New York = %q, expected %q", got, expected)
+	}
+}
@@ -0,0 +1,234 @@
+package brief
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+)
+
+const (
+	// trimmedDescriptionLength is the length event descriptions are shortened to before
+	// anything is dropped from an over-budget context
+	trimmedDescriptionLength = 200
+	// dayPenalty lowers the value of an item for each day further away from today
+	dayPenalty = 15
+	// manualNoteBonus raises the value of notes added by hand over imported ones
+	manualNoteBonus = 10
+)
+
+// Kinds of brief context items, for ranking and logging
+const (
+	itemEvent       = "event"
+	itemOngoing     = "ongoing"
+	itemNote        = "note"
+	itemMeal        = "meal"
+	itemPrices      = "prices"
+	itemHourly      = "hourly_forecast"
+	itemUndatedNote = "undated_note"
+)
+
+// itemValues are the base values of the item kinds, higher is more important. The weather,
+// family and birthdays are never dropped.
+var itemValues = map[string]int{
+	itemEvent:       100,
+	itemOngoing:     90,
+	itemNote:        70,
+	itemMeal:        50,
+	itemPrices:      40,
+	itemHourly:      30,
+	itemUndatedNote: 20,
+}
+
+// contextItem is a part of the brief context that can be dropped to fit the token budget
+type contextItem struct {
+	kind   string
+	day    int // Index of the day, -1 for items not tied to a day
+	index  int // Index within the day's or the context's list
+	text   string
+	value  int
+	tokens int
+}
+
+// itemKey identifies a context item
+type itemKey struct {
+	kind  string
+	day   int
+	index int
+}
+
+// droppedItem describes an item left out of the brief context to fit the token budget
+type droppedItem struct {
+	kind string
+	text string
+}
+
+// trimBriefContext makes the brief context fit the token budget. Event descriptions are
+// shortened first; if that isn't enough, the lowest-value items are dropped until the
+// estimate fits. The dropped items are returned.
+func trimBriefContext(bc *llm.BriefContext, budget int) []droppedItem {
+	if llm.EstimateBriefContextTokens(bc) <= budget {
+		return nil
+	}
+
+	shortenDescriptions(bc)
+	estimate := llm.EstimateBriefContextTokens(bc)
+	if estimate <= budget {
+		slog.Debug("Shortened event descriptions to fit the token budget", "budget", budget, "estimated_tokens", estimate)
+		return nil
+	}
+
+	items := contextItems(bc)
+	slices.SortStableFunc(items, func(a, b contextItem) int {
+		return cmp.Compare(a.value, b.value)
+	})
+
+	dropped := make(map[itemKey]bool)
+	var droppedItems []droppedItem
+	for _, item := range items {
+		dropped[itemKey{item.kind, item.day, item.index}] = true
+		droppedItems = append(droppedItems, droppedItem{kind: item.kind, text: item.text})
+
+		// The item estimates are rough, so check the real estimate once they say it fits
+		estimate -= item.tokens
+		if estimate <= budget {
+			estimate = llm.EstimateBriefContextTokens(withoutItems(bc, dropped))
+			if estimate <= budget {
+				break
+			}
+		}
+	}
+
+	*bc = *withoutItems(bc, dropped)
+	return droppedItems
+}
+
+// shortenDescriptions cuts long event descriptions to trimmedDescriptionLength characters
+func shortenDescriptions(bc *llm.BriefContext) {
+	for i := range bc.Days {
+		for j := range bc.Days[i].Events {
+			event := &bc.Days[i].Events[j]
+			if runes := []rune(event.Description); len(runes) > trimmedDescriptionLength {
+				event.Description = string(runes[:trimmedDescriptionLength]) + "…"
+			}
+		}
+	}
+}
+
+// contextItems lists the droppable items of the brief context with their values
+func contextItems(bc *llm.BriefContext) []contextItem {
+	var items []contextItem
+	add := func(kind string, day, index int, text string, bonus int) {
+		value := itemValues[kind] + bonus
+		if day > 0 {
+			value -= day * dayPenalty
+		}
+		items = append(items, contextItem{
+			kind:   kind,
+			day:    day,
+			index:  index,
+			text:   text,
+			value:  value,
+			tokens: llm.EstimateTokens(text) + 1, // The list marker and the newline
+		})
+	}
+
+	for i, event := range bc.Ongoing {
+		add(itemOngoing, -1, i, event.Summary, 0)
+	}
+
+	for day, briefDay := range bc.Days {
+		for i, event := range briefDay.Events {
+			add(itemEvent, day, i, eventText(event), 0)
+		}
+		for i, note := range briefDay.Notes {
+			bonus := 0
+			if strings.HasSuffix(note, "[Source: manual]") {
+				bonus = manualNoteBonus
+			}
+			add(itemNote, day, i, note, bonus)
+		}
+		for i, meal := range briefDay.Meals {
+			add(itemMeal, day, i, meal, 0)
+		}
+		if briefDay.Prices != "" {
+			add(itemPrices, day, 0, briefDay.Prices, 0)
+		}
+		if briefDay.HourlyForecast != "" {
+			add(itemHourly, day, 0, briefDay.HourlyForecast, 0)
+		}
+	}
+
+	// Undated notes are in order of relevance, so later ones are worth a little less
+	for i, note := range bc.Notes {
+		add(itemUndatedNote, -1, i, note, -i)
+	}
+
+	return items
+}
+
+// eventText approximates an event as it's formatted for the LLM
+func eventText(event llm.BriefEvent) string {
+	text := fmt.Sprintf("Calendar Event: %s from 00:00 to 00:00", event.Summary)
+	if event.Location != "" {
+		text += " at " + event.Location
+	}
+	if event.Description != "" {
+		text += ". Description: " + event.Description
+	}
+	return text + fmt.Sprintf(" [Source: %s]", event.Source)
+}
+
+// withoutItems returns a copy of the brief context without the dropped items
+func withoutItems(bc *llm.BriefContext, dropped map[itemKey]bool) *llm.BriefContext {
+	trimmed := *bc
+	trimmed.Ongoing = keep(bc.Ongoing, itemOngoing, -1, dropped)
+	trimmed.Notes = keep(bc.Notes, itemUndatedNote, -1, dropped)
+
+	trimmed.Days = make([]llm.BriefDay, len(bc.Days))
+	for day, briefDay := range bc.Days {
+		briefDay.Events = keep(briefDay.Events, itemEvent, day, dropped)
+		briefDay.Notes = keep(briefDay.Notes, itemNote, day, dropped)
+		briefDay.Meals = keep(briefDay.Meals, itemMeal, day, dropped)
+		if dropped[itemKey{itemPrices, day, 0}] {
+			briefDay.Prices = ""
+		}
+		if dropped[itemKey{itemHourly, day, 0}] {
+			briefDay.HourlyForecast = ""
+		}
+		trimmed.Days[day] = briefDay
+	}
+
+	return &trimmed
+}
+
+// keep returns the elements of the list that weren't dropped
+func keep[T any](list []T, kind string, day int, dropped map[itemKey]bool) []T {
+	var kept []T
+	for i, element := range list {
+		if !dropped[itemKey{kind, day, i}] {
+			kept = append(kept, element)
+		}
+	}
+	return kept
+}
+
+// logDroppedItems logs a summary of the items dropped to fit the budget, and each item
+// at debug level
+func logDroppedItems(dropped []droppedItem, budget, estimate int) {
+	if len(dropped) == 0 {
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, item := range dropped {
+		counts[item.kind]++
+		slog.Debug("Dropped from brief context", "kind", item.kind, "item", item.text)
+	}
+
+	slog.Info("Trimmed brief context to fit the token budget",
+		"budget", budget, "estimated_tokens", estimate, "dropped", len(dropped), "by_kind", counts)
+}
@@ -0,0 +1,111 @@
+package brief
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+)
+
+// newTrimTestContext creates a brief context with a mix of items over three days
+func newTrimTestContext() *llm.BriefContext {
+	now := time.Date(2025, 4, 18, 7, 0, 0, 0, time.UTC)
+	bc := newBriefContext(now, 2, "UTC", "Helsinki", "English")
+	bc.Family = []string{"Matti", "Maija"}
+
+	bc.Days[0].Weather = "Sunny, 12°C"
+	bc.Days[0].HourlyForecast = "Hourly forecast: " + strings.Repeat("08:00 10°C, ", 20)
+	bc.Days[0].Events = []llm.BriefEvent{
+		{Summary: "Dentist", Start: now.Add(2 * time.Hour), Source: "calendar:Family", Description: strings.Repeat("Bring the referral. ", 30)},
+	}
+	bc.Days[0].Meals = []string{"Fish soup"}
+	bc.Days[0].Prices = "Electricity: average 4.2 c/kWh, cheapest 02:00-05:00"
+	bc.Days[0].Notes = []string{"Return library books [Source: manual]"}
+	bc.Days[2].Events = []llm.BriefEvent{{Summary: "Football practice", Start: now.AddDate(0, 0, 2), Source: "calendar:Family"}}
+	bc.Days[2].Meals = []string{"Meatballs"}
+	for i := range 20 {
+		bc.Notes = append(bc.Notes, strings.Repeat("Undated note ", 5)+string(rune('A'+i)))
+	}
+
+	return bc
+}
+
+func TestTrimBriefContextWithinBudget(t *testing.T) {
+	bc := newTrimTestContext()
+	before := llm.EstimateBriefContextTokens(bc)
+
+	if dropped := trimBriefContext(bc, before); dropped != nil {
+		t.Errorf("expected nothing dropped within budget, got %v", dropped)
+	}
+	if llm.EstimateBriefContextTokens(bc) != before {
+		t.Error("expected the context to be unchanged within budget")
+	}
+}
+
+func TestTrimBriefContextShortensDescriptions(t *testing.T) {
+	bc := newTrimTestContext()
+	before := llm.EstimateBriefContextTokens(bc)
+
+	// Just under the current size, shortening the description is enough
+	dropped := trimBriefContext(bc, before-10)
+	if len(dropped) != 0 {
+		t.Errorf("expected nothing dropped, got %v", dropped)
+	}
+	if got := len([]rune(bc.Days[0].Events[0].Description)); got != trimmedDescriptionLength+1 {
+		t.Errorf("description length = %d, expected %d", got, trimmedDescriptionLength+1)
+	}
+}
+
+func TestTrimBriefContextDropsLowestValue(t *testing.T) {
+	bc := newTrimTestContext()
+	budget := 250
+
+	dropped := trimBriefContext(bc, budget)
+	if len(dropped) == 0 {
+		t.Fatal("expected items to be dropped")
+	}
+	if got := llm.EstimateBriefContextTokens(bc); got > budget {
+		t.Errorf("estimate %d is still over the budget %d", got, budget)
+	}
+
+	// Undated notes go first, starting from the least relevant
+	if dropped[0].kind != itemUndatedNote || !strings.HasSuffix(dropped[0].text, "T") {
+		t.Errorf("expected the last undated note to be dropped first, got %+v", dropped[0])
+	}
+
+	// Today's event, weather and family are kept
+	if len(bc.Days[0].Events) != 1 || bc.Days[0].Weather == "" || len(bc.Family) != 2 {
+		t.Errorf("expected today's event, the weather and the family to be kept: %+v", bc.Days[0])
+	}
+}
+
+func TestContextItemValues(t *testing.T) {
+	bc := newTrimTestContext()
+	values := make(map[string]int)
+	for _, item := range contextItems(bc) {
+		values[item.kind+":"+item.text[:min(len(item.text), 12)]] = item.value
+	}
+
+	tests := []struct {
+		name          string
+		higher, lower string
+	}{
+		{"event over meal", "event:Calendar Eve", "meal:Fish soup"},
+		{"today's meal over a later one", "meal:Fish soup", "meal:Meatballs"},
+		{"dated note over undated", "note:Return libra", "undated_note:Undated note"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			higher, ok := values[test.higher]
+			lower, ok2 := values[test.lower]
+			if !ok || !ok2 {
+				t.Fatalf("items not found in %v", values)
+			}
+			if higher <= lower {
+				t.Errorf("expected %s (%d) to be worth more than %s (%d)", test.higher, higher, test.lower, lower)
+			}
+		})
+	}
+}
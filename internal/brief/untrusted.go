@@ -0,0 +1,115 @@
+package brief
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// TrustPolicy cleans the imported text of calendar events and memories before it's given
+// to the LLM, depending on the trust level of its source
+type TrustPolicy struct {
+	rules []config.SourceTrust
+}
+
+// NewTrustPolicy creates a trust policy with the source_trust rules
+func NewTrustPolicy(rules []config.SourceTrust) *TrustPolicy {
+	return &TrustPolicy{rules: rules}
+}
+
+// Level returns the trust level of the source from the most specific matching rule, or
+// untrusted if no rule matches. A rule matches its source exactly or as a prefix followed
+// by ":", so "calendar" covers "calendar:Family".
+func (p *TrustPolicy) Level(source string) string {
+	level, matched := config.TrustUntrusted, ""
+	if p == nil {
+		return level
+	}
+
+	for _, rule := range p.rules {
+		if rule.Source != source && !strings.HasPrefix(source, rule.Source+":") {
+			continue
+		}
+		if len(rule.Source) > len(matched) {
+			level, matched = rule.Trust, rule.Source
+		}
+	}
+	return level
+}
+
+// Events returns the events with their text cleaned
+func (p *TrustPolicy) Events(events []store.CalendarEvent) []store.CalendarEvent {
+	cleaned := make([]store.CalendarEvent, len(events))
+	for i, event := range events {
+		cleaned[i] = p.Event(event)
+	}
+	return cleaned
+}
+
+// Event returns the event with its text cleaned. The description and location of an
+// event from a restricted source are left out.
+func (p *TrustPolicy) Event(event store.CalendarEvent) store.CalendarEvent {
+	level := p.Level(event.Source)
+
+	event.Summary = p.clean(event.Summary, event.Source, level)
+	if level == config.TrustRestricted {
+		event.Description = nil
+		event.Location = nil
+		return event
+	}
+
+	if event.Description != nil {
+		description := p.clean(*event.Description, event.Source, level)
+		event.Description = &description
+	}
+	if event.Location != nil {
+		location := p.clean(*event.Location, event.Source, level)
+		event.Location = &location
+	}
+	return event
+}
+
+// Memories returns the memories with their content cleaned
+func (p *TrustPolicy) Memories(memories []store.Memory) []store.Memory {
+	cleaned := make([]store.Memory, len(memories))
+	for i, memory := range memories {
+		memory.Content = p.clean(memory.Content, memory.Source, p.Level(memory.Source))
+		cleaned[i] = memory
+	}
+	return cleaned
+}
+
+// SearchResults returns the search results with their text cleaned. Only the title of an
+// event from a restricted source is kept, since the snippet may come from its description
+// or location.
+func (p *TrustPolicy) SearchResults(results []store.SearchResult) []store.SearchResult {
+	cleaned := make([]store.SearchResult, len(results))
+	for i, result := range results {
+		level := p.Level(result.Source)
+		result.Title = p.clean(result.Title, result.Source, level)
+		if result.Kind == store.SearchKindEvent && level == config.TrustRestricted {
+			result.Snippet = result.Title
+		} else {
+			result.Snippet = p.clean(result.Snippet, result.Source, level)
+		}
+		cleaned[i] = result
+	}
+	return cleaned
+}
+
+// clean removes control characters from the text, and instruction-like patterns unless
+// the source is trusted
+func (p *TrustPolicy) clean(text, source, level string) string {
+	if level == config.TrustTrusted {
+		return llm.RemoveControlCharacters(text)
+	}
+
+	cleaned, found := llm.SanitizeUntrusted(text)
+	if found {
+		slog.Warn("Removed instruction-like text from imported data", "source", source, "text", text)
+	}
+	return cleaned
+}
@@ -0,0 +1,320 @@
+package brief
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/output"
+)
+
+var (
+	// timePattern matches clock times like 9:30 and 09:30, or 9.30 after "klo"
+	timePattern = regexp.MustCompile(`\b([01]?\d|2[0-3]):([0-5]\d)\b|(?i:klo|kello)\s*([01]?\d|2[0-3])\.([0-5]\d)\b`)
+	// datePattern matches day-first dates like 22.4. and 22.4.2025
+	datePattern = regexp.MustCompile(`\b([1-9]|[12]\d|3[01]|0[1-9])\.(0?[1-9]|1[0-2])\.`)
+	// isoDatePattern matches dates like 2025-04-22
+	isoDatePattern = regexp.MustCompile(`\b\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])\b`)
+	// temperaturePattern matches temperatures and ranges like -3°C and 2-6°C
+	temperaturePattern = regexp.MustCompile(`([-−]?\d+)(?:\s*[-–]\s*([-−]?\d+))?\s*°`)
+	// namePattern matches capitalized words, which may be names
+	namePattern = regexp.MustCompile(`\p{Lu}\p{L}+`)
+	// sentenceEnd splits text into sentences
+	sentenceEnd = regexp.MustCompile(`[.!?]\s+|\n`)
+)
+
+// birthdayWords mark a sentence as being about a birthday, in English and Finnish
+var birthdayWords = []string{"birthday", "syntymäpäiv", "synttär", "täyttä"}
+
+// Verifier checks generated briefs against the context they were generated from, so
+// that invented meetings or birthdays are caught before the family reads them
+type Verifier struct {
+	prompts    map[string][]string
+	regenerate bool
+}
+
+// NewVerifier creates a brief verifier. With regenerate set, a brief with issues is
+// generated again once with the issues as feedback; otherwise the issues are only logged.
+func NewVerifier(prompts map[string][]string, regenerate bool) *Verifier {
+	return &Verifier{
+		prompts:    prompts,
+		regenerate: regenerate,
+	}
+}
+
+// SetVerifier enables checking generated briefs against their context
+func (g *Generator) SetVerifier(verifier *Verifier) {
+	g.verifier = verifier
+}
+
+// verifyBrief checks the brief against the context and returns the brief to send: the
+// original, or a regenerated one if the original has issues and the verifier regenerates.
+// It also reports whether the returned brief is free of issues.
+func (g *Generator) verifyBrief(ctx context.Context, bc *llm.BriefContext, brief *output.Brief) (*output.Brief, bool) {
+	issues := g.briefIssues(brief, bc)
+	if len(issues) == 0 {
+		return brief, true
+	}
+
+	slog.Warn("Brief mentions details that aren't in its context", "issues", issues)
+	if !g.verifier.regenerate {
+		return brief, false
+	}
+
+	// The original brief is never cached, only a regenerated one without issues
+	g.settleCache(false)
+
+	regenerated, err := g.generateBrief(ctx, bc, issues)
+	if err != nil {
+		slog.Warn("Failed to regenerate brief, sending the original", "error", err)
+		return brief, false
+	}
+
+	remaining := g.briefIssues(regenerated, bc)
+	switch {
+	case len(remaining) > len(issues):
+		slog.Warn("Regenerated brief has more issues, sending the original", "issues", remaining)
+		return brief, false
+	case len(remaining) > 0:
+		slog.Warn("Regenerated brief still mentions details that aren't in its context", "issues", remaining)
+		return regenerated, false
+	default:
+		slog.Info("Regenerated brief without the issues", "fixed", len(issues))
+	}
+
+	return regenerated, true
+}
+
+// regenerateBrief asks the LLM for the brief again with the briefFeedback prompt listing
+// the issues of the previous one
+func (g *Generator) regenerateBrief(ctx context.Context, bc *llm.BriefContext, issues []string) (string, error) {
+	promptContent, err := llm.BuildBriefPrompt(g.verifier.prompts, bc)
+	if err != nil {
+		return "", err
+	}
+
+	feedback, err := llm.BuildBriefFeedbackPrompt(g.verifier.prompts, issues, bc.Language)
+	if err != nil {
+		return "", err
+	}
+	promptContent += "\n\n" + feedback
+
+	if g.toolbox != nil {
+		return g.toolbox.Generate(ctx, g.llm, "dailyBrief", bc.Language, promptContent)
+	}
+	return g.llm.Generate(ctx, "dailyBrief", bc.Language, promptContent)
+}
+
+// briefIssues returns the details of the brief that aren't in the context or in the
+// results of the tools the model called
+func (g *Generator) briefIssues(brief *output.Brief, bc *llm.BriefContext) []string {
+	source := llm.FormatBriefContext(bc)
+	if g.toolbox != nil {
+		for _, result := range g.toolbox.Results() {
+			source += "\n" + result.Result
+		}
+	}
+	return unsupportedDetails(briefTexts(brief), bc, source)
+}
+
+// briefTexts returns the texts of the brief meant for the reader
+func briefTexts(brief *output.Brief) []string {
+	if brief.IsPlain() {
+		return strings.Split(brief.Plain, "\n")
+	}
+
+	texts := []string{brief.Greeting}
+	for _, section := range brief.Sections {
+		texts = append(texts, section.Items...)
+	}
+	return append(texts, brief.Closing)
+}
+
+// unsupportedDetails checks the times, dates, temperatures and birthdays mentioned in the
+// texts against the brief context and the source text it was formatted as, and describes
+// each one that isn't supported
+func unsupportedDetails(texts []string, bc *llm.BriefContext, source string) []string {
+	var issues []string
+	addIssue := func(format string, args ...any) {
+		if issue := fmt.Sprintf(format, args...); !slices.Contains(issues, issue) {
+			issues = append(issues, issue)
+		}
+	}
+
+	sourceTimes := findTimes(source)
+	for _, event := range append(slices.Clone(bc.Ongoing), allEvents(bc)...) {
+		sourceTimes = append(sourceTimes, event.Start.Format("15:04"))
+		if event.End != nil {
+			sourceTimes = append(sourceTimes, event.End.Format("15:04"))
+		}
+	}
+
+	sourceDates := findDates(timePattern.ReplaceAllString(source, " "))
+	sourceDates = append(sourceDates, bc.Now.Format("01-02"))
+	for _, day := range bc.Days {
+		sourceDates = append(sourceDates, day.Date.Format("01-02"))
+	}
+
+	sourceTemperatures := findTemperatures(source)
+
+	for _, text := range texts {
+		for _, clock := range findTimes(text) {
+			if !slices.Contains(sourceTimes, clock) {
+				addIssue("time %s isn't in the calendar or notes", clock)
+			}
+		}
+
+		// Times are removed first so that "klo 10.11" isn't read as a date
+		for _, date := range findDates(timePattern.ReplaceAllString(text, " ")) {
+			if !slices.Contains(sourceDates, date) {
+				addIssue("date %s isn't in the information given", formatMonthDay(date))
+			}
+		}
+
+		for _, temperature := range findTemperatures(text) {
+			if len(sourceTemperatures) == 0 {
+				addIssue("temperature %d°C is mentioned but there's no weather forecast", temperature)
+				continue
+			}
+			// Allow rounding, and any value within the forecast range
+			low, high := slices.Min(sourceTemperatures), slices.Max(sourceTemperatures)
+			if temperature < low-1 || temperature > high+1 {
+				addIssue("temperature %d°C is outside the forecast range %d to %d°C", temperature, low, high)
+			}
+		}
+
+		for _, name := range unsupportedBirthdays(text, bc, source) {
+			addIssue("birthday of %s isn't in the information given", name)
+		}
+	}
+
+	return issues
+}
+
+// allEvents returns the calendar events of all the brief days
+func allEvents(bc *llm.BriefContext) []llm.BriefEvent {
+	var events []llm.BriefEvent
+	for _, day := range bc.Days {
+		events = append(events, day.Events...)
+	}
+	return events
+}
+
+// findTimes returns the clock times in the text as HH:MM
+func findTimes(text string) []string {
+	var times []string
+	for _, match := range timePattern.FindAllStringSubmatch(text, -1) {
+		hour, minute := match[1], match[2]
+		if hour == "" {
+			hour, minute = match[3], match[4]
+		}
+		h, _ := strconv.Atoi(hour)
+		times = append(times, fmt.Sprintf("%02d:%s", h, minute))
+	}
+	return times
+}
+
+// findDates returns the dates in the text as MM-DD, ignoring the year
+func findDates(text string) []string {
+	var dates []string
+	for _, match := range isoDatePattern.FindAllStringSubmatch(text, -1) {
+		dates = append(dates, match[1]+"-"+match[2])
+	}
+	for _, match := range datePattern.FindAllStringSubmatch(isoDatePattern.ReplaceAllString(text, " "), -1) {
+		day, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		dates = append(dates, fmt.Sprintf("%02d-%02d", month, day))
+	}
+	return dates
+}
+
+// formatMonthDay formats an MM-DD date the way it's usually written in the briefs
+func formatMonthDay(date string) string {
+	month, day, _ := strings.Cut(date, "-")
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	return fmt.Sprintf("%d.%d.", d, m)
+}
+
+// findTemperatures returns the temperatures in the text, both ends of a range separately
+func findTemperatures(text string) []int {
+	var temperatures []int
+	for _, match := range temperaturePattern.FindAllStringSubmatch(text, -1) {
+		for _, value := range match[1:] {
+			if value == "" {
+				continue
+			}
+			temperature, err := strconv.Atoi(strings.ReplaceAll(value, "−", "-"))
+			if err == nil {
+				temperatures = append(temperatures, temperature)
+			}
+		}
+	}
+	return temperatures
+}
+
+// unsupportedBirthdays returns the names in the text's birthday sentences that don't have
+// a birthday in the context. A family member without a birthday today is unsupported, as
+// is a name that isn't in the context at all.
+func unsupportedBirthdays(text string, bc *llm.BriefContext, source string) []string {
+	var known []string
+	for _, birthday := range bc.Birthdays {
+		known = append(known, birthday.Name)
+	}
+	// Birthdays mentioned in the calendar or notes count too
+	for _, line := range strings.Split(source, "\n") {
+		if mentionsBirthday(line) {
+			known = append(known, namePattern.FindAllString(line, -1)...)
+		}
+	}
+
+	lowerSource := strings.ToLower(source)
+
+	var names []string
+	for _, sentence := range sentenceEnd.Split(text, -1) {
+		sentence = strings.TrimSpace(sentence)
+		if !mentionsBirthday(sentence) {
+			continue
+		}
+
+		for _, loc := range namePattern.FindAllStringIndex(sentence, -1) {
+			name := sentence[loc[0]:loc[1]]
+			family := matchesName(name, bc.Family)
+			// A capitalized word starting the sentence isn't necessarily a name
+			first := !strings.ContainsFunc(sentence[:loc[0]], unicode.IsLetter)
+			if first && !family {
+				continue
+			}
+			if mentionsBirthday(name) || matchesName(name, known) {
+				continue
+			}
+			if family || !strings.Contains(lowerSource, strings.ToLower(name)) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// mentionsBirthday reports whether the text is about a birthday
+func mentionsBirthday(text string) bool {
+	lower := strings.ToLower(text)
+	return slices.ContainsFunc(birthdayWords, func(word string) bool {
+		return strings.Contains(lower, word)
+	})
+}
+
+// matchesName reports whether the word is one of the names, allowing inflected forms
+// like the Finnish genitive "Maijan"
+func matchesName(word string, names []string) bool {
+	return slices.ContainsFunc(names, func(name string) bool {
+		return name != "" && strings.HasPrefix(word, name)
+	})
+}
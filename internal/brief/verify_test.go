@@ -0,0 +1,98 @@
+package brief
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/output"
+)
+
+func TestUnsupportedDetails(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "event times", text: "Dentist at 09:00, you should be back by 10:00."},
+		{name: "temperature range", text: "Partly cloudy, 3-11°C, tomorrow rain at 2°C."},
+		{name: "rounded temperature", text: "Up to 12°C in the afternoon."},
+		{name: "brief dates", text: "Friday 18.4. and Saturday 2025-04-19."},
+		{name: "birthday", text: "Alice turns 40 today, happy birthday!"},
+		{name: "Finnish birthday", text: "Hyvää syntymäpäivää! Tänään on Alicen syntymäpäivä."},
+		{
+			name:     "invented meeting",
+			text:     "Meeting with the principal at 9:30.",
+			expected: []string{"time 09:30 isn't in the calendar or notes"},
+		},
+		{
+			name:     "Finnish time",
+			text:     "Vanhempainilta klo 18.15.",
+			expected: []string{"time 18:15 isn't in the calendar or notes"},
+		},
+		{
+			name:     "invented date",
+			text:     "The library books are due 25.4.",
+			expected: []string{"date 25.4. isn't in the information given"},
+		},
+		{
+			name:     "temperature outside the forecast",
+			text:     "A warm day, up to 25°C.",
+			expected: []string{"temperature 25°C is outside the forecast range 2 to 11°C"},
+		},
+		{
+			name:     "family member without a birthday",
+			text:     "Happy birthday, Bob!",
+			expected: []string{"birthday of Bob isn't in the information given"},
+		},
+		{
+			name:     "unknown person",
+			text:     "Don't forget Liisa's birthday.",
+			expected: []string{"birthday of Liisa isn't in the information given"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bc := llm.SampleBriefContext()
+			got := unsupportedDetails([]string{test.text}, bc, llm.FormatBriefContext(bc))
+			if !slices.Equal(got, test.expected) {
+				t.Errorf("unsupportedDetails(%q) = %q, expected %q", test.text, got, test.expected)
+			}
+		})
+	}
+}
+
+func TestUnsupportedDetailsWithoutForecast(t *testing.T) {
+	bc := llm.SampleBriefContext()
+	for i := range bc.Days {
+		bc.Days[i].Weather = ""
+		bc.Days[i].HourlyForecast = ""
+	}
+
+	got := unsupportedDetails([]string{"Sunny and 20°C!"}, bc, llm.FormatBriefContext(bc))
+	expected := []string{"temperature 20°C is mentioned but there's no weather forecast"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("unsupportedDetails() = %q, expected %q", got, expected)
+	}
+}
+
+func TestBriefTexts(t *testing.T) {
+	brief := &output.Brief{
+		Greeting: "Good morning!",
+		Sections: []output.BriefSection{
+			{Title: "Calendar at 12:00", Items: []string{"Dentist at 09:00", "Meeting at 14:00"}},
+		},
+		Closing: "Have a nice day!",
+	}
+
+	// Section titles aren't checked
+	expected := []string{"Good morning!", "Dentist at 09:00", "Meeting at 14:00", "Have a nice day!"}
+	if got := briefTexts(brief); !slices.Equal(got, expected) {
+		t.Errorf("briefTexts() = %q, expected %q", got, expected)
+	}
+
+	if got := briefTexts(output.PlainBrief("Line 1\nLine 2")); !slices.Equal(got, []string{"Line 1", "Line 2"}) {
+		t.Errorf("briefTexts() for a plain brief = %q", got)
+	}
+}
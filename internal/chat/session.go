@@ -0,0 +1,157 @@
+// Package chat implements multi-turn conversations with the butler. A Session keeps its
+// turns in the database so that any frontend (the chat command, a bot) can continue it.
+package chat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// Roles of the conversation turns
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleSummary   = "summary"
+)
+
+// Store is the storage used by chat sessions
+type Store interface {
+	AddConversationTurn(sessionID, role, content string) (int64, error)
+	GetConversationTurns(sessionID string) ([]store.ConversationTurn, error)
+	CompactConversation(sessionID string, upToID int64, summary string) error
+	DeleteConversation(sessionID string) error
+}
+
+// NotesFunc returns the memories and calendar events given to the LLM with a message
+type NotesFunc func(ctx context.Context, message string) ([]string, error)
+
+// Session is a single persisted conversation
+type Session struct {
+	id         string
+	store      Store
+	llm        llm.Provider
+	prompts    map[string][]string
+	notes      NotesFunc
+	language   string
+	maxHistory int
+}
+
+// NewSession creates a session with the given ID. Up to maxHistory turns are sent to
+// the LLM as is; older turns are summarized.
+func NewSession(id string, store Store, provider llm.Provider, prompts map[string][]string, notes NotesFunc, language string, maxHistory int) *Session {
+	return &Session{
+		id:         id,
+		store:      store,
+		llm:        provider,
+		prompts:    prompts,
+		notes:      notes,
+		language:   language,
+		maxHistory: maxHistory,
+	}
+}
+
+// ID returns the session ID
+func (s *Session) ID() string {
+	return s.id
+}
+
+// Reset deletes the history of the session
+func (s *Session) Reset() error {
+	return s.store.DeleteConversation(s.id)
+}
+
+// Send sends a message in the session and returns the reply. The recent history, the
+// summary of older turns and the retrieved notes are sent to the LLM with the message.
+func (s *Session) Send(ctx context.Context, message string) (string, error) {
+	turns, err := s.store.GetConversationTurns(s.id)
+	if err != nil {
+		return "", fmt.Errorf("failed to load conversation: %w", err)
+	}
+	summary, history := splitHistory(turns)
+
+	notes, err := s.notes(ctx, message)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve memories: %w", err)
+	}
+
+	promptContent, err := llm.BuildChatPrompt(s.prompts, message, toChatTurns(history), summary, notes, s.language)
+	if err != nil {
+		return "", err
+	}
+
+	reply, err := s.llm.Generate(ctx, "chat", s.language, promptContent)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate reply: %w", err)
+	}
+
+	if _, err := s.store.AddConversationTurn(s.id, RoleUser, message); err != nil {
+		return "", fmt.Errorf("failed to save message: %w", err)
+	}
+	if _, err := s.store.AddConversationTurn(s.id, RoleAssistant, reply); err != nil {
+		return "", fmt.Errorf("failed to save reply: %w", err)
+	}
+
+	// The reply is already saved, so a failed summary only means a longer prompt next time
+	if err := s.compact(ctx); err != nil {
+		slog.Warn("Failed to summarize conversation history", "session", s.id, "error", err)
+	}
+
+	return reply, nil
+}
+
+// compact summarizes the oldest turns once the history grows past the cap, keeping the
+// most recent half of the cap as is
+func (s *Session) compact(ctx context.Context) error {
+	turns, err := s.store.GetConversationTurns(s.id)
+	if err != nil {
+		return fmt.Errorf("failed to load conversation: %w", err)
+	}
+
+	summary, history := splitHistory(turns)
+	if len(history) <= s.maxHistory {
+		return nil
+	}
+
+	old := history[:len(history)-s.maxHistory/2]
+
+	promptContent, err := llm.BuildChatSummaryPrompt(s.prompts, summary, toChatTurns(old), s.language)
+	if err != nil {
+		return err
+	}
+
+	newSummary, err := s.llm.Generate(ctx, "chatSummary", s.language, promptContent)
+	if err != nil {
+		return fmt.Errorf("failed to generate summary: %w", err)
+	}
+
+	slog.Debug("Summarized conversation history", "session", s.id, "turns", len(old))
+	return s.store.CompactConversation(s.id, old[len(old)-1].ID, newSummary)
+}
+
+// splitHistory separates the summary of older turns from the turns after it
+func splitHistory(turns []store.ConversationTurn) (string, []store.ConversationTurn) {
+	var summary string
+	var history []store.ConversationTurn
+	for _, turn := range turns {
+		if turn.Role == RoleSummary {
+			summary = turn.Content
+			history = nil
+			continue
+		}
+		history = append(history, turn)
+	}
+	return summary, history
+}
+
+// toChatTurns converts stored turns to the turns given to the prompt template
+func toChatTurns(turns []store.ConversationTurn) []llm.ChatTurn {
+	chatTurns := make([]llm.ChatTurn, 0, len(turns))
+	for _, turn := range turns {
+		chatTurns = append(chatTurns, llm.ChatTurn{Role: turn.Role, Content: turn.Content})
+	}
+	return chatTurns
+}
@@ -0,0 +1,164 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// memoryStore is an in-memory Store
+type memoryStore struct {
+	turns  []store.ConversationTurn
+	nextID int64
+}
+
+func (m *memoryStore) AddConversationTurn(sessionID, role, content string) (int64, error) {
+	m.nextID++
+	m.turns = append(m.turns, store.ConversationTurn{ID: m.nextID, SessionID: sessionID, Role: role, Content: content})
+	return m.nextID, nil
+}
+
+func (m *memoryStore) GetConversationTurns(sessionID string) ([]store.ConversationTurn, error) {
+	var turns []store.ConversationTurn
+	for _, turn := range m.turns {
+		if turn.SessionID == sessionID {
+			turns = append(turns, turn)
+		}
+	}
+	return turns, nil
+}
+
+func (m *memoryStore) CompactConversation(sessionID string, upToID int64, summary string) error {
+	var kept []store.ConversationTurn
+	for _, turn := range m.turns {
+		switch {
+		case turn.SessionID != sessionID || turn.ID > upToID:
+			kept = append(kept, turn)
+		case turn.ID == upToID:
+			turn.Role = RoleSummary
+			turn.Content = summary
+			kept = append(kept, turn)
+		}
+	}
+	m.turns = kept
+	return nil
+}
+
+func (m *memoryStore) DeleteConversation(sessionID string) error {
+	return m.CompactConversation(sessionID, m.nextID+1, "")
+}
+
+// scriptedProvider answers every prompt with a numbered reply and remembers the prompts
+type scriptedProvider struct {
+	prompts map[string][]string // prompt contents by prompt key
+}
+
+func (p *scriptedProvider) Name() string                                 { return "fake" }
+func (p *scriptedProvider) Model() string                                { return "fake-model" }
+func (p *scriptedProvider) Close() error                                 { return nil }
+func (p *scriptedProvider) SetRecorder(recorder llm.CallRecorder)        {}
+func (p *scriptedProvider) SetCache(cache llm.ResponseCache)             {}
+func (p *scriptedProvider) ListModels(context.Context) ([]string, error) { return nil, nil }
+
+func (p *scriptedProvider) Generate(ctx context.Context, promptKey, outputLanguage, promptContent string) (string, error) {
+	if p.prompts == nil {
+		p.prompts = map[string][]string{}
+	}
+	p.prompts[promptKey] = append(p.prompts[promptKey], promptContent)
+	return fmt.Sprintf("%s reply %d", promptKey, len(p.prompts[promptKey])), nil
+}
+
+func (p *scriptedProvider) GenerateBrief(ctx context.Context, bc *llm.BriefContext) (string, error) {
+	return p.Generate(ctx, "dailyBrief", bc.Language, "")
+}
+
+func (p *scriptedProvider) GenerateResponse(ctx context.Context, query string, memories []string, outputLanguage string) (string, error) {
+	return p.Generate(ctx, "userQuery", outputLanguage, query)
+}
+
+func newTestSession(t *testing.T, maxHistory int) (*Session, *memoryStore, *scriptedProvider) {
+	t.Helper()
+
+	prompts, err := config.DefaultPrompts()
+	if err != nil {
+		t.Fatalf("DefaultPrompts() error = %v", err)
+	}
+
+	notes := func(ctx context.Context, message string) ([]string, error) {
+		return []string{"Calendar Event: Dentist on Thursday 2025-04-24 at 09:00 [Source: calendar:Family]"}, nil
+	}
+
+	memStore := &memoryStore{}
+	provider := &scriptedProvider{}
+	return NewSession("test", memStore, provider, prompts, notes, "English", maxHistory), memStore, provider
+}
+
+func TestSessionSendIncludesHistory(t *testing.T) {
+	session, memStore, provider := newTestSession(t, 10)
+	ctx := context.Background()
+
+	if _, err := session.Send(ctx, "What is on Tuesday?"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	reply, err := session.Send(ctx, "And what about Thursday?")
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if reply != "chat reply 2" {
+		t.Errorf("Send() = %q, expected %q", reply, "chat reply 2")
+	}
+
+	// The follow-up prompt carries the earlier turns and the retrieved notes
+	prompt := provider.prompts["chat"][1]
+	for _, want := range []string{
+		"user: What is on Tuesday?\nassistant: chat reply 1",
+		"user: And what about Thursday?",
+		"Calendar Event: Dentist on Thursday",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("chat prompt missing %q:\n%s", want, prompt)
+		}
+	}
+
+	if len(memStore.turns) != 4 {
+		t.Errorf("expected 4 stored turns, got %d", len(memStore.turns))
+	}
+}
+
+func TestSessionSummarizesOldTurns(t *testing.T) {
+	session, memStore, provider := newTestSession(t, 4)
+	ctx := context.Background()
+
+	for i := range 3 {
+		if _, err := session.Send(ctx, fmt.Sprintf("Question %d", i+1)); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	// Six turns exceed the cap of four: the oldest four are summarized, two are kept
+	if len(provider.prompts["chatSummary"]) != 1 {
+		t.Fatalf("expected 1 summary prompt, got %d", len(provider.prompts["chatSummary"]))
+	}
+
+	summary, history := splitHistory(memStore.turns)
+	if summary != "chatSummary reply 1" {
+		t.Errorf("summary = %q, expected %q", summary, "chatSummary reply 1")
+	}
+	if len(history) != 2 || history[0].Content != "Question 3" {
+		t.Errorf("unexpected history after summarizing: %+v", history)
+	}
+
+	// The next message is sent with the summary instead of the summarized turns
+	if _, err := session.Send(ctx, "Question 4"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	prompt := provider.prompts["chat"][3]
+	if !strings.Contains(prompt, "chatSummary reply 1") || strings.Contains(prompt, "Question 1") {
+		t.Errorf("unexpected chat prompt after summarizing:\n%s", prompt)
+	}
+}
@@ -7,6 +7,6 @@
 // - Automatic environment variable binding
 // - Support for different configuration formats
 //
-// Please use the functions in viper.go (InitViper, GetConfig, LoadPrompts) for
-// all configuration-related operations.
+// Please use the functions in viper.go (InitViper, GetConfig) and prompts.go
+// (LoadPrompts) for all configuration-related operations.
 package config
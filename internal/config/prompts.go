@@ -0,0 +1,147 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/lepinkainen/hovimestari/internal/xdg"
+)
+
+// PromptSourceEmbedded is the source reported for prompts that come from the defaults
+// built into the binary
+const PromptSourceEmbedded = "embedded"
+
+// defaultPromptsJSON contains the default prompts shipped inside the binary
+//
+//go:embed prompts.json
+var defaultPromptsJSON []byte
+
+// PromptSet holds the effective prompts and where each prompt key came from
+type PromptSet struct {
+	Prompts map[string][]string
+	// Sources maps each prompt key to PromptSourceEmbedded or the path of the override file
+	Sources map[string]string
+	// Overridden holds the keys whose embedded default is replaced by a different prompt
+	// from an override file
+	Overridden map[string]bool
+
+	defaults map[string][]string
+}
+
+// newPromptSet creates an empty prompt set
+func newPromptSet() *PromptSet {
+	return &PromptSet{
+		Prompts:    make(map[string][]string),
+		Sources:    make(map[string]string),
+		Overridden: make(map[string]bool),
+		defaults:   make(map[string][]string),
+	}
+}
+
+// DefaultPrompts returns the default prompts embedded in the binary
+func DefaultPrompts() (map[string][]string, error) {
+	var prompts map[string][]string
+	if err := json.Unmarshal(defaultPromptsJSON, &prompts); err != nil {
+		return nil, fmt.Errorf("failed to decode embedded prompts: %w", err)
+	}
+	return prompts, nil
+}
+
+// LoadPrompts loads the effective prompts, see ResolvePrompts
+func LoadPrompts(filePath string) (map[string][]string, error) {
+	set, err := ResolvePrompts(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return set.Prompts, nil
+}
+
+// ResolvePrompts loads the embedded default prompts and layers the overrides on top,
+// merged per prompt key:
+// 1. prompts.json in the XDG config directory, if it exists
+// 2. The specified file path (promptFilePath in the configuration), if not empty
+//
+// A warning is logged for each default replaced by a different prompt, since an override
+// copied from an older version misses the changes made to the default since then.
+func ResolvePrompts(filePath string) (*PromptSet, error) {
+	defaults, err := DefaultPrompts()
+	if err != nil {
+		return nil, err
+	}
+
+	set := newPromptSet()
+	set.merge(defaults, PromptSourceEmbedded)
+
+	// The XDG override is optional
+	xdgPath, err := xdg.GetConfigPath("prompts.json")
+	if err != nil {
+		slog.Warn("Failed to get config directory for prompt overrides", "error", err)
+	} else if err := set.mergeFile(xdgPath, false); err != nil {
+		return nil, err
+	}
+
+	// An explicitly configured prompt file is expected to exist, so warn if it doesn't
+	if filePath != "" && filePath != xdgPath {
+		if err := set.mergeFile(filePath, true); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(set.Overridden)) {
+		slog.Warn("Prompt override replaces the built-in prompt, remove it from the file to get the updated default",
+			"key", key, "path", set.Sources[key])
+	}
+
+	return set, nil
+}
+
+// merge overrides the prompts in the set with the given prompts. Embedded prompts are
+// remembered as the defaults that overrides are compared to.
+func (s *PromptSet) merge(prompts map[string][]string, source string) {
+	for key, lines := range prompts {
+		s.Prompts[key] = lines
+		s.Sources[key] = source
+
+		if source == PromptSourceEmbedded {
+			s.defaults[key] = lines
+			continue
+		}
+		defaultLines, ok := s.defaults[key]
+		if ok && !slices.Equal(defaultLines, lines) {
+			s.Overridden[key] = true
+		} else {
+			delete(s.Overridden, key)
+		}
+	}
+}
+
+// mergeFile reads a prompts file and merges it into the set. A missing file is skipped,
+// with a warning if warnIfMissing is set.
+func (s *PromptSet) mergeFile(path string, warnIfMissing bool) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if warnIfMissing {
+			slog.Warn("Prompts file not found, using the remaining prompts", "path", path)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open prompts file: %w", err)
+	}
+
+	var prompts map[string][]string
+	if err := json.Unmarshal(data, &prompts); err != nil {
+		return fmt.Errorf("failed to decode prompts file %s: %w", path, err)
+	}
+
+	slog.Debug("Loaded prompt overrides", "path", path, "count", len(prompts))
+	s.merge(prompts, path)
+
+	return nil
+}
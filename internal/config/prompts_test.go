@@ -0,0 +1,88 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultPrompts(t *testing.T) {
+	prompts, err := DefaultPrompts()
+	if err != nil {
+		t.Fatalf("DefaultPrompts() error = %v", err)
+	}
+
+	for _, key := range []string{"dailyBrief", "userQuery"} {
+		if len(prompts[key]) == 0 {
+			t.Errorf("expected embedded prompt %q", key)
+		}
+	}
+}
+
+func TestPromptSetMergeFile(t *testing.T) {
+	defaults, err := DefaultPrompts()
+	if err != nil {
+		t.Fatalf("DefaultPrompts() error = %v", err)
+	}
+
+	set := newPromptSet()
+	set.merge(defaults, PromptSourceEmbedded)
+
+	dir := t.TempDir()
+	overridePath := filepath.Join(dir, "prompts.json")
+	if err := os.WriteFile(overridePath, []byte(`{"userQuery": ["Custom %QUERY%"]}`), 0644); err != nil {
+		t.Fatalf("failed to write override: %v", err)
+	}
+
+	if err := set.mergeFile(overridePath, true); err != nil {
+		t.Fatalf("mergeFile() error = %v", err)
+	}
+
+	if got := set.Prompts["userQuery"]; len(got) != 1 || got[0] != "Custom %QUERY%" {
+		t.Errorf("expected userQuery to be overridden, got %v", got)
+	}
+	if set.Sources["userQuery"] != overridePath {
+		t.Errorf("expected userQuery source %s, got %s", overridePath, set.Sources["userQuery"])
+	}
+	if set.Sources["dailyBrief"] != PromptSourceEmbedded {
+		t.Errorf("expected dailyBrief to stay embedded, got %s", set.Sources["dailyBrief"])
+	}
+	if !set.Overridden["userQuery"] || set.Overridden["dailyBrief"] {
+		t.Errorf("expected only userQuery to be marked overridden, got %v", set.Overridden)
+	}
+
+	// An override identical to the default doesn't count as overriding it
+	copyPath := filepath.Join(dir, "copy.json")
+	copyData, err := json.Marshal(map[string][]string{"dailyBrief": defaults["dailyBrief"]})
+	if err != nil {
+		t.Fatalf("failed to encode copy: %v", err)
+	}
+	if err := os.WriteFile(copyPath, copyData, 0644); err != nil {
+		t.Fatalf("failed to write copy: %v", err)
+	}
+	if err := set.mergeFile(copyPath, true); err != nil {
+		t.Fatalf("mergeFile() error = %v", err)
+	}
+	if set.Overridden["dailyBrief"] {
+		t.Error("expected a copy of the default not to be marked overridden")
+	}
+
+	// A missing file is skipped
+	missing := filepath.Join(dir, "missing.json")
+	if err := set.mergeFile(missing, true); err != nil {
+		t.Errorf("expected missing file to be skipped, got %v", err)
+	}
+	if set.Sources["userQuery"] != overridePath {
+		t.Errorf("expected missing file to leave prompts untouched, got source %s", set.Sources["userQuery"])
+	}
+
+	// Invalid JSON is always an error
+	invalidPath := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalidPath, []byte(`{`), 0644); err != nil {
+		t.Fatalf("failed to write invalid file: %v", err)
+	}
+	if err := set.mergeFile(invalidPath, false); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
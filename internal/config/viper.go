@@ -1,7 +1,6 @@
 package config
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -30,15 +29,74 @@ type FamilyMember struct {
 
 // TelegramConfig holds configuration for a Telegram bot
 type TelegramConfig struct {
-	BotToken string `json:"bot_token" mapstructure:"bot_token"`
-	ChatID   string `json:"chat_id" mapstructure:"chat_id"`
+	BotToken        string   `json:"bot_token" mapstructure:"bot_token"`
+	ChatID          string   `json:"chat_id" mapstructure:"chat_id"`
+	ExcludeSections []string `json:"exclude_sections,omitempty" mapstructure:"exclude_sections"` // Brief section keys or titles to leave out
+}
+
+// DiscordConfig holds configuration for a Discord webhook
+type DiscordConfig struct {
+	WebhookURL      string   `json:"webhook_url" mapstructure:"webhook_url"`
+	ExcludeSections []string `json:"exclude_sections,omitempty" mapstructure:"exclude_sections"` // Brief section keys or titles to leave out
+}
+
+// EmailConfig holds configuration for sending the brief by email over SMTP
+type EmailConfig struct {
+	SMTPHost        string   `json:"smtp_host" mapstructure:"smtp_host"`
+	SMTPPort        int      `json:"smtp_port,omitempty" mapstructure:"smtp_port"` // Defaults to 587
+	Username        string   `json:"username,omitempty" mapstructure:"username"`
+	Password        string   `json:"password,omitempty" mapstructure:"password"`
+	From            string   `json:"from" mapstructure:"from"`
+	To              []string `json:"to" mapstructure:"to"`
+	Subject         string   `json:"subject,omitempty" mapstructure:"subject"`
+	ExcludeSections []string `json:"exclude_sections,omitempty" mapstructure:"exclude_sections"` // Brief section keys or titles to leave out
 }
 
 // OutputConfig holds configuration for various output methods
 type OutputConfig struct {
 	EnableCLI          bool             `json:"enable_cli" mapstructure:"enable_cli"`
+	CLIExcludeSections []string         `json:"cli_exclude_sections,omitempty" mapstructure:"cli_exclude_sections"`
 	DiscordWebhookURLs []string         `json:"discord_webhook_urls,omitempty" mapstructure:"discord_webhook_urls"`
+	DiscordWebhooks    []DiscordConfig  `json:"discord_webhooks,omitempty" mapstructure:"discord_webhooks"`
 	TelegramBots       []TelegramConfig `json:"telegram_bots,omitempty" mapstructure:"telegram_bots"`
+	Emails             []EmailConfig    `json:"emails,omitempty" mapstructure:"emails"`
+}
+
+// HasChannels reports whether any output channel other than the CLI is configured
+func (o OutputConfig) HasChannels() bool {
+	return len(o.DiscordWebhookURLs) > 0 || len(o.DiscordWebhooks) > 0 || len(o.TelegramBots) > 0 || len(o.Emails) > 0
+}
+
+// LLMChainEntry defines a single provider/model entry in the LLM fallback chain
+type LLMChainEntry struct {
+	Provider string `json:"provider" mapstructure:"provider"`     // "gemini", "ollama" or "openai"
+	Model    string `json:"model,omitempty" mapstructure:"model"` // Model name, defaults to the provider's configured model
+}
+
+// ModelPrice defines the price of an LLM model, used to estimate the cost of each call
+type ModelPrice struct {
+	Model            string  `json:"model" mapstructure:"model"`                           // Model name as configured, e.g. "gemini-2.5-flash"
+	InputPerMillion  float64 `json:"input_per_million" mapstructure:"input_per_million"`   // Euros per million input tokens
+	OutputPerMillion float64 `json:"output_per_million" mapstructure:"output_per_million"` // Euros per million output tokens
+}
+
+// ContextBudget holds the token budget for the brief context sent to a single model
+type ContextBudget struct {
+	Model  string `json:"model" mapstructure:"model"`   // Model name as configured, e.g. "llama3.2"
+	Tokens int    `json:"tokens" mapstructure:"tokens"` // Estimated tokens the brief context may use
+}
+
+// SourceTrust holds the trust level of the text imported from a source
+type SourceTrust struct {
+	Source string `json:"source" mapstructure:"source"` // Source or source prefix, e.g. "calendar:Shared" or "schoollunch"
+	Trust  string `json:"trust" mapstructure:"trust"`   // "trusted", "untrusted" or "restricted"
+}
+
+// RetentionRule limits how many memories from a source are kept
+type RetentionRule struct {
+	Source            string `json:"source" mapstructure:"source"`                                       // Source or source prefix, e.g. "weather-metno" or "electricity:*"
+	MaxAgeDays        int    `json:"max_age_days,omitempty" mapstructure:"max_age_days"`                 // Delete memories relevant (or added, if undated) more than this many days ago, 0 keeps them
+	KeepLatestPerDate bool   `json:"keep_latest_per_date,omitempty" mapstructure:"keep_latest_per_date"` // Keep only the newest memory for each relevance date
 }
 
 // WaterQualityLocation holds configuration for a water quality measurement location
@@ -55,14 +113,64 @@ type Config struct {
 	LogLevel string `json:"log_level,omitempty" mapstructure:"log_level"` // Log level (debug, info, warn, error)
 
 	// LLM configuration
+	LLMProvider    string `json:"llm_provider,omitempty" mapstructure:"llm_provider"` // LLM provider to use ("gemini", "ollama" or "openai")
 	GeminiAPIKey   string `json:"gemini_api_key" mapstructure:"gemini_api_key"`
 	GeminiModel    string `json:"gemini_model,omitempty" mapstructure:"gemini_model"` // Gemini model to use (e.g., "gemini-2.0-flash")
 	OutputLanguage string `json:"outputLanguage" mapstructure:"outputLanguage"`       // Language for LLM responses (e.g., "Finnish", "English")
 	PromptFilePath string `json:"promptFilePath" mapstructure:"promptFilePath"`       // Path to the prompts.json file
 
+	// LLM fallback chain configuration
+	LLMChain             []LLMChainEntry `json:"llm_chain,omitempty" mapstructure:"llm_chain"`                             // Ordered list of providers/models to try
+	LLMMaxRetries        int             `json:"llm_max_retries,omitempty" mapstructure:"llm_max_retries"`                 // Retries per chain entry on retryable errors
+	LLMRetryDelaySeconds int             `json:"llm_retry_delay_seconds,omitempty" mapstructure:"llm_retry_delay_seconds"` // Initial backoff delay, doubled on each retry
+
+	// LLM cost tracking configuration
+	LLMPrices               []ModelPrice  `json:"llm_prices,omitempty" mapstructure:"llm_prices"`                                 // Per-model prices for the call ledger
+	LLMBudgetEURMonthly     float64       `json:"llm_budget_eur_monthly,omitempty" mapstructure:"llm_budget_eur_monthly"`         // Monthly LLM spend cap in euros, 0 disables the budget
+	LLMBudgetDowngradeRatio float64       `json:"llm_budget_downgrade_ratio,omitempty" mapstructure:"llm_budget_downgrade_ratio"` // Share of the budget after which the downgrade model is used
+	LLMBudgetDowngrade      LLMChainEntry `json:"llm_budget_downgrade,omitzero" mapstructure:"llm_budget_downgrade"`              // Cheaper provider/model to use when nearing the budget
+
+	// LLM context budget configuration
+	LLMContextTokens  int             `json:"llm_context_tokens,omitempty" mapstructure:"llm_context_tokens"`   // Token budget for the brief context, 0 for no limit
+	LLMContextBudgets []ContextBudget `json:"llm_context_budgets,omitempty" mapstructure:"llm_context_budgets"` // Per-model budgets overriding llm_context_tokens
+
+	// LLM tool calling configuration
+	LLMTools         bool `json:"llm_tools,omitempty" mapstructure:"llm_tools"`                     // Let the model look up events, memories, weather and prices while answering
+	LLMMaxToolRounds int  `json:"llm_max_tool_rounds,omitempty" mapstructure:"llm_max_tool_rounds"` // Rounds of tool calls before the model has to answer
+
+	// Brief verification configuration
+	BriefVerification string `json:"brief_verification,omitempty" mapstructure:"brief_verification"` // "off", "log" or "regenerate" when a brief has details missing from its context
+
+	// Prompt injection hardening configuration
+	SourceTrust []SourceTrust `json:"source_trust,omitempty" mapstructure:"source_trust"` // Per-source trust levels, sources not listed are untrusted
+
+	// Memory retention configuration
+	Retention []RetentionRule `json:"retention,omitempty" mapstructure:"retention"`   // Per-source retention rules, sources not listed are kept forever
+	AutoPrune bool            `json:"auto_prune,omitempty" mapstructure:"auto_prune"` // Apply the retention rules after every import
+
+	// LLM response cache configuration
+	LLMCacheTTLMinutes int `json:"llm_cache_ttl_minutes,omitempty" mapstructure:"llm_cache_ttl_minutes"` // How long identical prompts are answered from the cache, 0 disables caching
+
+	// Memory retrieval configuration
+	EmbeddingProvider string `json:"embedding_provider,omitempty" mapstructure:"embedding_provider"` // "gemini" or "ollama", empty disables semantic retrieval
+	EmbeddingModel    string `json:"embedding_model,omitempty" mapstructure:"embedding_model"`       // Embedding model, defaults depend on the provider
+	MemoryTopK        int    `json:"memory_top_k,omitempty" mapstructure:"memory_top_k"`             // Undated memories included per prompt with semantic retrieval
+
+	// Ollama configuration
+	OllamaURL   string `json:"ollama_url,omitempty" mapstructure:"ollama_url"`     // URL of the Ollama API server
+	OllamaModel string `json:"ollama_model,omitempty" mapstructure:"ollama_model"` // Model name to use with Ollama
+
+	// OpenAI-compatible API configuration
+	OpenAIBaseURL string `json:"openai_base_url,omitempty" mapstructure:"openai_base_url"` // Base URL including the version path, e.g. "http://localhost:8080/v1"
+	OpenAIModel   string `json:"openai_model,omitempty" mapstructure:"openai_model"`       // Model name to request
+	OpenAIAPIKey  string `json:"openai_api_key,omitempty" mapstructure:"openai_api_key"`   // API key (optional for local servers)
+
 	// Brief configuration
 	DaysAhead int `json:"days_ahead,omitempty" mapstructure:"days_ahead"` // Number of days ahead to include in the brief
 
+	// Chat configuration
+	ChatHistoryTurns int `json:"chat_history_turns,omitempty" mapstructure:"chat_history_turns"` // Turns sent to the LLM as is before older turns are summarized
+
 	// Location configuration
 	LocationName string  `json:"location_name" mapstructure:"location_name"`
 	Latitude     float64 `json:"latitude" mapstructure:"latitude"`
@@ -93,8 +201,41 @@ type Config struct {
 
 // validateRequiredFields validates that required configuration fields are present
 func validateRequiredFields(config *Config) error {
-	if config.GeminiAPIKey == "" {
-		return fmt.Errorf("gemini API key is required")
+	if len(config.LLMChain) == 0 {
+		return validateProviderSettings(config, config.LLMProvider)
+	}
+
+	for i, entry := range config.LLMChain {
+		if err := validateProviderSettings(config, entry.Provider); err != nil {
+			return fmt.Errorf("llm_chain entry %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// validateProviderSettings validates that the settings needed by an LLM provider are present
+func validateProviderSettings(config *Config, provider string) error {
+	switch provider {
+	case "gemini", "":
+		if config.GeminiAPIKey == "" {
+			return fmt.Errorf("gemini API key is required")
+		}
+	case "ollama":
+		if config.OllamaURL == "" {
+			return fmt.Errorf("ollama_url is required when using the ollama provider")
+		}
+		if config.OllamaModel == "" {
+			return fmt.Errorf("ollama_model is required when using the ollama provider")
+		}
+	case "openai":
+		if config.OpenAIBaseURL == "" {
+			return fmt.Errorf("openai_base_url is required when using the openai provider")
+		}
+		if config.OpenAIModel == "" {
+			return fmt.Errorf("openai_model is required when using the openai provider")
+		}
+	default:
+		return fmt.Errorf("unknown llm_provider %q (expected \"gemini\", \"ollama\" or \"openai\")", provider)
 	}
 	return nil
 }
@@ -179,6 +320,211 @@ func validateWaterQualityLocations(config *Config) error {
 	return nil
 }
 
+// validateEmails validates the email output configurations and applies the default port
+func validateEmails(config *Config) error {
+	for i := range config.Outputs.Emails {
+		email := &config.Outputs.Emails[i]
+		if email.SMTPHost == "" {
+			return fmt.Errorf("email output %d is missing smtp_host", i+1)
+		}
+		if email.From == "" {
+			return fmt.Errorf("email output %d is missing a from address", i+1)
+		}
+		if len(email.To) == 0 {
+			return fmt.Errorf("email output %d has no recipients", i+1)
+		}
+		if email.SMTPPort == 0 {
+			email.SMTPPort = 587
+		}
+	}
+
+	return nil
+}
+
+// validateLLMPrices validates the per-model LLM price configurations
+func validateLLMPrices(config *Config) error {
+	for i, price := range config.LLMPrices {
+		if price.Model == "" {
+			return fmt.Errorf("llm_prices entry %d is missing a model", i+1)
+		}
+		if price.InputPerMillion < 0 || price.OutputPerMillion < 0 {
+			return fmt.Errorf("llm_prices entry %d (%s) has a negative price", i+1, price.Model)
+		}
+	}
+
+	return nil
+}
+
+// validateLLMContext validates the brief context token budgets
+func validateLLMContext(config *Config) error {
+	if config.LLMContextTokens < 0 {
+		return fmt.Errorf("llm_context_tokens must not be negative")
+	}
+
+	for i, budget := range config.LLMContextBudgets {
+		if budget.Model == "" {
+			return fmt.Errorf("llm_context_budgets entry %d is missing a model", i+1)
+		}
+		if budget.Tokens <= 0 {
+			return fmt.Errorf("llm_context_budgets entry %d (%s) must have a positive token budget", i+1, budget.Model)
+		}
+	}
+
+	return nil
+}
+
+// validateLLMTools validates the LLM tool calling configuration
+func validateLLMTools(config *Config) error {
+	if config.LLMTools && config.LLMMaxToolRounds < 1 {
+		return fmt.Errorf("llm_max_tool_rounds must be at least 1, got %d", config.LLMMaxToolRounds)
+	}
+
+	return nil
+}
+
+// Brief verification modes
+const (
+	BriefVerificationOff        = "off"
+	BriefVerificationLog        = "log"
+	BriefVerificationRegenerate = "regenerate"
+)
+
+// validateBriefVerification validates the brief verification mode
+func validateBriefVerification(config *Config) error {
+	switch config.BriefVerification {
+	case BriefVerificationOff, BriefVerificationLog, BriefVerificationRegenerate:
+		return nil
+	default:
+		return fmt.Errorf("brief_verification must be %q, %q or %q, got %q",
+			BriefVerificationOff, BriefVerificationLog, BriefVerificationRegenerate, config.BriefVerification)
+	}
+}
+
+// Trust levels of imported text
+const (
+	// TrustTrusted text is only cleaned of control characters
+	TrustTrusted = "trusted"
+	// TrustUntrusted text is also cleaned of instruction-like patterns
+	TrustUntrusted = "untrusted"
+	// TrustRestricted text is cleaned like untrusted text, and event descriptions and
+	// locations are left out
+	TrustRestricted = "restricted"
+)
+
+// validateSourceTrust validates the per-source trust levels
+func validateSourceTrust(config *Config) error {
+	for i, rule := range config.SourceTrust {
+		if rule.Source == "" {
+			return fmt.Errorf("source_trust entry %d is missing a source", i+1)
+		}
+		switch rule.Trust {
+		case TrustTrusted, TrustUntrusted, TrustRestricted:
+		default:
+			return fmt.Errorf("source_trust entry %d (%s) must have trust %q, %q or %q, got %q",
+				i+1, rule.Source, TrustTrusted, TrustUntrusted, TrustRestricted, rule.Trust)
+		}
+	}
+
+	return nil
+}
+
+// DefaultRetention returns the retention rules used when none are configured: only the
+// newest weather forecast and school lunch menu of each day are kept, since every import
+// adds them again
+func DefaultRetention() []RetentionRule {
+	return []RetentionRule{
+		{Source: "weather-metno", KeepLatestPerDate: true},
+		{Source: "schoollunch", KeepLatestPerDate: true},
+	}
+}
+
+// validateRetention validates the retention rules, normalizing "source:*" to "source"
+// since a source also matches the sources prefixed by it
+func validateRetention(config *Config) error {
+	for i, rule := range config.Retention {
+		rule.Source = strings.TrimSuffix(rule.Source, ":*")
+		if rule.Source == "" || rule.Source == "*" {
+			return fmt.Errorf("retention entry %d is missing a source", i+1)
+		}
+		if rule.MaxAgeDays < 0 {
+			return fmt.Errorf("retention entry %d (%s) must not have a negative max_age_days", i+1, rule.Source)
+		}
+		if rule.MaxAgeDays == 0 && !rule.KeepLatestPerDate {
+			return fmt.Errorf("retention entry %d (%s) must set max_age_days or keep_latest_per_date", i+1, rule.Source)
+		}
+		config.Retention[i] = rule
+	}
+
+	return nil
+}
+
+// validateLLMCache validates the LLM response cache configuration
+func validateLLMCache(config *Config) error {
+	if config.LLMCacheTTLMinutes < 0 {
+		return fmt.Errorf("llm_cache_ttl_minutes must not be negative")
+	}
+
+	return nil
+}
+
+// validateEmbedding validates the semantic memory retrieval configuration
+func validateEmbedding(config *Config) error {
+	switch config.EmbeddingProvider {
+	case "":
+	case "gemini":
+		if config.GeminiAPIKey == "" {
+			return fmt.Errorf("gemini API key is required for gemini embeddings")
+		}
+	case "ollama":
+		if config.OllamaURL == "" {
+			return fmt.Errorf("ollama_url is required for ollama embeddings")
+		}
+	default:
+		return fmt.Errorf("unknown embedding_provider %q (expected \"gemini\", \"ollama\" or empty)", config.EmbeddingProvider)
+	}
+
+	if config.MemoryTopK < 1 {
+		return fmt.Errorf("memory_top_k must be at least 1, got %d", config.MemoryTopK)
+	}
+
+	return nil
+}
+
+// validateChat validates the chat configuration
+func validateChat(config *Config) error {
+	if config.ChatHistoryTurns < 2 {
+		return fmt.Errorf("chat_history_turns must be at least 2, got %d", config.ChatHistoryTurns)
+	}
+
+	return nil
+}
+
+// validateLLMBudget validates the monthly LLM budget configuration
+func validateLLMBudget(config *Config) error {
+	if config.LLMBudgetEURMonthly < 0 {
+		return fmt.Errorf("llm_budget_eur_monthly must not be negative")
+	}
+	if config.LLMBudgetEURMonthly == 0 {
+		return nil
+	}
+
+	if config.LLMBudgetDowngradeRatio <= 0 || config.LLMBudgetDowngradeRatio > 1 {
+		return fmt.Errorf("llm_budget_downgrade_ratio must be between 0 and 1, got %v", config.LLMBudgetDowngradeRatio)
+	}
+
+	if config.LLMBudgetDowngrade.Provider != "" {
+		if err := validateProviderSettings(config, config.LLMBudgetDowngrade.Provider); err != nil {
+			return fmt.Errorf("llm_budget_downgrade: %w", err)
+		}
+	}
+
+	if len(config.LLMPrices) == 0 {
+		slog.Warn("llm_budget_eur_monthly is set but no llm_prices are configured, so every call is estimated to cost nothing")
+	}
+
+	return nil
+}
+
 // validateSchoolLunch validates the school lunch configuration
 func validateSchoolLunch(config *Config) error {
 	// School lunch is optional - no validation needed
@@ -192,10 +538,24 @@ func validateSchoolLunch(config *Config) error {
 // Otherwise, it will search for config.json in the XDG config directory and executable directory
 func InitViper(configFileFlag string) error {
 	// Set default values for fields not expected to be in the config file initially
+	viper.SetDefault("llm_provider", "gemini")
 	viper.SetDefault("gemini_model", "gemini-2.5-flash")
+	viper.SetDefault("ollama_url", "http://localhost:11434")
+	viper.SetDefault("ollama_model", "llama3")
+	viper.SetDefault("openai_base_url", "https://api.openai.com/v1")
+	viper.SetDefault("llm_max_retries", 2)
+	viper.SetDefault("llm_retry_delay_seconds", 2)
+	viper.SetDefault("llm_budget_downgrade_ratio", 0.8)
+	viper.SetDefault("llm_cache_ttl_minutes", 60)
 	viper.SetDefault("output_language", "Finnish")
 	viper.SetDefault("output_format", "cli")
 	viper.SetDefault("days_ahead", 2)
+	viper.SetDefault("chat_history_turns", 12)
+	viper.SetDefault("memory_top_k", 10)
+	viper.SetDefault("llm_max_tool_rounds", 3)
+	viper.SetDefault("brief_verification", BriefVerificationLog)
+	viper.SetDefault("retention", DefaultRetention())
+	viper.SetDefault("auto_prune", true)
 	viper.SetDefault("log_level", "info")
 
 	// Configure environment variable handling
@@ -210,6 +570,27 @@ func InitViper(configFileFlag string) error {
 	if err := viper.BindEnv("gemini_model", "HOVIMESTARI_GEMINI_MODEL"); err != nil {
 		slog.Warn("Failed to bind gemini_model environment variable", "error", err)
 	}
+	if err := viper.BindEnv("llm_provider", "HOVIMESTARI_LLM_PROVIDER"); err != nil {
+		slog.Warn("Failed to bind llm_provider environment variable", "error", err)
+	}
+	if err := viper.BindEnv("ollama_url", "HOVIMESTARI_OLLAMA_URL"); err != nil {
+		slog.Warn("Failed to bind ollama_url environment variable", "error", err)
+	}
+	if err := viper.BindEnv("ollama_model", "HOVIMESTARI_OLLAMA_MODEL"); err != nil {
+		slog.Warn("Failed to bind ollama_model environment variable", "error", err)
+	}
+	if err := viper.BindEnv("openai_base_url", "HOVIMESTARI_OPENAI_BASE_URL"); err != nil {
+		slog.Warn("Failed to bind openai_base_url environment variable", "error", err)
+	}
+	if err := viper.BindEnv("openai_model", "HOVIMESTARI_OPENAI_MODEL"); err != nil {
+		slog.Warn("Failed to bind openai_model environment variable", "error", err)
+	}
+	if err := viper.BindEnv("openai_api_key", "HOVIMESTARI_OPENAI_API_KEY"); err != nil {
+		slog.Warn("Failed to bind openai_api_key environment variable", "error", err)
+	}
+	if err := viper.BindEnv("llm_budget_eur_monthly", "HOVIMESTARI_LLM_BUDGET_EUR_MONTHLY"); err != nil {
+		slog.Warn("Failed to bind llm_budget_eur_monthly environment variable", "error", err)
+	}
 	if err := viper.BindEnv("output_format", "HOVIMESTARI_OUTPUT_FORMAT"); err != nil {
 		slog.Warn("Failed to bind output_format environment variable", "error", err)
 	}
@@ -279,34 +660,6 @@ func InitViper(configFileFlag string) error {
 	return nil
 }
 
-// LoadPrompts loads the prompts from the specified file
-func LoadPrompts(filePath string) (map[string][]string, error) {
-	// If no path is provided, use the default
-	if filePath == "" {
-		filePath = "prompts.json"
-	}
-
-	// Open the file
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open prompts file: %w", err)
-	}
-	defer func() {
-		if err := file.Close(); err != nil {
-			slog.Error("Failed to close prompts file", "error", err)
-		}
-	}()
-
-	// Decode the JSON
-	var prompts map[string][]string
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&prompts); err != nil {
-		return nil, fmt.Errorf("failed to decode prompts file: %w", err)
-	}
-
-	return prompts, nil
-}
-
 // GetConfig returns the configuration from Viper
 // It unmarshals the Viper configuration into a Config struct and resolves file paths
 func GetConfig() (*Config, error) {
@@ -344,28 +697,12 @@ func GetConfig() (*Config, error) {
 		cfg.DBPath = filepath.Join(configFileDir, cfg.DBPath)
 	}
 
-	// Determine the final PromptFilePath
-	if cfg.PromptFilePath == "" {
-		// If PromptFilePath is empty, set it to the default
-		cfg.PromptFilePath = filepath.Join(configDir, "prompts.json")
-	} else if !filepath.IsAbs(cfg.PromptFilePath) && configFileDir != "" {
-		// If PromptFilePath is relative and config was loaded from a file, resolve it relative to the config file
+	// Resolve a relative PromptFilePath relative to the config file. An empty path means
+	// only the embedded defaults and the XDG config directory are used for prompts.
+	if cfg.PromptFilePath != "" && !filepath.IsAbs(cfg.PromptFilePath) && configFileDir != "" {
 		cfg.PromptFilePath = filepath.Join(configFileDir, cfg.PromptFilePath)
 	}
 
-	// Check if prompts.json exists
-	if _, err := os.Stat(cfg.PromptFilePath); os.IsNotExist(err) {
-		// Try to find prompts.json in the standard locations
-		promptsPath, err := xdg.FindConfigFile("prompts.json", "")
-		if err == nil {
-			// Found prompts.json in a standard location, use that
-			cfg.PromptFilePath = promptsPath
-		} else {
-			// prompts.json not found, return an error
-			return nil, fmt.Errorf("prompts file not found at %s or in standard locations ($XDG_CONFIG_HOME/hovimestari/ or executable directory)", cfg.PromptFilePath)
-		}
-	}
-
 	// Validate the configuration
 	if err := validateRequiredFields(cfg); err != nil {
 		configSource := "environment variables"
@@ -395,8 +732,52 @@ func GetConfig() (*Config, error) {
 		return nil, err
 	}
 
+	if err := validateEmails(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateLLMPrices(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateLLMBudget(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateLLMCache(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateChat(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateLLMContext(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateLLMTools(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateEmbedding(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateBriefVerification(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateSourceTrust(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateRetention(cfg); err != nil {
+		return nil, err
+	}
+
 	// Set default values for Outputs if not specified
-	if !cfg.Outputs.EnableCLI && len(cfg.Outputs.DiscordWebhookURLs) == 0 && len(cfg.Outputs.TelegramBots) == 0 {
+	if !cfg.Outputs.EnableCLI && !cfg.Outputs.HasChannels() {
 		// If no outputs are configured, use the legacy OutputFormat field
 		if cfg.OutputFormat == "cli" || cfg.OutputFormat == "" {
 			cfg.Outputs.EnableCLI = true
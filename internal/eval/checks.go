@@ -0,0 +1,150 @@
+package eval
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/output"
+)
+
+// minSummaryWordLength is the shortest word of an event summary that counts as a mention,
+// shorter words like "at" or "ja" appear everywhere
+const minSummaryWordLength = 4
+
+// languageWords are common words used to tell the languages of the briefs apart
+var languageWords = map[string][]string{
+	"English": {"the", "and", "is", "are", "you", "your", "today", "tomorrow", "with", "for", "will", "good", "morning", "have", "weather"},
+	"Finnish": {"ja", "on", "tänään", "huomenna", "että", "klo", "hyvää", "huomenta", "sää", "päivä", "lämpötila", "kanssa", "myös", "teidän", "ovat"},
+	"Swedish": {"och", "är", "idag", "imorgon", "det", "att", "med", "för", "god", "morgon", "vädret", "inte", "som", "har", "ni"},
+}
+
+// Check is the result of a single automatic check of a brief
+type Check struct {
+	Name   string
+	Passed bool
+	Detail string
+}
+
+// CheckBrief runs the automatic checks on the brief generated from the context: it
+// mentions every calendar event, stays within maxLength characters (unless 0) and is
+// written in the context's language
+func CheckBrief(brief *output.Brief, bc *llm.BriefContext, maxLength int) []Check {
+	text := brief.RenderText()
+
+	checks := []Check{checkEvents(text, bc)}
+	if maxLength > 0 {
+		checks = append(checks, checkLength(text, maxLength))
+	}
+	return append(checks, checkLanguage(text, bc.Language))
+}
+
+// checkEvents checks that the text mentions every calendar event of the brief days
+func checkEvents(text string, bc *llm.BriefContext) Check {
+	var missing []string
+	total := 0
+	for _, day := range bc.Days {
+		for _, event := range day.Events {
+			total++
+			if !mentionsEvent(text, event) {
+				missing = append(missing, event.Summary)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return Check{Name: "events", Detail: "missing " + strings.Join(missing, ", ")}
+	}
+	return Check{Name: "events", Passed: true, Detail: fmt.Sprintf("%d mentioned", total)}
+}
+
+// mentionsEvent reports whether the text mentions the event by its start time or by a
+// word of its summary. Words are enough since the model may rephrase or translate it.
+func mentionsEvent(text string, event llm.BriefEvent) bool {
+	if !event.AllDay() && containsTime(text, event.Start) {
+		return true
+	}
+
+	lower := strings.ToLower(text)
+	return slices.ContainsFunc(words(event.Summary), func(word string) bool {
+		return utf8.RuneCountInString(word) >= minSummaryWordLength && strings.Contains(lower, word)
+	})
+}
+
+// containsTime reports whether the text contains the clock time as 9:30, 09:30 or 9.30
+func containsTime(text string, t time.Time) bool {
+	pattern := regexp.MustCompile(fmt.Sprintf(`(^|[^0-9])0?%d[:.]%02d`, t.Hour(), t.Minute()))
+	return pattern.MatchString(text)
+}
+
+// checkLength checks that the text isn't longer than maxLength characters
+func checkLength(text string, maxLength int) Check {
+	length := utf8.RuneCountInString(text)
+	return Check{
+		Name:   "length",
+		Passed: length <= maxLength,
+		Detail: fmt.Sprintf("%d/%d characters", length, maxLength),
+	}
+}
+
+// checkLanguage checks that the text is written in the expected language. Languages
+// without a word list aren't checked.
+func checkLanguage(text, language string) Check {
+	if _, ok := languageWords[language]; !ok {
+		return Check{Name: "language", Passed: true, Detail: "not checked for " + language}
+	}
+
+	detected := DetectLanguage(text)
+	if detected != language {
+		return Check{Name: "language", Detail: fmt.Sprintf("looks like %s, expected %s", detectedName(detected), language)}
+	}
+	return Check{Name: "language", Passed: true, Detail: language}
+}
+
+// DetectLanguage returns the language of the text by counting common words, or an empty
+// string if it's unclear
+func DetectLanguage(text string) string {
+	counts := make(map[string]int)
+	for _, word := range words(text) {
+		for language, common := range languageWords {
+			if slices.Contains(common, word) {
+				counts[language]++
+			}
+		}
+	}
+
+	best, bestCount, tie := "", 0, false
+	for language, count := range counts {
+		switch {
+		case count > bestCount:
+			best, bestCount, tie = language, count, false
+		case count == bestCount:
+			tie = true
+		}
+	}
+
+	if tie {
+		return ""
+	}
+	return best
+}
+
+// detectedName describes the detected language for check details
+func detectedName(language string) string {
+	if language == "" {
+		return "an unknown language"
+	}
+	return language
+}
+
+// words splits the text into lowercase words
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,96 @@
+package eval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/output"
+)
+
+func TestCheckBrief(t *testing.T) {
+	bc := llm.SampleBriefContext()
+
+	tests := []struct {
+		name      string
+		text      string
+		maxLength int
+		failed    []string
+	}{
+		{
+			name:      "good brief",
+			text:      "Good morning! The dentist is at the clinic today, and the weather will be partly cloudy.",
+			maxLength: 200,
+		},
+		{
+			name:      "missing event",
+			text:      "Good morning! Today the weather will be partly cloudy.",
+			maxLength: 200,
+			failed:    []string{"events"},
+		},
+		{
+			name:      "too long",
+			text:      "Good morning! Dentist at 09:00, and the weather will be partly cloudy.",
+			maxLength: 20,
+			failed:    []string{"length"},
+		},
+		{
+			name:   "wrong language",
+			text:   "Hyvää huomenta! Tänään klo 09:00 on hammaslääkäri ja sää on puolipilvinen.",
+			failed: []string{"language"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var failed []string
+			for _, check := range CheckBrief(output.PlainBrief(test.text), bc, test.maxLength) {
+				if !check.Passed {
+					failed = append(failed, check.Name)
+				}
+			}
+			if len(failed) != len(test.failed) || (len(failed) > 0 && failed[0] != test.failed[0]) {
+				t.Errorf("failed checks = %v, expected %v", failed, test.failed)
+			}
+		})
+	}
+}
+
+func TestMentionsEvent(t *testing.T) {
+	start := time.Date(2025, 4, 18, 9, 30, 0, 0, time.UTC)
+	event := llm.BriefEvent{Summary: "Parents' evening", Start: start}
+
+	tests := []struct {
+		text     string
+		expected bool
+	}{
+		{"Parents' evening at the school", true},
+		{"Vanhempainilta klo 9.30", true},
+		{"School event at 09:30", true},
+		{"Nothing special today", false},
+	}
+
+	for _, test := range tests {
+		if got := mentionsEvent(test.text, event); got != test.expected {
+			t.Errorf("mentionsEvent(%q) = %v, expected %v", test.text, got, test.expected)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"Good morning! Today the weather is sunny and you have a dentist appointment.", "English"},
+		{"Hyvää huomenta! Tänään sää on aurinkoinen ja klo 9 on hammaslääkäri.", "Finnish"},
+		{"God morgon! Idag är vädret soligt och det är tandläkare.", "Swedish"},
+		{"12:00 🦷", ""},
+	}
+
+	for _, test := range tests {
+		if got := DetectLanguage(test.text); got != test.expected {
+			t.Errorf("DetectLanguage(%q) = %q, expected %q", test.text, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Package eval runs dailyBrief prompt variants against saved brief contexts and checks
+// the results, so prompts can be tuned without sending briefs to the family.
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+	"github.com/lepinkainen/hovimestari/internal/output"
+)
+
+// Fixture is a saved brief context to evaluate prompts against, e.g. the output of
+// show-brief-context --json
+type Fixture struct {
+	Name    string
+	Context *llm.BriefContext
+}
+
+// LoadFixtures loads the brief contexts from the JSON files in the directory, sorted by
+// file name. The fixture name is the file name without the extension.
+func LoadFixtures(dir string) ([]Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fixtures: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no fixtures (*.json) found in %s", dir)
+	}
+	slices.Sort(paths)
+
+	fixtures := make([]Fixture, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture: %w", err)
+		}
+
+		var bc llm.BriefContext
+		if err := json.Unmarshal(data, &bc); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+		}
+
+		fixtures = append(fixtures, Fixture{
+			Name:    strings.TrimSuffix(filepath.Base(path), ".json"),
+			Context: &bc,
+		})
+	}
+
+	return fixtures, nil
+}
+
+// Result is the output of a single prompt variant for a single fixture
+type Result struct {
+	Fixture string
+	Variant string
+	// Response is the raw response of the LLM
+	Response string
+	// Brief is the parsed brief, nil if generating or parsing failed
+	Brief *output.Brief
+	// Err is the error from building the prompt, generating or parsing the brief
+	Err    error
+	Checks []Check
+}
+
+// Passed reports whether the brief was generated and passed every check
+func (r Result) Passed() bool {
+	if r.Err != nil {
+		return false
+	}
+	for _, check := range r.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Runner generates briefs with prompt variants and checks them
+type Runner struct {
+	provider  llm.Provider
+	prompts   map[string][]string
+	maxLength int
+}
+
+// NewRunner creates a runner generating with the provider. Briefs longer than maxLength
+// characters fail the length check, 0 disables it.
+func NewRunner(provider llm.Provider, prompts map[string][]string, maxLength int) *Runner {
+	return &Runner{
+		provider:  provider,
+		prompts:   prompts,
+		maxLength: maxLength,
+	}
+}
+
+// Run generates a brief for each fixture with each variant, in order. A failure is
+// recorded in the result and doesn't stop the run.
+func (r *Runner) Run(ctx context.Context, fixtures []Fixture, variants []string) []Result {
+	var results []Result
+	for _, fixture := range fixtures {
+		for _, variant := range variants {
+			results = append(results, r.run(ctx, fixture, variant))
+		}
+	}
+	return results
+}
+
+// run generates and checks the brief for a single fixture and variant
+func (r *Runner) run(ctx context.Context, fixture Fixture, variant string) Result {
+	result := Result{Fixture: fixture.Name, Variant: variant}
+
+	promptContent, err := llm.BuildBriefPromptVariant(r.prompts, variant, fixture.Context)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Response, err = r.provider.Generate(ctx, variant, fixture.Context.Language, promptContent)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to generate brief: %w", err)
+		return result
+	}
+
+	result.Brief, err = output.ParseBrief(result.Response)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Checks = CheckBrief(result.Brief, fixture.Context, r.maxLength)
+	return result
+}
+
+// WriteResults writes a markdown file for each fixture with the briefs of every variant
+// one after the other, and summary.md with the checks of every result
+func WriteResults(dir string, results []Result) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	var fixtures []string
+	byFixture := make(map[string][]Result)
+	for _, result := range results {
+		if _, ok := byFixture[result.Fixture]; !ok {
+			fixtures = append(fixtures, result.Fixture)
+		}
+		byFixture[result.Fixture] = append(byFixture[result.Fixture], result)
+	}
+
+	for _, fixture := range fixtures {
+		path := filepath.Join(dir, fixture+".md")
+		if err := os.WriteFile(path, []byte(formatFixtureResults(fixture, byFixture[fixture])), 0o644); err != nil {
+			return fmt.Errorf("failed to write results: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "summary.md"), []byte(FormatSummary(results)), 0o644); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+
+	return nil
+}
+
+// formatFixtureResults formats the results of every variant for a single fixture
+func formatFixtureResults(fixture string, results []Result) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "# %s\n", fixture)
+
+	for _, result := range results {
+		fmt.Fprintf(&builder, "\n## %s\n\n", result.Variant)
+
+		if result.Err != nil {
+			fmt.Fprintf(&builder, "**Error:** %v\n", result.Err)
+			if result.Response != "" {
+				fmt.Fprintf(&builder, "\n```\n%s\n```\n", result.Response)
+			}
+			continue
+		}
+
+		for _, check := range result.Checks {
+			fmt.Fprintf(&builder, "- %s\n", formatCheck(check))
+		}
+		fmt.Fprintf(&builder, "\n%s\n", result.Brief.RenderMarkdown())
+	}
+
+	return builder.String()
+}
+
+// FormatSummary formats the results as a markdown table with a row per fixture and
+// variant
+func FormatSummary(results []Result) string {
+	var builder strings.Builder
+	builder.WriteString("| Fixture | Variant | Result | Checks |\n")
+	builder.WriteString("|---------|---------|--------|--------|\n")
+
+	for _, result := range results {
+		status := "pass"
+		if !result.Passed() {
+			status = "FAIL"
+		}
+
+		var details []string
+		if result.Err != nil {
+			details = append(details, result.Err.Error())
+		}
+		for _, check := range result.Checks {
+			details = append(details, formatCheck(check))
+		}
+
+		detail := strings.ReplaceAll(strings.Join(details, "; "), "|", "\\|")
+		fmt.Fprintf(&builder, "| %s | %s | %s | %s |\n", result.Fixture, result.Variant, status, detail)
+	}
+
+	return builder.String()
+}
+
+// formatCheck formats a check as a single line
+func formatCheck(check Check) string {
+	mark := "ok"
+	if !check.Passed {
+		mark = "FAILED"
+	}
+	if check.Detail == "" {
+		return fmt.Sprintf("%s: %s", check.Name, mark)
+	}
+	return fmt.Sprintf("%s: %s (%s)", check.Name, mark, check.Detail)
+}
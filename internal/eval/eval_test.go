@@ -0,0 +1,92 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lepinkainen/hovimestari/internal/llm"
+)
+
+func TestLoadFixtures(t *testing.T) {
+	dir := t.TempDir()
+
+	data, err := json.Marshal(llm.SampleBriefContext())
+	if err != nil {
+		t.Fatalf("failed to marshal context: %v", err)
+	}
+	for _, name := range []string{"weekday.json", "birthday.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatalf("failed to write fixture: %v", err)
+		}
+	}
+
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		t.Fatalf("LoadFixtures() error = %v", err)
+	}
+	if len(fixtures) != 2 || fixtures[0].Name != "birthday" || fixtures[1].Name != "weekday" {
+		t.Fatalf("unexpected fixtures: %+v", fixtures)
+	}
+	if len(fixtures[0].Context.Days) != 2 || fixtures[0].Context.Days[0].Events[0].Summary != "Dentist" {
+		t.Errorf("fixture context not loaded: %+v", fixtures[0].Context)
+	}
+
+	if _, err := LoadFixtures(t.TempDir()); err == nil {
+		t.Error("LoadFixtures() expected an error for a directory without fixtures")
+	}
+}
+
+func TestRunnerRun(t *testing.T) {
+	prompts := map[string][]string{
+		"dailyBrief":         {"Brief in {{.Lang}}: {{.Notes}}"},
+		"dailyBrief:concise": {"Short brief in {{.Lang}}: {{.Notes}}"},
+	}
+	provider := &llm.ScriptedProvider{Responses: []string{
+		`{"greeting": "Good morning!", "sections": [{"key": "events", "title": "Today", "items": ["Dentist at 09:00 at the clinic"]}], "closing": "Have a good day and enjoy the weather."}`,
+		`{"greeting": "Good morning!"`,
+	}}
+
+	fixtures := []Fixture{{Name: "weekday", Context: llm.SampleBriefContext()}}
+	results := NewRunner(provider, prompts, 500).Run(context.Background(), fixtures, []string{"dailyBrief", "dailyBrief:concise"})
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if !strings.HasPrefix(provider.Prompts[1], "Short brief in English") {
+		t.Errorf("expected the variant prompt, got %q", provider.Prompts[1])
+	}
+
+	if !results[0].Passed() {
+		t.Errorf("expected the first result to pass, got %+v", results[0])
+	}
+	if results[1].Err == nil || results[1].Passed() {
+		t.Errorf("expected the invalid JSON brief to fail, got %+v", results[1])
+	}
+
+	dir := filepath.Join(t.TempDir(), "out")
+	if err := WriteResults(dir, results); err != nil {
+		t.Fatalf("WriteResults() error = %v", err)
+	}
+
+	written, err := os.ReadFile(filepath.Join(dir, "weekday.md"))
+	if err != nil {
+		t.Fatalf("failed to read results: %v", err)
+	}
+	for _, want := range []string{"## dailyBrief\n", "Dentist at 09:00", "## dailyBrief:concise\n", "**Error:**"} {
+		if !strings.Contains(string(written), want) {
+			t.Errorf("results missing %q:\n%s", want, written)
+		}
+	}
+
+	summary, err := os.ReadFile(filepath.Join(dir, "summary.md"))
+	if err != nil {
+		t.Fatalf("failed to read summary: %v", err)
+	}
+	if !strings.Contains(string(summary), "| weekday | dailyBrief | pass |") || !strings.Contains(string(summary), "| weekday | dailyBrief:concise | FAIL |") {
+		t.Errorf("unexpected summary:\n%s", summary)
+	}
+}
@@ -77,12 +77,12 @@ func GetLatestForecasts(s *store.Store, startDate, endDate time.Time, location s
 
 	for _, memory := range memories {
 		// Skip non-weather memories or memories for other locations
-		if memory.Source != source || memory.RelevanceDate == nil {
+		if memory.Source != source || memory.RelevanceStart == nil {
 			continue
 		}
 
 		// Get the date as a string (YYYY-MM-DD)
-		dateStr := memory.RelevanceDate.Format("2006-01-02")
+		dateStr := memory.RelevanceStart.Format("2006-01-02")
 
 		// Check if we already have a forecast for this date
 		existing, exists := latestForecasts[dateStr]
@@ -0,0 +1,69 @@
+package llm
+
+import "time"
+
+// BriefContext is the structured context a daily brief is generated from. It's filled in
+// by the brief package's context providers and consumed by the prompt builders and the
+// template-based brief.
+type BriefContext struct {
+	// Now is the time the context was built, in the configured timezone
+	Now       time.Time    `json:"now"`
+	Timezone  string       `json:"timezone"`
+	Location  string       `json:"location,omitempty"`
+	Language  string       `json:"language"`
+	Family    []string     `json:"family,omitempty"`
+	Birthdays []Birthday   `json:"birthdays,omitempty"`
+	Ongoing   []BriefEvent `json:"ongoing,omitempty"`
+	Days      []BriefDay   `json:"days"`
+	// Notes are memories that aren't tied to a specific day
+	Notes []string `json:"notes,omitempty"`
+}
+
+// BriefDay holds everything known about a single day in the brief
+type BriefDay struct {
+	Date    time.Time `json:"date"`
+	Weather string    `json:"weather,omitempty"`
+	// HourlyForecast is only available for the current day
+	HourlyForecast string       `json:"hourly_forecast,omitempty"`
+	Events         []BriefEvent `json:"events,omitempty"`
+	Meals          []string     `json:"meals,omitempty"`
+	Prices         string       `json:"prices,omitempty"`
+	Notes          []string     `json:"notes,omitempty"`
+}
+
+// BriefEvent is a calendar event in the brief
+type BriefEvent struct {
+	Summary     string     `json:"summary"`
+	Start       time.Time  `json:"start"`
+	End         *time.Time `json:"end,omitempty"`
+	Location    string     `json:"location,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Source      string     `json:"source"`
+}
+
+// AllDay reports whether the event starts at midnight and lasts whole days
+func (e BriefEvent) AllDay() bool {
+	if e.End == nil || e.Start.Hour() != 0 || e.Start.Minute() != 0 {
+		return false
+	}
+	duration := e.End.Sub(e.Start)
+	return duration > 0 && duration%(24*time.Hour) == 0
+}
+
+// Birthday is a family member's birthday on the brief date
+type Birthday struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+// Day returns the day in the brief matching the given date, or nil if the date is
+// outside the days covered by the brief
+func (bc *BriefContext) Day(date time.Time) *BriefDay {
+	key := date.Format("2006-01-02")
+	for i := range bc.Days {
+		if bc.Days[i].Date.Format("2006-01-02") == key {
+			return &bc.Days[i]
+		}
+	}
+	return nil
+}
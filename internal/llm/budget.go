@@ -0,0 +1,165 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+)
+
+// BudgetExceededError is returned instead of calling the LLM when the monthly budget
+// has been used up
+type BudgetExceededError struct {
+	Spent  float64 // Estimated spend this month in euros
+	Budget float64 // Monthly budget in euros
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("monthly LLM budget exceeded: spent %.2f of %.2f EUR", e.Spent, e.Budget)
+}
+
+// SpendStore provides the estimated LLM spend recorded in the call ledger
+type SpendStore interface {
+	GetLLMCostSince(since time.Time) (float64, error)
+}
+
+// Budget is a Provider that enforces a monthly spend cap. When the spend this month
+// reaches the downgrade ratio of the budget, calls go to the cheaper downgrade provider;
+// once the budget is used up, calls fail with a BudgetExceededError.
+type Budget struct {
+	provider       Provider
+	downgrade      Provider // optional, nil if no downgrade model is configured
+	spend          SpendStore
+	monthlyBudget  float64
+	downgradeRatio float64
+	active         Provider // provider that answered last
+	now            func() time.Time
+}
+
+// NewBudget creates a new budget-enforcing provider wrapping provider
+func NewBudget(provider, downgrade Provider, spend SpendStore, monthlyBudget, downgradeRatio float64) *Budget {
+	return &Budget{
+		provider:       provider,
+		downgrade:      downgrade,
+		spend:          spend,
+		monthlyBudget:  monthlyBudget,
+		downgradeRatio: downgradeRatio,
+		active:         provider,
+		now:            time.Now,
+	}
+}
+
+// WithBudget wraps provider with the monthly budget from the configuration. If no budget
+// is configured, provider is returned as is.
+func WithBudget(cfg *config.Config, prompts map[string][]string, provider Provider, spend SpendStore) (Provider, error) {
+	if cfg.LLMBudgetEURMonthly <= 0 {
+		return provider, nil
+	}
+
+	var downgrade Provider
+	if cfg.LLMBudgetDowngrade.Provider != "" {
+		single, err := newSingleProvider(cfg, cfg.LLMBudgetDowngrade, prompts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create downgrade LLM client: %w", err)
+		}
+		retryDelay := time.Duration(cfg.LLMRetryDelaySeconds) * time.Second
+		downgrade = NewChain([]Provider{single}, cfg.LLMMaxRetries, retryDelay)
+	}
+
+	return NewBudget(provider, downgrade, spend, cfg.LLMBudgetEURMonthly, cfg.LLMBudgetDowngradeRatio), nil
+}
+
+// Name returns the identifier of the provider that answered last
+func (b *Budget) Name() string {
+	return b.active.Name()
+}
+
+// Model returns the model that answered last
+func (b *Budget) Model() string {
+	return b.active.Model()
+}
+
+// SetRecorder sets the recorder on both the regular and the downgrade provider
+func (b *Budget) SetRecorder(recorder CallRecorder) {
+	b.provider.SetRecorder(recorder)
+	if b.downgrade != nil {
+		b.downgrade.SetRecorder(recorder)
+	}
+}
+
+// SetCache sets the cache on both the regular and the downgrade provider
+func (b *Budget) SetCache(cache ResponseCache) {
+	b.provider.SetCache(cache)
+	if b.downgrade != nil {
+		b.downgrade.SetCache(cache)
+	}
+}
+
+// Close closes both the regular and the downgrade provider
+func (b *Budget) Close() error {
+	err := b.provider.Close()
+	if b.downgrade != nil {
+		err = errors.Join(err, b.downgrade.Close())
+	}
+	return err
+}
+
+// Generate generates content with the provider allowed by the budget
+func (b *Budget) Generate(ctx context.Context, promptKey string, outputLanguage string, promptContent string) (string, error) {
+	provider, err := b.selectProvider(promptKey)
+	if err != nil {
+		return "", err
+	}
+	return provider.Generate(ctx, promptKey, outputLanguage, promptContent)
+}
+
+// GenerateBrief generates a brief with the provider allowed by the budget
+func (b *Budget) GenerateBrief(ctx context.Context, bc *BriefContext) (string, error) {
+	provider, err := b.selectProvider("dailyBrief")
+	if err != nil {
+		return "", err
+	}
+	return provider.GenerateBrief(ctx, bc)
+}
+
+// GenerateResponse generates a response to a user query with the provider allowed by the budget
+func (b *Budget) GenerateResponse(ctx context.Context, query string, memories []string, outputLanguage string) (string, error) {
+	provider, err := b.selectProvider("userQuery")
+	if err != nil {
+		return "", err
+	}
+	return provider.GenerateResponse(ctx, query, memories, outputLanguage)
+}
+
+// ListModels lists the models available from the regular provider
+func (b *Budget) ListModels(ctx context.Context) ([]string, error) {
+	return b.provider.ListModels(ctx)
+}
+
+// selectProvider picks the provider to use based on the spend this month. If the spend
+// can't be read, the call is refused rather than risking an unbounded bill.
+func (b *Budget) selectProvider(promptKey string) (Provider, error) {
+	now := b.now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	spent, err := b.spend.GetLLMCostSince(monthStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check LLM budget: %w", err)
+	}
+
+	if spent >= b.monthlyBudget {
+		return nil, &BudgetExceededError{Spent: spent, Budget: b.monthlyBudget}
+	}
+
+	b.active = b.provider
+	if b.downgrade != nil && spent >= b.monthlyBudget*b.downgradeRatio {
+		slog.Warn("LLM spend is nearing the monthly budget, using the downgrade model",
+			"prompt", promptKey, "spent", spent, "budget", b.monthlyBudget, "model", b.downgrade.Model())
+		b.active = b.downgrade
+	}
+
+	return b.active, nil
+}
@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeSpendStore returns a fixed spend for the month
+type fakeSpendStore struct {
+	spent float64
+	err   error
+	since time.Time
+}
+
+func (f *fakeSpendStore) GetLLMCostSince(since time.Time) (float64, error) {
+	f.since = since
+	return f.spent, f.err
+}
+
+func TestBudgetGenerate(t *testing.T) {
+	tests := []struct {
+		name          string
+		spent         float64
+		withDowngrade bool
+		wantAnswer    string
+		wantExceeded  bool
+	}{
+		{name: "under budget", spent: 1, withDowngrade: true, wantAnswer: "regular"},
+		{name: "nearing budget", spent: 8.5, withDowngrade: true, wantAnswer: "cheap"},
+		{name: "nearing budget without downgrade", spent: 8.5, wantAnswer: "regular"},
+		{name: "over budget", spent: 10, withDowngrade: true, wantExceeded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regular := &fakeProvider{name: "gemini", model: "gemini-2.5-pro", answer: "regular"}
+			var downgrade Provider
+			if tt.withDowngrade {
+				downgrade = &fakeProvider{name: "gemini", model: "gemini-2.5-flash-lite", answer: "cheap"}
+			}
+
+			spend := &fakeSpendStore{spent: tt.spent}
+			budget := NewBudget(regular, downgrade, spend, 10, 0.8)
+			budget.now = func() time.Time { return time.Date(2025, 4, 18, 7, 30, 0, 0, time.UTC) }
+
+			got, err := budget.Generate(context.Background(), "dailyBrief", "Finnish", "prompt")
+
+			if tt.wantExceeded {
+				budgetErr, ok := errors.AsType[*BudgetExceededError](err)
+				if !ok {
+					t.Fatalf("Generate() error = %v, expected a BudgetExceededError", err)
+				}
+				if budgetErr.Spent != tt.spent || budgetErr.Budget != 10 {
+					t.Errorf("unexpected BudgetExceededError: %+v", budgetErr)
+				}
+				if regular.calls != 0 {
+					t.Errorf("expected no LLM calls over budget, got %d", regular.calls)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Generate() returned an error: %v", err)
+			}
+			if got != tt.wantAnswer {
+				t.Errorf("Generate() = %q, expected %q", got, tt.wantAnswer)
+			}
+			if wantStart := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC); !spend.since.Equal(wantStart) {
+				t.Errorf("spend queried since %v, expected %v", spend.since, wantStart)
+			}
+		})
+	}
+}
+
+func TestBudgetSpendError(t *testing.T) {
+	regular := &fakeProvider{name: "gemini", model: "gemini-2.5-pro", answer: "regular"}
+	budget := NewBudget(regular, nil, &fakeSpendStore{err: errors.New("database is locked")}, 10, 0.8)
+
+	if _, err := budget.Generate(context.Background(), "dailyBrief", "Finnish", "prompt"); err == nil {
+		t.Error("Generate() expected an error when the spend can't be read")
+	}
+	if regular.calls != 0 {
+		t.Errorf("expected no LLM calls when the spend can't be read, got %d", regular.calls)
+	}
+}
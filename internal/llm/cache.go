@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ResponseCache stores generated responses so an identical prompt isn't sent to the same
+// model twice
+type ResponseCache interface {
+	Get(ctx context.Context, provider, model, promptKey, promptHash string) (string, bool)
+	Put(ctx context.Context, provider, model, promptKey, promptHash, response string)
+}
+
+// CacheStore is the storage used by the response cache
+type CacheStore interface {
+	GetCachedResponse(provider, model, promptHash string, notBefore time.Time) (string, bool, error)
+	PutCachedResponse(provider, model, promptHash, response string) error
+	DeleteCachedResponsesBefore(before time.Time) (int64, error)
+}
+
+// Cache is a ResponseCache backed by the llm_cache table. Only the responses to the
+// prompt keys it was created for are cached. New responses are held back until Commit,
+// so a response the caller rejects, like a brief that can't be parsed, is never reused.
+// Responses older than the TTL are ignored and removed when new responses are stored.
+type Cache struct {
+	store      CacheStore
+	ttl        time.Duration
+	fresh      bool
+	promptKeys []string
+	pending    []cachedResponse
+	now        func() time.Time
+}
+
+// cachedResponse is a response waiting to be committed to the cache
+type cachedResponse struct {
+	provider   string
+	model      string
+	promptHash string
+	response   string
+}
+
+// NewCache creates a new response cache for the prompt keys. If fresh is set, cached
+// responses are never used but new responses are still stored.
+func NewCache(store CacheStore, ttl time.Duration, fresh bool, promptKeys ...string) *Cache {
+	return &Cache{
+		store:      store,
+		ttl:        ttl,
+		fresh:      fresh,
+		promptKeys: promptKeys,
+		now:        time.Now,
+	}
+}
+
+// Get returns the cached response for the prompt, if there's one younger than the TTL.
+// Lookup failures are logged and treated as a cache miss.
+func (c *Cache) Get(ctx context.Context, provider, model, promptKey, promptHash string) (string, bool) {
+	if c.fresh || !slices.Contains(c.promptKeys, promptKey) {
+		return "", false
+	}
+
+	response, ok, err := c.store.GetCachedResponse(provider, model, promptHash, c.now().Add(-c.ttl))
+	if err != nil {
+		slog.Warn("Failed to look up cached LLM response", "provider", provider, "model", model, "error", err)
+		return "", false
+	}
+
+	return response, ok
+}
+
+// Put holds the response for the prompt back until Commit
+func (c *Cache) Put(ctx context.Context, provider, model, promptKey, promptHash, response string) {
+	if !slices.Contains(c.promptKeys, promptKey) {
+		return
+	}
+
+	c.pending = append(c.pending, cachedResponse{provider: provider, model: model, promptHash: promptHash, response: response})
+}
+
+// Commit stores the responses held back since the last Commit or Discard and removes
+// expired responses. Failures are only logged since a missing cache entry just means
+// another LLM call later.
+func (c *Cache) Commit() {
+	pending := c.pending
+	c.pending = nil
+	if len(pending) == 0 {
+		return
+	}
+
+	for _, p := range pending {
+		if err := c.store.PutCachedResponse(p.provider, p.model, p.promptHash, p.response); err != nil {
+			slog.Warn("Failed to cache LLM response", "provider", p.provider, "model", p.model, "error", err)
+		}
+	}
+
+	if _, err := c.store.DeleteCachedResponsesBefore(c.now().Add(-c.ttl)); err != nil {
+		slog.Warn("Failed to remove expired LLM responses", "error", err)
+	}
+}
+
+// Discard drops the responses held back since the last Commit or Discard
+func (c *Cache) Discard() {
+	c.pending = nil
+}
+
+// cacheKey returns the hash a response to the prompt is cached under. Only the hour of
+// the current time line of a brief prompt is kept, so a rerun a few minutes later is
+// still answered from the cache while the model always sees the actual time, and a
+// greeting for the morning isn't replayed in the evening.
+func cacheKey(promptContent string) string {
+	lines := strings.Split(promptContent, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if clock, ok := strings.CutPrefix(trimmed, currentTimeLabel); ok {
+			hour, _, _ := strings.Cut(clock, ":")
+			lines[i] = currentTimeLabel + hour
+		}
+	}
+	return PromptHash(strings.Join(lines, "\n"))
+}
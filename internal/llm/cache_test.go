@@ -0,0 +1,167 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeCacheStore is an in-memory CacheStore
+type fakeCacheStore struct {
+	entries map[string]fakeCacheEntry
+	now     time.Time
+}
+
+type fakeCacheEntry struct {
+	response  string
+	createdAt time.Time
+}
+
+func newFakeCacheStore(now time.Time) *fakeCacheStore {
+	return &fakeCacheStore{entries: map[string]fakeCacheEntry{}, now: now}
+}
+
+func (f *fakeCacheStore) GetCachedResponse(provider, model, promptHash string, notBefore time.Time) (string, bool, error) {
+	entry, ok := f.entries[provider+"/"+model+"/"+promptHash]
+	if !ok || entry.createdAt.Before(notBefore) {
+		return "", false, nil
+	}
+	return entry.response, true, nil
+}
+
+func (f *fakeCacheStore) PutCachedResponse(provider, model, promptHash, response string) error {
+	f.entries[provider+"/"+model+"/"+promptHash] = fakeCacheEntry{response: response, createdAt: f.now}
+	return nil
+}
+
+func (f *fakeCacheStore) DeleteCachedResponsesBefore(before time.Time) (int64, error) {
+	var deleted int64
+	for key, entry := range f.entries {
+		if entry.createdAt.Before(before) {
+			delete(f.entries, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func TestCacheGet(t *testing.T) {
+	now := time.Date(2025, 4, 18, 7, 30, 0, 0, time.UTC)
+	store := newFakeCacheStore(now.Add(-30 * time.Minute))
+	_ = store.PutCachedResponse("gemini", "gemini-2.5-flash", "abc", "cached brief")
+
+	tests := []struct {
+		name      string
+		model     string
+		promptKey string
+		ttl       time.Duration
+		fresh     bool
+		wantOK    bool
+	}{
+		{name: "hit within TTL", model: "gemini-2.5-flash", promptKey: "dailyBrief", ttl: time.Hour, wantOK: true},
+		{name: "expired", model: "gemini-2.5-flash", promptKey: "dailyBrief", ttl: 15 * time.Minute},
+		{name: "fresh bypasses cache", model: "gemini-2.5-flash", promptKey: "dailyBrief", ttl: time.Hour, fresh: true},
+		{name: "other model", model: "gemini-2.5-pro", promptKey: "dailyBrief", ttl: time.Hour},
+		{name: "prompt key not cached", model: "gemini-2.5-flash", promptKey: "chat", ttl: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewCache(store, tt.ttl, tt.fresh, "dailyBrief")
+			cache.now = func() time.Time { return now }
+
+			got, ok := cache.Get(context.Background(), "gemini", tt.model, tt.promptKey, "abc")
+			if ok != tt.wantOK {
+				t.Fatalf("Get() ok = %v, expected %v", ok, tt.wantOK)
+			}
+			if ok && got != "cached brief" {
+				t.Errorf("Get() = %q, expected %q", got, "cached brief")
+			}
+		})
+	}
+}
+
+func TestGenerateUsesCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"model":"llama3","response":"Hei!","done":true}`))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	collector := &callCollector{}
+	client := NewOllamaClient(server.URL, "llama3", nil)
+	client.SetRecorder(collector)
+	cache := NewCache(newFakeCacheStore(now), time.Hour, false, "dailyBrief")
+	client.SetCache(cache)
+
+	generate := func(promptKey, prompt string) {
+		t.Helper()
+		got, err := client.Generate(context.Background(), promptKey, "Finnish", prompt)
+		if err != nil {
+			t.Fatalf("Generate() returned an error: %v", err)
+		}
+		if got != "Hei!" {
+			t.Errorf("Generate() = %q, expected %q", got, "Hei!")
+		}
+	}
+
+	// A response that wasn't committed isn't reused
+	generate("dailyBrief", "same prompt")
+	cache.Discard()
+	generate("dailyBrief", "same prompt")
+	if requests != 2 {
+		t.Errorf("expected 2 requests to the LLM after discarding, got %d", requests)
+	}
+
+	cache.Commit()
+	generate("dailyBrief", "same prompt")
+	if requests != 2 {
+		t.Errorf("expected the committed response to be reused, got %d requests", requests)
+	}
+	if len(collector.calls) != 2 {
+		t.Errorf("expected 2 recorded calls, got %d", len(collector.calls))
+	}
+
+	// A different prompt isn't answered from the cache
+	generate("dailyBrief", "other prompt")
+	if requests != 3 {
+		t.Errorf("expected 3 requests to the LLM, got %d", requests)
+	}
+
+	// Prompt keys the cache wasn't created for are never cached
+	generate("chat", "chat prompt")
+	cache.Commit()
+	generate("chat", "chat prompt")
+	if requests != 5 {
+		t.Errorf("expected 5 requests to the LLM, got %d", requests)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	prompt := "Context:\n- Current Date: Monday, 10 March 2025\n- Current Time: 07:05\n- Location: Helsinki"
+
+	tests := []struct {
+		name     string
+		other    string
+		expected bool
+	}{
+		{name: "same prompt", other: prompt, expected: true},
+		{name: "only the minutes differ", other: strings.Replace(prompt, "07:05", "07:55", 1), expected: true},
+		{name: "hour differs", other: strings.Replace(prompt, "07:05", "19:05", 1), expected: false},
+		{name: "date differs", other: strings.Replace(prompt, "10 March", "11 March", 1), expected: false},
+		{name: "context differs", other: strings.Replace(prompt, "Helsinki", "Espoo", 1), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cacheKey(prompt) == cacheKey(tt.other); got != tt.expected {
+				t.Errorf("cacheKey() equal = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
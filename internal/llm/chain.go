@@ -0,0 +1,187 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// Chain is a Provider that tries an ordered list of providers, retrying retryable
+// errors with exponential backoff before failing over to the next provider
+type Chain struct {
+	providers  []Provider
+	maxRetries int
+	baseDelay  time.Duration
+	active     int // index of the provider that answered last
+}
+
+// NewChain creates a new fallback chain. Each provider is retried up to maxRetries
+// times on retryable errors, waiting baseDelay, 2*baseDelay, 4*baseDelay... between attempts.
+func NewChain(providers []Provider, maxRetries int, baseDelay time.Duration) *Chain {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	return &Chain{
+		providers:  providers,
+		maxRetries: maxRetries,
+		baseDelay:  baseDelay,
+	}
+}
+
+// Name returns the identifier of the provider that answered last, or the first provider
+// if no call has been made yet
+func (c *Chain) Name() string {
+	return c.providers[c.active].Name()
+}
+
+// Model returns the model that answered last, or the first model if no call has been
+// made yet
+func (c *Chain) Model() string {
+	return c.providers[c.active].Model()
+}
+
+// SetRecorder sets the recorder on every provider in the chain, so each attempt
+// (including retries and failures) is recorded
+func (c *Chain) SetRecorder(recorder CallRecorder) {
+	for _, provider := range c.providers {
+		provider.SetRecorder(recorder)
+	}
+}
+
+// SetCache sets the cache on every provider in the chain. Responses are cached per
+// provider and model, so a fallback answer is only reused for the same fallback.
+func (c *Chain) SetCache(cache ResponseCache) {
+	for _, provider := range c.providers {
+		provider.SetCache(cache)
+	}
+}
+
+// Close closes all providers in the chain
+func (c *Chain) Close() error {
+	var errs []error
+	for _, provider := range c.providers {
+		if err := provider.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Generate generates content with the first provider that succeeds
+func (c *Chain) Generate(ctx context.Context, promptKey string, outputLanguage string, promptContent string) (string, error) {
+	return c.run(ctx, promptKey, func(p Provider) (string, error) {
+		return p.Generate(ctx, promptKey, outputLanguage, promptContent)
+	})
+}
+
+// GenerateBrief generates a brief with the first provider that succeeds
+func (c *Chain) GenerateBrief(ctx context.Context, bc *BriefContext) (string, error) {
+	return c.run(ctx, "dailyBrief", func(p Provider) (string, error) {
+		return p.GenerateBrief(ctx, bc)
+	})
+}
+
+// GenerateResponse generates a response to a user query with the first provider that succeeds
+func (c *Chain) GenerateResponse(ctx context.Context, query string, memories []string, outputLanguage string) (string, error) {
+	return c.run(ctx, "userQuery", func(p Provider) (string, error) {
+		return p.GenerateResponse(ctx, query, memories, outputLanguage)
+	})
+}
+
+// ListModels lists the models available from the first provider in the chain
+func (c *Chain) ListModels(ctx context.Context) ([]string, error) {
+	return c.providers[0].ListModels(ctx)
+}
+
+// run calls fn for each provider in order until one succeeds
+func (c *Chain) run(ctx context.Context, promptKey string, fn func(p Provider) (string, error)) (string, error) {
+	var errs []error
+	for i, provider := range c.providers {
+		result, err := c.runWithRetry(ctx, provider, fn)
+		if err == nil {
+			c.active = i
+			if i > 0 {
+				slog.Warn("LLM request answered by fallback provider",
+					"prompt", promptKey, "provider", provider.Name(), "model", provider.Model())
+			}
+			return result, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s/%s: %w", provider.Name(), provider.Model(), err))
+
+		// Don't try the remaining providers if the caller gave up
+		if ctx.Err() != nil {
+			break
+		}
+
+		if i < len(c.providers)-1 {
+			slog.Warn("LLM provider failed, trying next in chain",
+				"prompt", promptKey, "provider", provider.Name(), "model", provider.Model(), "error", err)
+		}
+	}
+
+	return "", fmt.Errorf("all LLM providers failed: %w", errors.Join(errs...))
+}
+
+// runWithRetry calls fn with the provider, retrying retryable errors with exponential backoff
+func (c *Chain) runWithRetry(ctx context.Context, provider Provider, fn func(p Provider) (string, error)) (string, error) {
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := c.baseDelay << (attempt - 1)
+			slog.Info("Retrying LLM request",
+				"provider", provider.Name(), "model", provider.Model(), "attempt", attempt+1, "delay", delay, "error", lastErr)
+
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		result, err := fn(provider)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		if !IsRetryable(err) {
+			break
+		}
+	}
+
+	return "", lastErr
+}
+
+// IsRetryable reports whether an LLM error is transient: rate limiting (429),
+// server errors (5xx) or network timeouts
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if statusErr, ok := errors.AsType[*StatusError](err); ok {
+		return isRetryableStatus(statusErr.StatusCode)
+	}
+
+	if apiErr, ok := errors.AsType[*googleapi.Error](err); ok {
+		return isRetryableStatus(apiErr.Code)
+	}
+
+	if netErr, ok := errors.AsType[net.Error](err); ok && netErr.Timeout() {
+		return true
+	}
+
+	return false
+}
+
+// isRetryableStatus reports whether an HTTP status code indicates a transient failure
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
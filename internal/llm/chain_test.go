@@ -0,0 +1,154 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// fakeProvider is a Provider that returns scripted errors before succeeding
+type fakeProvider struct {
+	name   string
+	model  string
+	errs   []error // errors returned by consecutive calls before succeeding
+	calls  int
+	answer string
+}
+
+func (f *fakeProvider) Name() string  { return f.name }
+func (f *fakeProvider) Model() string { return f.model }
+func (f *fakeProvider) Close() error  { return nil }
+
+func (f *fakeProvider) SetRecorder(recorder CallRecorder) {}
+func (f *fakeProvider) SetCache(cache ResponseCache)      {}
+
+func (f *fakeProvider) Generate(ctx context.Context, promptKey, outputLanguage, promptContent string) (string, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return "", f.errs[f.calls-1]
+	}
+	return f.answer, nil
+}
+
+func (f *fakeProvider) GenerateBrief(ctx context.Context, bc *BriefContext) (string, error) {
+	return f.Generate(ctx, "dailyBrief", bc.Language, "")
+}
+
+func (f *fakeProvider) GenerateResponse(ctx context.Context, query string, memories []string, outputLanguage string) (string, error) {
+	return f.Generate(ctx, "userQuery", outputLanguage, query)
+}
+
+func (f *fakeProvider) ListModels(ctx context.Context) ([]string, error) {
+	return []string{f.model}, nil
+}
+
+func TestChainGenerate(t *testing.T) {
+	rateLimited := &StatusError{StatusCode: http.StatusTooManyRequests}
+	badRequest := &StatusError{StatusCode: http.StatusBadRequest}
+
+	tests := []struct {
+		name        string
+		primaryErrs []error
+		maxRetries  int
+		wantAnswer  string
+		wantModel   string
+		wantPrimary int // expected number of calls to the primary provider
+	}{
+		{
+			name:        "primary succeeds",
+			wantAnswer:  "primary",
+			wantModel:   "primary-model",
+			wantPrimary: 1,
+		},
+		{
+			name:        "primary succeeds after retry",
+			primaryErrs: []error{rateLimited},
+			maxRetries:  2,
+			wantAnswer:  "primary",
+			wantModel:   "primary-model",
+			wantPrimary: 2,
+		},
+		{
+			name:        "retries exhausted, fallback answers",
+			primaryErrs: []error{rateLimited, rateLimited, rateLimited},
+			maxRetries:  2,
+			wantAnswer:  "fallback",
+			wantModel:   "fallback-model",
+			wantPrimary: 3,
+		},
+		{
+			name:        "non-retryable error fails over immediately",
+			primaryErrs: []error{badRequest},
+			maxRetries:  2,
+			wantAnswer:  "fallback",
+			wantModel:   "fallback-model",
+			wantPrimary: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &fakeProvider{name: "gemini", model: "primary-model", errs: tt.primaryErrs, answer: "primary"}
+			fallback := &fakeProvider{name: "ollama", model: "fallback-model", answer: "fallback"}
+			chain := NewChain([]Provider{primary, fallback}, tt.maxRetries, time.Millisecond)
+
+			got, err := chain.Generate(context.Background(), "dailyBrief", "Finnish", "prompt")
+			if err != nil {
+				t.Fatalf("Generate() returned an error: %v", err)
+			}
+			if got != tt.wantAnswer {
+				t.Errorf("Generate() = %q, expected %q", got, tt.wantAnswer)
+			}
+			if chain.Model() != tt.wantModel {
+				t.Errorf("Model() = %q, expected %q", chain.Model(), tt.wantModel)
+			}
+			if primary.calls != tt.wantPrimary {
+				t.Errorf("primary called %d times, expected %d", primary.calls, tt.wantPrimary)
+			}
+		})
+	}
+}
+
+func TestChainAllProvidersFail(t *testing.T) {
+	serverErr := &StatusError{StatusCode: http.StatusServiceUnavailable}
+	primary := &fakeProvider{name: "gemini", model: "a", errs: []error{serverErr, serverErr}}
+	fallback := &fakeProvider{name: "ollama", model: "b", errs: []error{serverErr, serverErr}}
+	chain := NewChain([]Provider{primary, fallback}, 1, time.Millisecond)
+
+	if _, err := chain.Generate(context.Background(), "dailyBrief", "Finnish", "prompt"); err == nil {
+		t.Error("Generate() expected an error when all providers fail")
+	}
+	if primary.calls != 2 || fallback.calls != 2 {
+		t.Errorf("expected 2 calls per provider, got %d and %d", primary.calls, fallback.calls)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "rate limited", err: &StatusError{StatusCode: 429}, want: true},
+		{name: "server error", err: &StatusError{StatusCode: 503}, want: true},
+		{name: "bad request", err: &StatusError{StatusCode: 400}, want: false},
+		{name: "wrapped rate limit", err: fmt.Errorf("failed to generate content: %w", &StatusError{StatusCode: 429}), want: true},
+		{name: "gemini rate limit", err: fmt.Errorf("failed to generate content: %w", &googleapi.Error{Code: 429}), want: true},
+		{name: "gemini permission denied", err: &googleapi.Error{Code: 403}, want: false},
+		{name: "plain error", err: errors.New("no content generated"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, expected %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
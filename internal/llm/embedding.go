@@ -0,0 +1,269 @@
+package llm
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+)
+
+const (
+	// DefaultGeminiEmbeddingModel is the embedding model used with Gemini if none is configured
+	DefaultGeminiEmbeddingModel = "text-embedding-004"
+	// DefaultOllamaEmbeddingModel is the embedding model used with Ollama if none is configured
+	DefaultOllamaEmbeddingModel = "nomic-embed-text"
+
+	// geminiEmbedBatchSize is the most texts the Gemini API accepts in one batch request
+	geminiEmbedBatchSize = 100
+)
+
+// Embedder turns texts into vectors whose cosine similarity reflects their meaning
+type Embedder interface {
+	// Name returns the provider identifier (e.g. "gemini", "ollama")
+	Name() string
+
+	// Model returns the name of the embedding model
+	Model() string
+
+	// Embed returns one vector for each text, in the same order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+
+	// Close releases any resources held by the embedder
+	Close() error
+}
+
+// NewEmbedder creates the embedder selected by embedding_provider. It returns nil if
+// no embedding provider is configured.
+func NewEmbedder(cfg *config.Config) (Embedder, error) {
+	switch cfg.EmbeddingProvider {
+	case "":
+		return nil, nil
+	case ProviderGemini:
+		return NewGeminiEmbedder(cfg.GeminiAPIKey, cmp.Or(cfg.EmbeddingModel, DefaultGeminiEmbeddingModel))
+	case ProviderOllama:
+		return NewOllamaEmbedder(cfg.OllamaURL, cmp.Or(cfg.EmbeddingModel, DefaultOllamaEmbeddingModel)), nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider: %s", cfg.EmbeddingProvider)
+	}
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors, 0 if either is empty or
+// their lengths differ
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// GeminiEmbedder creates embeddings with the Gemini API
+type GeminiEmbedder struct {
+	client    *genai.Client
+	model     *genai.EmbeddingModel
+	modelName string
+}
+
+// NewGeminiEmbedder creates a new Gemini embedder with the given API key and model name
+func NewGeminiEmbedder(apiKey, modelName string) (*GeminiEmbedder, error) {
+	client, err := genai.NewClient(context.Background(), option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+
+	return &GeminiEmbedder{
+		client:    client,
+		model:     client.EmbeddingModel(modelName),
+		modelName: modelName,
+	}, nil
+}
+
+// Name returns the provider identifier
+func (e *GeminiEmbedder) Name() string {
+	return ProviderGemini
+}
+
+// Model returns the embedding model
+func (e *GeminiEmbedder) Model() string {
+	return e.modelName
+}
+
+// Close closes the Gemini client
+func (e *GeminiEmbedder) Close() error {
+	return e.client.Close()
+}
+
+// Embed embeds the texts in batch requests of at most geminiEmbedBatchSize texts
+func (e *GeminiEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return embedInBatches(texts, geminiEmbedBatchSize, func(texts []string) ([][]float32, error) {
+		batch := e.model.NewBatch()
+		for _, text := range texts {
+			batch.AddContent(genai.Text(text))
+		}
+
+		resp, err := e.model.BatchEmbedContents(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create embeddings: %w", err)
+		}
+
+		vectors := make([][]float32, len(resp.Embeddings))
+		for i, embedding := range resp.Embeddings {
+			vectors[i] = embedding.Values
+		}
+		return vectors, nil
+	})
+}
+
+// embedInBatches embeds the texts by calling embed with consecutive batches of at most
+// size texts, checking that each batch returns one vector per text
+func embedInBatches(texts []string, size int, embed func(texts []string) ([][]float32, error)) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += size {
+		batch := texts[start:min(start+size, len(texts))]
+		batchVectors, err := embed(batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(batchVectors) != len(batch) {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", len(batch), len(batchVectors))
+		}
+		vectors = append(vectors, batchVectors...)
+	}
+	return vectors, nil
+}
+
+// OllamaEmbedder creates embeddings with a local Ollama server
+type OllamaEmbedder struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+// ollamaEmbedRequest is the request body for the Ollama /api/embed endpoint
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// ollamaEmbedResponse is the response body from the Ollama /api/embed endpoint
+type ollamaEmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// NewOllamaEmbedder creates a new Ollama embedder for the server at baseURL
+func NewOllamaEmbedder(baseURL, model string) *OllamaEmbedder {
+	return &OllamaEmbedder{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		httpClient: &http.Client{
+			Timeout: 2 * time.Minute,
+		},
+	}
+}
+
+// Name returns the provider identifier
+func (e *OllamaEmbedder) Name() string {
+	return ProviderOllama
+}
+
+// Model returns the embedding model
+func (e *OllamaEmbedder) Model() string {
+	return e.model
+}
+
+// Close is a no-op, the HTTP client holds no resources that need releasing
+func (e *OllamaEmbedder) Close() error {
+	return nil
+}
+
+// Embed embeds the texts in a single request
+func (e *OllamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	var resp ollamaEmbedResponse
+	reqBody := ollamaEmbedRequest{Model: e.model, Input: texts}
+	if err := doJSON(ctx, e.httpClient, http.MethodPost, e.baseURL+"/api/embed", "", reqBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to create embeddings: %w", err)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(resp.Embeddings))
+	}
+
+	return resp.Embeddings, nil
+}
+
+// fakeEmbeddingDimensions is the length of the vectors created by FakeEmbedder
+const fakeEmbeddingDimensions = 64
+
+// FakeEmbedder is a deterministic embedder for tests. Each word is hashed into one of the
+// vector dimensions, so texts sharing words are similar.
+type FakeEmbedder struct {
+	// Err is returned from Embed if set
+	Err error
+	// Calls counts the texts embedded so far
+	Calls int
+}
+
+// Name returns the provider identifier
+func (e *FakeEmbedder) Name() string {
+	return "fake"
+}
+
+// Model returns the embedding model
+func (e *FakeEmbedder) Model() string {
+	return "fake-embedding"
+}
+
+// Close is a no-op
+func (e *FakeEmbedder) Close() error {
+	return nil
+}
+
+// Embed returns a bag-of-words vector for each text
+func (e *FakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.Err != nil {
+		return nil, e.Err
+	}
+	e.Calls += len(texts)
+
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, fakeEmbeddingDimensions)
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, word := range words {
+			hash := fnv.New32a()
+			_, _ = hash.Write([]byte(word))
+			vector[hash.Sum32()%fakeEmbeddingDimensions]++
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
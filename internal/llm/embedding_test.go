@@ -0,0 +1,148 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     []float32
+		expected float64
+	}{
+		{"identical", []float32{1, 2, 3}, []float32{1, 2, 3}, 1},
+		{"scaled", []float32{1, 2, 3}, []float32{2, 4, 6}, 1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"opposite", []float32{1, 0}, []float32{-1, 0}, -1},
+		{"different lengths", []float32{1, 0}, []float32{1, 0, 0}, 0},
+		{"zero vector", []float32{0, 0}, []float32{1, 0}, 0},
+		{"empty", nil, nil, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := CosineSimilarity(test.a, test.b); math.Abs(got-test.expected) > 1e-9 {
+				t.Errorf("CosineSimilarity() = %v, expected %v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestFakeEmbedder(t *testing.T) {
+	embedder := &FakeEmbedder{}
+	vectors, err := embedder.Embed(context.Background(), []string{
+		"Bob is allergic to nuts",
+		"bob is ALLERGIC to nuts!",
+		"The car needs new tyres",
+	})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	if got := CosineSimilarity(vectors[0], vectors[1]); math.Abs(got-1) > 1e-9 {
+		t.Errorf("expected the same words to give identical vectors, similarity %v", got)
+	}
+	if CosineSimilarity(vectors[0], vectors[2]) >= CosineSimilarity(vectors[0], vectors[1]) {
+		t.Error("expected unrelated texts to be less similar")
+	}
+	if embedder.Calls != 3 {
+		t.Errorf("Calls = %d, expected 3", embedder.Calls)
+	}
+}
+
+func TestOllamaEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+
+		var req ollamaEmbedRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Model != "nomic-embed-text" {
+			t.Errorf("expected model nomic-embed-text, got %q", req.Model)
+		}
+
+		resp := ollamaEmbedResponse{}
+		for i := range req.Input {
+			resp.Embeddings = append(resp.Embeddings, []float32{float32(i), 1})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	embedder := NewOllamaEmbedder(server.URL+"/", "nomic-embed-text")
+	vectors, err := embedder.Embed(context.Background(), []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(vectors) != 2 || vectors[1][0] != 1 {
+		t.Errorf("Embed() = %v, expected a vector for each text in order", vectors)
+	}
+}
+
+func TestEmbedInBatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		texts   int
+		size    int
+		batches []int
+	}{
+		{name: "no texts", texts: 0, size: 100, batches: nil},
+		{name: "single batch", texts: 3, size: 100, batches: []int{3}},
+		{name: "exactly full", texts: 100, size: 100, batches: []int{100}},
+		{name: "split", texts: 250, size: 100, batches: []int{100, 100, 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			texts := make([]string, tt.texts)
+			for i := range texts {
+				texts[i] = strconv.Itoa(i)
+			}
+
+			var batches []int
+			vectors, err := embedInBatches(texts, tt.size, func(batch []string) ([][]float32, error) {
+				batches = append(batches, len(batch))
+				vectors := make([][]float32, len(batch))
+				for i, text := range batch {
+					n, _ := strconv.Atoi(text)
+					vectors[i] = []float32{float32(n)}
+				}
+				return vectors, nil
+			})
+			if err != nil {
+				t.Fatalf("embedInBatches() error = %v", err)
+			}
+
+			if !slices.Equal(batches, tt.batches) {
+				t.Errorf("batch sizes = %v, expected %v", batches, tt.batches)
+			}
+			if len(vectors) != tt.texts {
+				t.Fatalf("got %d vectors, expected %d", len(vectors), tt.texts)
+			}
+			for i, vector := range vectors {
+				if vector[0] != float32(i) {
+					t.Errorf("vector %d = %v, expected the vector of text %d", i, vector, i)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbedInBatchesWrongCount(t *testing.T) {
+	_, err := embedInBatches([]string{"a", "b"}, 100, func(batch []string) ([][]float32, error) {
+		return [][]float32{{1}}, nil
+	})
+	if err == nil {
+		t.Error("expected an error when a batch returns too few vectors")
+	}
+}
@@ -3,35 +3,26 @@ package llm
 import (
 	"context"
 	"fmt"
-	"log/slog"
 	"strings"
 
 	"github.com/google/generative-ai-go/genai"
 	"google.golang.org/api/option"
 )
 
-const (
-	// PromptContextPlaceholder is the placeholder for context in prompts.
-	PromptContextPlaceholder = "%CONTEXT%"
-	// PromptNotesPlaceholder is the placeholder for notes/memories in prompts.
-	PromptNotesPlaceholder = "%NOTES%"
-	// PromptLanguagePlaceholder is the placeholder for the output language in prompts.
-	PromptLanguagePlaceholder = "%LANG%"
-	// PromptQueryPlaceholder is the placeholder for user queries in prompts.
-	PromptQueryPlaceholder = "%QUERY%"
-)
-
 // cleanMarkdownWrapper removes markdown code block wrapping from LLM responses
 func cleanMarkdownWrapper(content string) string {
 	// Remove leading and trailing whitespace
 	content = strings.TrimSpace(content)
 
-	// Check if content starts with ```markdown and ends with ```
-	if strings.HasPrefix(content, "```markdown") && strings.HasSuffix(content, "```") {
-		// Remove the markdown code block wrapper
-		content = strings.TrimPrefix(content, "```markdown")
-		content = strings.TrimSuffix(content, "```")
-		content = strings.TrimSpace(content)
+	// Check if content starts with ```markdown or ```json and ends with ```
+	for _, prefix := range []string{"```markdown", "```json"} {
+		if strings.HasPrefix(content, prefix) && strings.HasSuffix(content, "```") {
+			// Remove the code block wrapper
+			content = strings.TrimPrefix(content, prefix)
+			content = strings.TrimSuffix(content, "```")
+			content = strings.TrimSpace(content)
+			break
+		}
 	}
 
 	return content
@@ -39,9 +30,12 @@ func cleanMarkdownWrapper(content string) string {
 
 // Client is a client for the Google Gemini API
 type Client struct {
-	client  *genai.Client
-	model   *genai.GenerativeModel
-	prompts map[string][]string
+	client    *genai.Client
+	model     *genai.GenerativeModel
+	modelName string
+	prompts   map[string][]string
+
+	callHooks
 }
 
 // NewClient creates a new Gemini client with the given API key, model name, and prompts
@@ -56,12 +50,23 @@ func NewClient(apiKey string, modelName string, prompts map[string][]string) (*C
 	model := client.GenerativeModel(modelName)
 
 	return &Client{
-		client:  client,
-		model:   model,
-		prompts: prompts,
+		client:    client,
+		model:     model,
+		modelName: modelName,
+		prompts:   prompts,
 	}, nil
 }
 
+// Name returns the provider identifier
+func (c *Client) Name() string {
+	return ProviderGemini
+}
+
+// Model returns the Gemini model used for generation
+func (c *Client) Model() string {
+	return c.modelName
+}
+
 // Close closes the Gemini client
 func (c *Client) Close() error {
 	return c.client.Close()
@@ -69,157 +74,73 @@ func (c *Client) Close() error {
 
 // Generate generates content using the Gemini API with the specified prompt content and output language
 func (c *Client) Generate(ctx context.Context, promptKey string, outputLanguage string, promptContent string) (string, error) {
+	return c.run(ctx, ProviderGemini, c.modelName, promptKey, promptContent, func() (string, Usage, error) {
+		return c.generate(ctx, promptContent)
+	})
+}
+
+// generate sends the prompt to the Gemini API and returns the cleaned response text and
+// the token usage
+func (c *Client) generate(ctx context.Context, promptContent string) (string, Usage, error) {
 	// For debugging purposes, you can print the prompt
 	// fmt.Println("Prompt for Gemini:", promptContent)
 
 	// Generate the response
 	resp, err := c.model.GenerateContent(ctx, genai.Text(promptContent))
 	if err != nil {
-		return "", fmt.Errorf("failed to generate content: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	var usage Usage
+	if resp.UsageMetadata != nil {
+		usage.InputTokens = int(resp.UsageMetadata.PromptTokenCount)
+		usage.OutputTokens = int(resp.UsageMetadata.CandidatesTokenCount)
 	}
 
 	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("no content generated")
+		return "", usage, fmt.Errorf("no content generated")
 	}
 
 	// Extract the text from the response
 	text, ok := resp.Candidates[0].Content.Parts[0].(genai.Text)
 	if !ok {
-		return "", fmt.Errorf("unexpected response format")
+		return "", usage, fmt.Errorf("unexpected response format")
 	}
 
 	// Clean any markdown wrapper from the response
 	cleanedText := cleanMarkdownWrapper(string(text))
 
-	return cleanedText, nil
-}
-
-// BuildBriefPrompt builds the prompt content for a brief without sending it to the LLM
-func (c *Client) BuildBriefPrompt(memories []string, userInfo map[string]string, outputLanguage string) string {
-	// Build the context information
-	var contextBuilder strings.Builder
-
-	// Add user information if available
-	if len(userInfo) > 0 {
-		// Extract specific information for special handling
-		date := userInfo["Date"]
-		currentTime := userInfo["CurrentTime"]
-		timezone := userInfo["Timezone"]
-		location := userInfo["Location"]
-		family := userInfo["Family"]
-		weather := userInfo["Weather"]
-		futureWeather := userInfo["FutureWeather"]
-		weatherChanges := userInfo["WeatherChanges"]
-		birthdays := userInfo["Birthdays"]
-		ongoingEvents := userInfo["OngoingEvents"]
-
-		if date != "" {
-			fmt.Fprintf(&contextBuilder, "- Current Date: %s\n", date)
-		}
-
-		if currentTime != "" {
-			fmt.Fprintf(&contextBuilder, "- Current Time: %s\n", currentTime)
-		}
-
-		if timezone != "" {
-			fmt.Fprintf(&contextBuilder, "- Timezone: %s\n", timezone)
-		}
-
-		if location != "" {
-			fmt.Fprintf(&contextBuilder, "- Location: %s\n", location)
-		}
-
-		if family != "" {
-			fmt.Fprintf(&contextBuilder, "- Family Members: %s\n", family)
-		}
-
-		if weather != "" {
-			fmt.Fprintf(&contextBuilder, "- Today's Weather: %s\n", weather)
-		}
-
-		if futureWeather != "" {
-			contextBuilder.WriteString("- Upcoming Weather Forecasts:\n")
-			for forecast := range strings.SplitSeq(futureWeather, "\n") {
-				fmt.Fprintf(&contextBuilder, "  * %s\n", forecast)
-			}
-		}
-
-		if weatherChanges != "" {
-			contextBuilder.WriteString("- Weather Forecast Changes:\n")
-			for change := range strings.SplitSeq(weatherChanges, "\n") {
-				fmt.Fprintf(&contextBuilder, "  * %s\n", change)
-			}
-		}
-
-		if birthdays != "" {
-			fmt.Fprintf(&contextBuilder, "- Birthdays Today: %s\n", birthdays)
-		}
-
-		if ongoingEvents != "" {
-			contextBuilder.WriteString("- Currently Ongoing:\n")
-			for event := range strings.SplitSeq(ongoingEvents, "\n") {
-				fmt.Fprintf(&contextBuilder, "  * %s\n", event)
-			}
-		}
-	}
-
-	// Format memories
-	var memoryBuilder strings.Builder
-	for _, memory := range memories {
-		fmt.Fprintf(&memoryBuilder, "- %s\n", memory)
-	}
-
-	// Create the prompt content with context, memories, and language
-	promptContent := strings.Join(c.prompts["dailyBrief"], "\n")
-	promptContent = strings.ReplaceAll(promptContent, PromptContextPlaceholder, contextBuilder.String())
-	promptContent = strings.ReplaceAll(promptContent, PromptNotesPlaceholder, memoryBuilder.String())
-	promptContent = strings.ReplaceAll(promptContent, PromptLanguagePlaceholder, outputLanguage)
-
-	return promptContent
+	return cleanedText, usage, nil
 }
 
-// GenerateBrief generates a brief based on the provided memories
-func (c *Client) GenerateBrief(ctx context.Context, memories []string, userInfo map[string]string, outputLanguage string) (string, error) {
+// GenerateBrief generates a brief based on the provided brief context
+func (c *Client) GenerateBrief(ctx context.Context, bc *BriefContext) (string, error) {
 	// Build the prompt content
-	promptContent := c.BuildBriefPrompt(memories, userInfo, outputLanguage)
+	promptContent, err := BuildBriefPrompt(c.prompts, bc)
+	if err != nil {
+		return "", err
+	}
 
 	// Generate the brief
-	return c.Generate(ctx, "dailyBrief", outputLanguage, promptContent)
+	return c.Generate(ctx, "dailyBrief", bc.Language, promptContent)
 }
 
 // GenerateResponse generates a response to a user query
 func (c *Client) GenerateResponse(ctx context.Context, query string, memories []string, outputLanguage string) (string, error) {
-	// Format memories
-	var memoryBuilder strings.Builder
-	for _, memory := range memories {
-		fmt.Fprintf(&memoryBuilder, "- %s\n", memory)
+	// Build the prompt content
+	promptContent, err := BuildResponsePrompt(c.prompts, query, memories, outputLanguage)
+	if err != nil {
+		return "", err
 	}
 
-	// Create the prompt content with query, memories, and language
-	promptContent := strings.Join(c.prompts["userQuery"], "\n")
-	promptContent = strings.ReplaceAll(promptContent, PromptQueryPlaceholder, query)
-	promptContent = strings.ReplaceAll(promptContent, PromptNotesPlaceholder, memoryBuilder.String())
-	promptContent = strings.ReplaceAll(promptContent, PromptLanguagePlaceholder, outputLanguage)
-
 	// Generate the response
 	return c.Generate(ctx, "userQuery", outputLanguage, promptContent)
 }
 
 // ListModels lists the available Gemini models
-func ListModels(ctx context.Context, apiKey string) ([]string, error) {
-	// Create a temporary client
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
-	}
-	defer func() {
-		if err := client.Close(); err != nil {
-			slog.Error("Failed to close Gemini client", "error", err)
-		}
-	}()
-
+func (c *Client) ListModels(ctx context.Context) ([]string, error) {
 	// List the models
-	iter := client.ListModels(ctx)
+	iter := c.client.ListModels(ctx)
 
 	// Extract model names
 	var modelNames []string
@@ -33,6 +33,11 @@ func TestCleanMarkdownWrapper(t *testing.T) {
 			input:    "```markdown\nHyvää huomenta! Tänään on keskiviikko, 13. elokuuta 2025.\n\nSään ennuste Järvenpäähän huomiselle:\n- Lämpötila: 12-25°C\n```",
 			expected: "Hyvää huomenta! Tänään on keskiviikko, 13. elokuuta 2025.\n\nSään ennuste Järvenpäähän huomiselle:\n- Lämpötila: 12-25°C",
 		},
+		{
+			name:     "JSON wrapped in code block",
+			input:    "```json\n{\"greeting\": \"Hyvää huomenta!\"}\n```",
+			expected: "{\"greeting\": \"Hyvää huomenta!\"}",
+		},
 		{
 			name:     "empty input",
 			input:    "",
@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// callHooks holds the call recorder and response cache shared by the provider clients.
+// Embedding it gives a client the SetRecorder and SetCache methods.
+type callHooks struct {
+	recorder CallRecorder
+	cache    ResponseCache
+}
+
+// SetRecorder sets the recorder that every Generate call is reported to
+func (h *callHooks) SetRecorder(recorder CallRecorder) {
+	h.recorder = recorder
+}
+
+// SetCache sets the cache that responses are looked up from and stored in
+func (h *callHooks) SetCache(cache ResponseCache) {
+	h.cache = cache
+}
+
+// run answers the prompt from the cache if possible. Otherwise it calls generate, records
+// the call and passes a successful response to the cache, which holds it until the caller
+// commits it.
+func (h *callHooks) run(ctx context.Context, provider, model, promptKey, promptContent string, generate func() (string, Usage, error)) (string, error) {
+	promptHash := PromptHash(promptContent)
+	key := cacheKey(promptContent)
+
+	if h.cache != nil {
+		if cached, ok := h.cache.Get(ctx, provider, model, promptKey, key); ok {
+			slog.Info("Using cached LLM response", "prompt", promptKey, "provider", provider, "model", model)
+			return cached, nil
+		}
+	}
+
+	start := time.Now()
+	text, usage, err := generate()
+	recordCall(ctx, h.recorder, Call{
+		Provider:   provider,
+		Model:      model,
+		PromptKey:  promptKey,
+		PromptHash: promptHash,
+		Usage:      usage,
+		Latency:    time.Since(start),
+		Err:        err,
+	})
+
+	if err == nil && h.cache != nil {
+		h.cache.Put(ctx, provider, model, promptKey, key, text)
+	}
+
+	return text, err
+}
@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// Usage is the token usage reported by the LLM API for a single call
+type Usage struct {
+	InputTokens  int
+	OutputTokens int
+}
+
+// Call describes a single LLM API call
+type Call struct {
+	Provider   string
+	Model      string
+	PromptKey  string
+	PromptHash string
+	Usage      Usage
+	Latency    time.Duration
+	Err        error
+}
+
+// CallRecorder records LLM API calls, e.g. to the call ledger in the database
+type CallRecorder interface {
+	RecordCall(ctx context.Context, call Call)
+}
+
+// PromptHash returns the hex-encoded SHA-256 hash of the prompt content, used to tell
+// apart calls with the same prompt key but different content
+func PromptHash(promptContent string) string {
+	sum := sha256.Sum256([]byte(promptContent))
+	return hex.EncodeToString(sum[:])
+}
+
+// recordCall passes the call to the recorder, if one is set
+func recordCall(ctx context.Context, recorder CallRecorder, call Call) {
+	if recorder == nil {
+		return
+	}
+	recorder.RecordCall(ctx, call)
+}
+
+// LedgerStore is the storage used by the call ledger
+type LedgerStore interface {
+	AddLLMCall(call store.LLMCall) (int64, error)
+}
+
+// Ledger is a CallRecorder that writes every call to the llm_calls table together with
+// its estimated cost
+type Ledger struct {
+	store  LedgerStore
+	prices []config.ModelPrice
+}
+
+// NewLedger creates a new call ledger. The prices are used to estimate the cost of each call.
+func NewLedger(store LedgerStore, prices []config.ModelPrice) *Ledger {
+	return &Ledger{
+		store:  store,
+		prices: prices,
+	}
+}
+
+// RecordCall writes the call to the database. Failures are only logged since losing a
+// ledger entry shouldn't fail the LLM request.
+func (l *Ledger) RecordCall(ctx context.Context, call Call) {
+	entry := store.LLMCall{
+		Provider:     call.Provider,
+		Model:        call.Model,
+		PromptKey:    call.PromptKey,
+		PromptHash:   call.PromptHash,
+		InputTokens:  call.Usage.InputTokens,
+		OutputTokens: call.Usage.OutputTokens,
+		LatencyMS:    call.Latency.Milliseconds(),
+		Cost:         EstimateCost(l.prices, call.Model, call.Usage),
+	}
+	if call.Err != nil {
+		errText := call.Err.Error()
+		entry.Error = &errText
+	}
+
+	if _, err := l.store.AddLLMCall(entry); err != nil {
+		slog.Warn("Failed to record LLM call", "provider", call.Provider, "model", call.Model, "error", err)
+	}
+}
+
+// EstimateCost returns the cost of the token usage in euros using the price of the given
+// model. Models without a configured price (e.g. local models) cost nothing.
+func EstimateCost(prices []config.ModelPrice, model string, usage Usage) float64 {
+	for _, price := range prices {
+		if strings.EqualFold(price.Model, model) {
+			return (float64(usage.InputTokens)*price.InputPerMillion + float64(usage.OutputTokens)*price.OutputPerMillion) / 1_000_000
+		}
+	}
+	return 0
+}
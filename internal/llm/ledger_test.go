@@ -0,0 +1,125 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// fakeLedgerStore collects the calls added to the ledger
+type fakeLedgerStore struct {
+	calls []store.LLMCall
+}
+
+func (f *fakeLedgerStore) AddLLMCall(call store.LLMCall) (int64, error) {
+	f.calls = append(f.calls, call)
+	return int64(len(f.calls)), nil
+}
+
+// callCollector is a CallRecorder that keeps the recorded calls in memory
+type callCollector struct {
+	calls []Call
+}
+
+func (c *callCollector) RecordCall(ctx context.Context, call Call) {
+	c.calls = append(c.calls, call)
+}
+
+func TestEstimateCost(t *testing.T) {
+	prices := []config.ModelPrice{
+		{Model: "gemini-2.5-flash", InputPerMillion: 0.3, OutputPerMillion: 2.5},
+	}
+
+	tests := []struct {
+		name     string
+		model    string
+		usage    Usage
+		expected float64
+	}{
+		{"priced model", "gemini-2.5-flash", Usage{InputTokens: 10_000, OutputTokens: 2_000}, 0.008},
+		{"case insensitive", "Gemini-2.5-Flash", Usage{InputTokens: 1_000_000}, 0.3},
+		{"unpriced model", "llama3", Usage{InputTokens: 10_000, OutputTokens: 2_000}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateCost(prices, tt.model, tt.usage)
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("EstimateCost() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLedgerRecordCall(t *testing.T) {
+	ledgerStore := &fakeLedgerStore{}
+	ledger := NewLedger(ledgerStore, []config.ModelPrice{{Model: "gpt-4o-mini", InputPerMillion: 1, OutputPerMillion: 2}})
+
+	ledger.RecordCall(context.Background(), Call{
+		Provider:   ProviderOpenAI,
+		Model:      "gpt-4o-mini",
+		PromptKey:  "dailyBrief",
+		PromptHash: PromptHash("prompt"),
+		Usage:      Usage{InputTokens: 500_000, OutputTokens: 250_000},
+		Latency:    1500 * time.Millisecond,
+		Err:        errors.New("boom"),
+	})
+
+	if len(ledgerStore.calls) != 1 {
+		t.Fatalf("expected 1 recorded call, got %d", len(ledgerStore.calls))
+	}
+
+	got := ledgerStore.calls[0]
+	if got.PromptKey != "dailyBrief" || got.InputTokens != 500_000 || got.OutputTokens != 250_000 {
+		t.Errorf("unexpected call: %+v", got)
+	}
+	if got.LatencyMS != 1500 {
+		t.Errorf("LatencyMS = %d, expected 1500", got.LatencyMS)
+	}
+	if got.Cost != 1.0 {
+		t.Errorf("Cost = %v, expected 1.0", got.Cost)
+	}
+	if got.Error == nil || *got.Error != "boom" {
+		t.Errorf("Error = %v, expected boom", got.Error)
+	}
+}
+
+func TestGenerateRecordsCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"model":"llama3","response":"Hei!","done":true,"prompt_eval_count":42,"eval_count":7}`))
+	}))
+	defer server.Close()
+
+	collector := &callCollector{}
+	client := NewOllamaClient(server.URL, "llama3", nil)
+	client.SetRecorder(collector)
+
+	if _, err := client.Generate(context.Background(), "userQuery", "Finnish", "prompt"); err != nil {
+		t.Fatalf("Generate() returned an error: %v", err)
+	}
+
+	if len(collector.calls) != 1 {
+		t.Fatalf("expected 1 recorded call, got %d", len(collector.calls))
+	}
+
+	got := collector.calls[0]
+	if got.Provider != ProviderOllama || got.Model != "llama3" || got.PromptKey != "userQuery" {
+		t.Errorf("unexpected call: %+v", got)
+	}
+	if got.PromptHash != PromptHash("prompt") {
+		t.Errorf("PromptHash = %q, expected hash of the prompt", got.PromptHash)
+	}
+	if got.Usage != (Usage{InputTokens: 42, OutputTokens: 7}) {
+		t.Errorf("Usage = %+v, expected 42 input and 7 output tokens", got.Usage)
+	}
+	if got.Err != nil {
+		t.Errorf("Err = %v, expected nil", got.Err)
+	}
+}
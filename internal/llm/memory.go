@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExtractedMemory is a memory extracted by the LLM from free text with the parseMemory prompt
+type ExtractedMemory struct {
+	Content string `json:"content"`
+	// Date is the relevance date as YYYY-MM-DD, empty if the text has no date
+	Date string `json:"date"`
+	// Time is the time of day as HH:MM, empty if the text has no time
+	Time string `json:"time"`
+	// Person is the family member the memory concerns, empty if none
+	Person string `json:"person"`
+}
+
+// ParseExtractedMemory parses and validates the LLM response to the parseMemory prompt.
+// The person is matched case-insensitively against the family names and replaced with
+// the configured spelling.
+func ParseExtractedMemory(response string, family []string) (*ExtractedMemory, error) {
+	trimmed := strings.TrimSpace(response)
+	// Models like to wrap JSON in a code fence even when told not to
+	trimmed = strings.TrimPrefix(trimmed, "```json")
+	trimmed = strings.TrimPrefix(trimmed, "```")
+	trimmed = strings.TrimSuffix(trimmed, "```")
+
+	var memory ExtractedMemory
+	if err := json.Unmarshal([]byte(strings.TrimSpace(trimmed)), &memory); err != nil {
+		return nil, fmt.Errorf("failed to parse extracted memory: %w", err)
+	}
+
+	memory.Content = strings.TrimSpace(memory.Content)
+	memory.Date = strings.TrimSpace(memory.Date)
+	memory.Time = strings.TrimSpace(memory.Time)
+	memory.Person = strings.TrimSpace(memory.Person)
+
+	if memory.Content == "" {
+		return nil, errors.New("extracted memory has no content")
+	}
+	if memory.Date != "" {
+		if _, err := time.Parse("2006-01-02", memory.Date); err != nil {
+			return nil, fmt.Errorf("extracted memory has an invalid date %q", memory.Date)
+		}
+	}
+	if memory.Time != "" {
+		if _, err := time.Parse("15:04", memory.Time); err != nil {
+			return nil, fmt.Errorf("extracted memory has an invalid time %q", memory.Time)
+		}
+	}
+
+	for _, name := range family {
+		if strings.EqualFold(memory.Person, name) {
+			memory.Person = name
+			break
+		}
+	}
+
+	return &memory, nil
+}
+
+// RelevanceDate returns the relevance date of the memory, nil if it has none
+func (m *ExtractedMemory) RelevanceDate() *time.Time {
+	if m.Date == "" {
+		return nil
+	}
+	// Validated in ParseExtractedMemory
+	date, _ := time.Parse("2006-01-02", m.Date)
+	return &date
+}
+
+// MemoryContent returns the content to store, with the time and the person added when
+// the content doesn't mention them already
+func (m *ExtractedMemory) MemoryContent() string {
+	content := m.Content
+	if m.Time != "" && !strings.Contains(content, m.Time) {
+		content += " at " + m.Time
+	}
+	if m.Person != "" && !strings.Contains(strings.ToLower(content), strings.ToLower(m.Person)) {
+		content += " (" + m.Person + ")"
+	}
+	return content
+}
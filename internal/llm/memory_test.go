@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExtractedMemory(t *testing.T) {
+	family := []string{"Maija", "Pekka"}
+
+	tests := []struct {
+		name        string
+		response    string
+		expected    ExtractedMemory
+		errContains string
+	}{
+		{
+			name:     "all fields",
+			response: `{"content": "Parent-teacher meeting", "date": "2025-04-22", "time": "18:00", "person": "maija"}`,
+			expected: ExtractedMemory{Content: "Parent-teacher meeting", Date: "2025-04-22", Time: "18:00", Person: "Maija"},
+		},
+		{
+			name:     "code fence and no date",
+			response: "```json\n{\"content\": \"Bob is allergic to nuts\", \"date\": \"\", \"time\": \"\", \"person\": \"\"}\n```",
+			expected: ExtractedMemory{Content: "Bob is allergic to nuts"},
+		},
+		{
+			name:        "not JSON",
+			response:    "Sure! The meeting is on Tuesday.",
+			errContains: "failed to parse",
+		},
+		{
+			name:        "no content",
+			response:    `{"content": " ", "date": "2025-04-22"}`,
+			errContains: "no content",
+		},
+		{
+			name:        "invalid date",
+			response:    `{"content": "Meeting", "date": "next Tuesday"}`,
+			errContains: "invalid date",
+		},
+		{
+			name:        "invalid time",
+			response:    `{"content": "Meeting", "time": "6pm"}`,
+			errContains: "invalid time",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			memory, err := ParseExtractedMemory(test.response, family)
+			if test.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), test.errContains) {
+					t.Fatalf("ParseExtractedMemory() error = %v, expected it to contain %q", err, test.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseExtractedMemory() error = %v", err)
+			}
+			if *memory != test.expected {
+				t.Errorf("ParseExtractedMemory() = %+v, expected %+v", *memory, test.expected)
+			}
+		})
+	}
+}
+
+func TestExtractedMemoryContent(t *testing.T) {
+	memory := ExtractedMemory{Content: "Parent-teacher meeting", Date: "2025-04-22", Time: "18:00", Person: "Maija"}
+
+	if got, expected := memory.MemoryContent(), "Parent-teacher meeting at 18:00 (Maija)"; got != expected {
+		t.Errorf("MemoryContent() = %q, expected %q", got, expected)
+	}
+
+	memory.Content = "Maija's parent-teacher meeting at 18:00"
+	if got := memory.MemoryContent(); got != memory.Content {
+		t.Errorf("MemoryContent() = %q, expected the content unchanged", got)
+	}
+
+	date := memory.RelevanceDate()
+	if date == nil || !date.Equal(time.Date(2025, 4, 22, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("RelevanceDate() = %v, expected 2025-04-22", date)
+	}
+
+	memory.Date = ""
+	if memory.RelevanceDate() != nil {
+		t.Errorf("RelevanceDate() = %v, expected nil", memory.RelevanceDate())
+	}
+}
+
+func TestBuildMemoryPrompt(t *testing.T) {
+	prompts := map[string][]string{
+		"parseMemory": {"{{.Today}} {{.Timezone}} {{join .Family \", \"}}: {{.Text}}"},
+	}
+
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+
+	got, err := BuildMemoryPrompt(prompts, "Dentist tomorrow", time.Date(2025, 4, 18, 7, 30, 0, 0, helsinki), []string{"Maija", "Pekka"})
+	if err != nil {
+		t.Fatalf("BuildMemoryPrompt() error = %v", err)
+	}
+
+	expected := "Friday 2025-04-18 Europe/Helsinki Maija, Pekka: Dentist tomorrow"
+	if got != expected {
+		t.Errorf("BuildMemoryPrompt() = %q, expected %q", got, expected)
+	}
+}
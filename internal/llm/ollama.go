@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OllamaClient is a client for a local Ollama server
+type OllamaClient struct {
+	baseURL    string
+	model      string
+	prompts    map[string][]string
+	httpClient *http.Client
+
+	callHooks
+}
+
+// ollamaGenerateRequest is the request body for the /api/generate endpoint
+type ollamaGenerateRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+}
+
+// ollamaGenerateResponse is the response body from the /api/generate endpoint
+type ollamaGenerateResponse struct {
+	Model    string `json:"model"`
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+
+	PromptEvalCount int `json:"prompt_eval_count"` // Input tokens
+	EvalCount       int `json:"eval_count"`        // Output tokens
+}
+
+// ollamaTagsResponse is the response body from the /api/tags endpoint
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
+
+// NewOllamaClient creates a new Ollama client with the given server URL, model name, and prompts
+func NewOllamaClient(baseURL string, model string, prompts map[string][]string) *OllamaClient {
+	return &OllamaClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		prompts: prompts,
+		httpClient: &http.Client{
+			// Local generation on CPU-only machines can take several minutes
+			Timeout: 5 * time.Minute,
+		},
+	}
+}
+
+// Name returns the provider identifier
+func (c *OllamaClient) Name() string {
+	return ProviderOllama
+}
+
+// Model returns the Ollama model used for generation
+func (c *OllamaClient) Model() string {
+	return c.model
+}
+
+// Close is a no-op for the Ollama client
+func (c *OllamaClient) Close() error {
+	return nil
+}
+
+// Generate generates content using the Ollama /api/generate endpoint
+func (c *OllamaClient) Generate(ctx context.Context, promptKey string, outputLanguage string, promptContent string) (string, error) {
+	return c.run(ctx, ProviderOllama, c.model, promptKey, promptContent, func() (string, Usage, error) {
+		return c.generate(ctx, promptContent)
+	})
+}
+
+// generate sends the prompt to Ollama and returns the cleaned response text and the
+// token usage
+func (c *OllamaClient) generate(ctx context.Context, promptContent string) (string, Usage, error) {
+	reqBody := ollamaGenerateRequest{
+		Model:  c.model,
+		Prompt: promptContent,
+		Stream: false,
+	}
+
+	var resp ollamaGenerateResponse
+	if err := doJSON(ctx, c.httpClient, http.MethodPost, c.baseURL+"/api/generate", "", reqBody, &resp); err != nil {
+		return "", Usage{}, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	usage := Usage{InputTokens: resp.PromptEvalCount, OutputTokens: resp.EvalCount}
+	if resp.Response == "" {
+		return "", usage, fmt.Errorf("no content generated")
+	}
+
+	return cleanMarkdownWrapper(resp.Response), usage, nil
+}
+
+// GenerateBrief generates a brief based on the provided brief context
+func (c *OllamaClient) GenerateBrief(ctx context.Context, bc *BriefContext) (string, error) {
+	promptContent, err := BuildBriefPrompt(c.prompts, bc)
+	if err != nil {
+		return "", err
+	}
+	return c.Generate(ctx, "dailyBrief", bc.Language, promptContent)
+}
+
+// GenerateResponse generates a response to a user query
+func (c *OllamaClient) GenerateResponse(ctx context.Context, query string, memories []string, outputLanguage string) (string, error) {
+	promptContent, err := BuildResponsePrompt(c.prompts, query, memories, outputLanguage)
+	if err != nil {
+		return "", err
+	}
+	return c.Generate(ctx, "userQuery", outputLanguage, promptContent)
+}
+
+// ListModels lists the models available on the Ollama server
+func (c *OllamaClient) ListModels(ctx context.Context) ([]string, error) {
+	var resp ollamaTagsResponse
+	if err := doJSON(ctx, c.httpClient, http.MethodGet, c.baseURL+"/api/tags", "", nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+
+	var modelNames []string
+	for _, model := range resp.Models {
+		modelNames = append(modelNames, model.Name)
+	}
+
+	if len(modelNames) == 0 {
+		return nil, fmt.Errorf("no models found on the Ollama server - pull one with 'ollama pull <model>'")
+	}
+
+	return modelNames, nil
+}
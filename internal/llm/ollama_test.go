@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOllamaClientGenerate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+
+		var req ollamaGenerateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Model != "llama3" {
+			t.Errorf("expected model llama3, got %q", req.Model)
+		}
+		if req.Stream {
+			t.Error("expected stream to be false")
+		}
+
+		_ = json.NewEncoder(w).Encode(ollamaGenerateResponse{
+			Model:    req.Model,
+			Response: "```markdown\nHyvää huomenta!\n```",
+			Done:     true,
+		})
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL+"/", "llama3", nil)
+	got, err := client.Generate(context.Background(), "dailyBrief", "Finnish", "prompt")
+	if err != nil {
+		t.Fatalf("Generate() returned an error: %v", err)
+	}
+	if got != "Hyvää huomenta!" {
+		t.Errorf("Generate() = %q, expected %q", got, "Hyvää huomenta!")
+	}
+}
+
+func TestOllamaClientGenerateErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"model 'llama3' not found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, "llama3", nil)
+	if _, err := client.Generate(context.Background(), "dailyBrief", "Finnish", "prompt"); err == nil {
+		t.Error("Generate() expected an error for a 404 response")
+	}
+}
+
+func TestOllamaClientListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"models":[{"name":"llama3:latest"},{"name":"mistral:latest"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, "llama3", nil)
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels() returned an error: %v", err)
+	}
+	if len(models) != 2 || models[0] != "llama3:latest" || models[1] != "mistral:latest" {
+		t.Errorf("ListModels() = %v", models)
+	}
+}
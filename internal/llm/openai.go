@@ -0,0 +1,153 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIClient is a client for any OpenAI-compatible chat completions API
+// (OpenAI, llama.cpp server, vLLM, LM Studio, etc.)
+type OpenAIClient struct {
+	baseURL    string
+	model      string
+	apiKey     string
+	prompts    map[string][]string
+	httpClient *http.Client
+
+	callHooks
+}
+
+// openAIMessage is a single chat message in the OpenAI chat format
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// openAIChatRequest is the request body for the /chat/completions endpoint
+type openAIChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+}
+
+// openAIChatResponse is the response body from the /chat/completions endpoint
+type openAIChatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      openAIMessage `json:"message"`
+		FinishReason string        `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// openAIModelsResponse is the response body from the /models endpoint
+type openAIModelsResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// NewOpenAIClient creates a new OpenAI-compatible client. The base URL should include
+// the API version path, e.g. "https://api.openai.com/v1" or "http://localhost:8080/v1".
+// The API key is optional for local servers.
+func NewOpenAIClient(baseURL, model, apiKey string, prompts map[string][]string) *OpenAIClient {
+	return &OpenAIClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		apiKey:  apiKey,
+		prompts: prompts,
+		httpClient: &http.Client{
+			// Self-hosted servers can be slow on modest hardware
+			Timeout: 5 * time.Minute,
+		},
+	}
+}
+
+// Name returns the provider identifier
+func (c *OpenAIClient) Name() string {
+	return ProviderOpenAI
+}
+
+// Model returns the model used for generation
+func (c *OpenAIClient) Model() string {
+	return c.model
+}
+
+// Close is a no-op for the OpenAI-compatible client
+func (c *OpenAIClient) Close() error {
+	return nil
+}
+
+// Generate generates content using the /chat/completions endpoint
+func (c *OpenAIClient) Generate(ctx context.Context, promptKey string, outputLanguage string, promptContent string) (string, error) {
+	return c.run(ctx, ProviderOpenAI, c.model, promptKey, promptContent, func() (string, Usage, error) {
+		return c.generate(ctx, promptContent)
+	})
+}
+
+// generate sends the prompt to the chat completions endpoint and returns the cleaned
+// response text and the token usage
+func (c *OpenAIClient) generate(ctx context.Context, promptContent string) (string, Usage, error) {
+	reqBody := openAIChatRequest{
+		Model: c.model,
+		Messages: []openAIMessage{
+			{Role: "user", Content: promptContent},
+		},
+		Stream: false,
+	}
+
+	var resp openAIChatResponse
+	if err := doJSON(ctx, c.httpClient, http.MethodPost, c.baseURL+"/chat/completions", c.apiKey, reqBody, &resp); err != nil {
+		return "", Usage{}, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	usage := Usage{InputTokens: resp.Usage.PromptTokens, OutputTokens: resp.Usage.CompletionTokens}
+	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+		return "", usage, fmt.Errorf("no content generated")
+	}
+
+	return cleanMarkdownWrapper(resp.Choices[0].Message.Content), usage, nil
+}
+
+// GenerateBrief generates a brief based on the provided brief context
+func (c *OpenAIClient) GenerateBrief(ctx context.Context, bc *BriefContext) (string, error) {
+	promptContent, err := BuildBriefPrompt(c.prompts, bc)
+	if err != nil {
+		return "", err
+	}
+	return c.Generate(ctx, "dailyBrief", bc.Language, promptContent)
+}
+
+// GenerateResponse generates a response to a user query
+func (c *OpenAIClient) GenerateResponse(ctx context.Context, query string, memories []string, outputLanguage string) (string, error) {
+	promptContent, err := BuildResponsePrompt(c.prompts, query, memories, outputLanguage)
+	if err != nil {
+		return "", err
+	}
+	return c.Generate(ctx, "userQuery", outputLanguage, promptContent)
+}
+
+// ListModels lists the models available from the /models endpoint
+func (c *OpenAIClient) ListModels(ctx context.Context) ([]string, error) {
+	var resp openAIModelsResponse
+	if err := doJSON(ctx, c.httpClient, http.MethodGet, c.baseURL+"/models", c.apiKey, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+
+	var modelNames []string
+	for _, model := range resp.Data {
+		modelNames = append(modelNames, model.ID)
+	}
+
+	if len(modelNames) == 0 {
+		return nil, fmt.Errorf("no models returned by the API at %s", c.baseURL)
+	}
+
+	return modelNames, nil
+}
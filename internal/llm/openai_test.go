@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newOpenAIStubServer returns a test server emulating an OpenAI-compatible API
+func newOpenAIStubServer(t *testing.T, wantAuth string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != wantAuth {
+			t.Errorf("Authorization header = %q, expected %q", got, wantAuth)
+		}
+
+		switch r.URL.Path {
+		case "/v1/chat/completions":
+			var req openAIChatRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("failed to decode request: %v", err)
+			}
+			if req.Model != "local-model" {
+				t.Errorf("expected model local-model, got %q", req.Model)
+			}
+			if len(req.Messages) != 1 || req.Messages[0].Role != "user" || req.Messages[0].Content != "prompt" {
+				t.Errorf("unexpected messages: %+v", req.Messages)
+			}
+			_, _ = w.Write([]byte(`{"model":"local-model","choices":[{"message":{"role":"assistant","content":"Hyvää huomenta!"},"finish_reason":"stop"}]}`))
+		case "/v1/models":
+			_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"local-model"},{"id":"other-model"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestOpenAIClientGenerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		apiKey   string
+		wantAuth string
+	}{
+		{name: "hosted API with key", apiKey: "secret", wantAuth: "Bearer secret"},
+		{name: "local server without key", apiKey: "", wantAuth: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newOpenAIStubServer(t, tt.wantAuth)
+			defer server.Close()
+
+			client := NewOpenAIClient(server.URL+"/v1/", "local-model", tt.apiKey, nil)
+			got, err := client.Generate(context.Background(), "dailyBrief", "Finnish", "prompt")
+			if err != nil {
+				t.Fatalf("Generate() returned an error: %v", err)
+			}
+			if got != "Hyvää huomenta!" {
+				t.Errorf("Generate() = %q, expected %q", got, "Hyvää huomenta!")
+			}
+		})
+	}
+}
+
+func TestOpenAIClientGenerateNoChoices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"choices":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL, "local-model", "", nil)
+	if _, err := client.Generate(context.Background(), "dailyBrief", "Finnish", "prompt"); err == nil {
+		t.Error("Generate() expected an error when no choices are returned")
+	}
+}
+
+func TestOpenAIClientListModels(t *testing.T) {
+	server := newOpenAIStubServer(t, "Bearer secret")
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL+"/v1", "local-model", "secret", nil)
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels() returned an error: %v", err)
+	}
+	if len(models) != 2 || models[0] != "local-model" || models[1] != "other-model" {
+		t.Errorf("ListModels() = %v", models)
+	}
+}
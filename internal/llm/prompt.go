@@ -0,0 +1,235 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The legacy placeholders are still supported in prompts and are converted to the
+// equivalent template actions before parsing, see legacyPlaceholders.
+const (
+	// PromptContextPlaceholder is the placeholder for context in prompts.
+	PromptContextPlaceholder = "%CONTEXT%"
+	// PromptNotesPlaceholder is the placeholder for notes/memories in prompts.
+	PromptNotesPlaceholder = "%NOTES%"
+	// PromptLanguagePlaceholder is the placeholder for the output language in prompts.
+	PromptLanguagePlaceholder = "%LANG%"
+	// PromptQueryPlaceholder is the placeholder for user queries in prompts.
+	PromptQueryPlaceholder = "%QUERY%"
+)
+
+// formatMemories formats memories as a bulleted list
+func formatMemories(memories []string) string {
+	var memoryBuilder strings.Builder
+	for _, memory := range memories {
+		fmt.Fprintf(&memoryBuilder, "- %s\n", memory)
+	}
+	return memoryBuilder.String()
+}
+
+// formatBriefEvent formats a calendar event as a single line for the LLM context
+func formatBriefEvent(event BriefEvent) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "Calendar Event: %s", event.Summary)
+
+	switch {
+	case event.AllDay():
+		builder.WriteString(" (all day)")
+	case event.End != nil:
+		endFormat := "15:04"
+		// Show the full end date if the event spans multiple days
+		if event.Start.Format("2006-01-02") != event.End.Format("2006-01-02") {
+			endFormat = "2006-01-02 15:04"
+		}
+		fmt.Fprintf(&builder, " from %s to %s", event.Start.Format("15:04"), event.End.Format(endFormat))
+	default:
+		fmt.Fprintf(&builder, " at %s", event.Start.Format("15:04"))
+	}
+
+	if event.Location != "" {
+		fmt.Fprintf(&builder, " at %s", event.Location)
+	}
+
+	if event.Description != "" {
+		fmt.Fprintf(&builder, ". Description: %s", event.Description)
+	}
+
+	fmt.Fprintf(&builder, " [Source: %s]", event.Source)
+
+	return builder.String()
+}
+
+// currentTimeLabel starts the line of the brief prompt giving the current time
+const currentTimeLabel = "- Current Time: "
+
+// formatBriefHeader formats the general context information (date, location, family, etc.)
+func formatBriefHeader(bc *BriefContext) string {
+	var contextBuilder strings.Builder
+
+	fmt.Fprintf(&contextBuilder, "- Current Date: %s\n", bc.Now.Format("Monday, 2 January 2006"))
+	fmt.Fprintf(&contextBuilder, "%s%s\n", currentTimeLabel, bc.Now.Format("15:04"))
+
+	if bc.Timezone != "" {
+		fmt.Fprintf(&contextBuilder, "- Timezone: %s\n", bc.Timezone)
+	}
+
+	if bc.Location != "" {
+		fmt.Fprintf(&contextBuilder, "- Location: %s\n", bc.Location)
+	}
+
+	if len(bc.Family) > 0 {
+		fmt.Fprintf(&contextBuilder, "- Family Members: %s\n", strings.Join(bc.Family, ", "))
+	}
+
+	if len(bc.Birthdays) > 0 {
+		var birthdays []string
+		for _, birthday := range bc.Birthdays {
+			birthdays = append(birthdays, fmt.Sprintf("%s (%d years)", birthday.Name, birthday.Age))
+		}
+		fmt.Fprintf(&contextBuilder, "- Birthdays Today: %s\n", strings.Join(birthdays, ", "))
+	}
+
+	if len(bc.Ongoing) > 0 {
+		contextBuilder.WriteString("- Currently Ongoing:\n")
+		for _, event := range bc.Ongoing {
+			if event.End != nil {
+				fmt.Fprintf(&contextBuilder, "  * %s (until %s)\n", event.Summary, event.End.Format("15:04"))
+			} else {
+				fmt.Fprintf(&contextBuilder, "  * %s\n", event.Summary)
+			}
+		}
+	}
+
+	return contextBuilder.String()
+}
+
+// formatBriefDays formats the per-day information (weather, events, meals, prices, notes)
+// followed by the notes that aren't tied to a specific day
+func formatBriefDays(bc *BriefContext) string {
+	var builder strings.Builder
+
+	for _, day := range bc.Days {
+		fmt.Fprintf(&builder, "%s:\n", day.Date.Format("Monday, 2 January 2006"))
+
+		if day.Weather != "" {
+			fmt.Fprintf(&builder, "- Weather: %s\n", day.Weather)
+		} else {
+			builder.WriteString("- Weather: not available\n")
+		}
+
+		if day.HourlyForecast != "" {
+			fmt.Fprintf(&builder, "- %s\n", day.HourlyForecast)
+		}
+
+		for _, event := range day.Events {
+			fmt.Fprintf(&builder, "- %s\n", formatBriefEvent(event))
+		}
+
+		for _, meal := range day.Meals {
+			fmt.Fprintf(&builder, "- School lunch: %s\n", strings.ReplaceAll(meal, "\n", "; "))
+		}
+
+		if day.Prices != "" {
+			fmt.Fprintf(&builder, "- %s\n", strings.ReplaceAll(day.Prices, "\n", " "))
+		}
+
+		for _, note := range day.Notes {
+			fmt.Fprintf(&builder, "- %s\n", note)
+		}
+
+		builder.WriteString("\n")
+	}
+
+	if len(bc.Notes) > 0 {
+		builder.WriteString("Other notes:\n")
+		builder.WriteString(formatMemories(bc.Notes))
+	}
+
+	return builder.String()
+}
+
+// briefPromptData returns the dailyBrief template data for the brief context. The
+// preformatted context and notes contain imported text, so they're fenced as data.
+func briefPromptData(bc *BriefContext) BriefPromptData {
+	return BriefPromptData{
+		Brief:   bc,
+		Context: FenceUntrusted(formatBriefHeader(bc)),
+		Notes:   FenceUntrusted(formatBriefDays(bc)),
+		Lang:    bc.Language,
+	}
+}
+
+// BuildBriefPrompt builds the prompt content for a brief without sending it to the LLM
+func BuildBriefPrompt(prompts map[string][]string, bc *BriefContext) (string, error) {
+	return BuildBriefPromptVariant(prompts, "dailyBrief", bc)
+}
+
+// BuildBriefPromptVariant builds the prompt content for a brief with the given dailyBrief
+// prompt or one of its variants, e.g. "dailyBrief:concise"
+func BuildBriefPromptVariant(prompts map[string][]string, key string, bc *BriefContext) (string, error) {
+	return renderPrompt(prompts, key, briefPromptData(bc))
+}
+
+// BuildBriefFeedbackPrompt builds the correction appended to the dailyBrief prompt when a
+// brief is regenerated because of the issues found in it
+func BuildBriefFeedbackPrompt(prompts map[string][]string, issues []string, outputLanguage string) (string, error) {
+	data := BriefFeedbackPromptData{
+		Issues: issues,
+		Lang:   outputLanguage,
+	}
+	return renderPrompt(prompts, "briefFeedback", data)
+}
+
+// FormatBriefContext formats the brief context as it's given to the dailyBrief prompt:
+// the general information followed by the days
+func FormatBriefContext(bc *BriefContext) string {
+	return formatBriefHeader(bc) + "\n" + formatBriefDays(bc)
+}
+
+// BuildResponsePrompt builds the prompt content for answering a user query
+func BuildResponsePrompt(prompts map[string][]string, query string, memories []string, outputLanguage string) (string, error) {
+	data := ResponsePromptData{
+		Query:    query,
+		Memories: memories,
+		Notes:    FenceUntrusted(formatMemories(memories)),
+		Lang:     outputLanguage,
+	}
+	return renderPrompt(prompts, "userQuery", data)
+}
+
+// BuildChatPrompt builds the prompt content for the next reply in a chat conversation
+func BuildChatPrompt(prompts map[string][]string, message string, history []ChatTurn, summary string, memories []string, outputLanguage string) (string, error) {
+	data := ChatPromptData{
+		Message:  message,
+		History:  history,
+		Summary:  summary,
+		Memories: memories,
+		Notes:    FenceUntrusted(formatMemories(memories)),
+		Lang:     outputLanguage,
+	}
+	return renderPrompt(prompts, "chat", data)
+}
+
+// BuildMemoryPrompt builds the prompt content for extracting a memory from free text.
+// Relative dates in the text are resolved against now.
+func BuildMemoryPrompt(prompts map[string][]string, text string, now time.Time, family []string) (string, error) {
+	data := MemoryPromptData{
+		Text:     text,
+		Today:    now.Format("Monday 2006-01-02"),
+		Timezone: now.Location().String(),
+		Family:   family,
+	}
+	return renderPrompt(prompts, "parseMemory", data)
+}
+
+// BuildChatSummaryPrompt builds the prompt content for summarizing older chat turns
+func BuildChatSummaryPrompt(prompts map[string][]string, summary string, turns []ChatTurn, outputLanguage string) (string, error) {
+	data := ChatSummaryPromptData{
+		Summary: summary,
+		Turns:   turns,
+		Lang:    outputLanguage,
+	}
+	return renderPrompt(prompts, "chatSummary", data)
+}
@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildBriefPrompt(t *testing.T) {
+	prompts := map[string][]string{
+		"dailyBrief": {"Language: %LANG%", "Context:", "%CONTEXT%", "Notes:", "%NOTES%"},
+	}
+
+	now := time.Date(2025, 3, 10, 7, 30, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+	bc := &BriefContext{
+		Now:       now,
+		Timezone:  "UTC",
+		Location:  "Helsinki",
+		Language:  "English",
+		Family:    []string{"Alice", "Bob"},
+		Birthdays: []Birthday{{Name: "Alice", Age: 40}},
+		Days: []BriefDay{
+			{
+				Date:           time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+				Weather:        "sunny, temperature 1-5°C",
+				HourlyForecast: "Hourly forecast for today: 08:00: 1°C (clearsky_day)",
+				Events: []BriefEvent{
+					{Summary: "Dentist", Start: time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC), End: &end, Location: "Clinic", Source: "calendar:Family"},
+				},
+				Meals: []string{"Lounas: Pasta\nKasvislounas: Soup"},
+			},
+		},
+		Notes: []string{"Alice is allergic to nuts [Source: manual]"},
+	}
+
+	got, err := BuildBriefPrompt(prompts, bc)
+	if err != nil {
+		t.Fatalf("BuildBriefPrompt() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"Language: English",
+		"- Current Date: Monday, 10 March 2025",
+		"- Current Time: 07:30",
+		"- Family Members: Alice, Bob",
+		"- Birthdays Today: Alice (40 years)",
+		"Monday, 10 March 2025:\n- Weather: sunny, temperature 1-5°C",
+		"- Hourly forecast for today: 08:00: 1°C (clearsky_day)",
+		"- Calendar Event: Dentist from 09:00 to 10:00 at Clinic [Source: calendar:Family]",
+		"- School lunch: Lounas: Pasta; Kasvislounas: Soup",
+		"Other notes:\n- Alice is allergic to nuts [Source: manual]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompt missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+)
+
+const (
+	// ProviderGemini is the identifier for the Google Gemini provider
+	ProviderGemini = "gemini"
+	// ProviderOllama is the identifier for the local Ollama provider
+	ProviderOllama = "ollama"
+	// ProviderOpenAI is the identifier for OpenAI-compatible chat completions APIs
+	ProviderOpenAI = "openai"
+)
+
+// Provider is the provider-agnostic interface for LLM backends
+type Provider interface {
+	// Name returns the provider identifier (e.g. "gemini", "ollama", "openai")
+	Name() string
+
+	// Model returns the name of the model used for generation
+	Model() string
+
+	// Generate sends the prompt content to the LLM and returns the generated text
+	Generate(ctx context.Context, promptKey string, outputLanguage string, promptContent string) (string, error)
+
+	// GenerateBrief generates a daily brief based on the provided brief context
+	GenerateBrief(ctx context.Context, bc *BriefContext) (string, error)
+
+	// GenerateResponse generates a response to a user query
+	GenerateResponse(ctx context.Context, query string, memories []string, outputLanguage string) (string, error)
+
+	// ListModels lists the models available from the provider
+	ListModels(ctx context.Context) ([]string, error)
+
+	// SetRecorder sets the recorder that every Generate call is reported to
+	SetRecorder(recorder CallRecorder)
+
+	// SetCache sets the cache that responses are looked up from and stored in
+	SetCache(cache ResponseCache)
+
+	// Close releases any resources held by the provider
+	Close() error
+}
+
+// NewProvider creates the LLM provider selected by the configuration. If llm_chain is
+// configured, the entries are tried in order; otherwise the single provider selected by
+// llm_provider is used. In both cases retryable errors are retried with exponential backoff.
+func NewProvider(cfg *config.Config, prompts map[string][]string) (Provider, error) {
+	entries := cfg.LLMChain
+	if len(entries) == 0 {
+		entries = []config.LLMChainEntry{{Provider: cfg.LLMProvider}}
+	}
+
+	var providers []Provider
+	for _, entry := range entries {
+		provider, err := newSingleProvider(cfg, entry, prompts)
+		if err != nil {
+			for _, created := range providers {
+				_ = created.Close()
+			}
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	retryDelay := time.Duration(cfg.LLMRetryDelaySeconds) * time.Second
+	return NewChain(providers, cfg.LLMMaxRetries, retryDelay), nil
+}
+
+// newSingleProvider creates the provider for a single chain entry. If the entry doesn't
+// specify a model, the provider's configured model is used.
+func newSingleProvider(cfg *config.Config, entry config.LLMChainEntry, prompts map[string][]string) (Provider, error) {
+	switch entry.Provider {
+	case ProviderGemini, "":
+		return NewClient(cfg.GeminiAPIKey, cmp.Or(entry.Model, cfg.GeminiModel), prompts)
+	case ProviderOllama:
+		return NewOllamaClient(cfg.OllamaURL, cmp.Or(entry.Model, cfg.OllamaModel), prompts), nil
+	case ProviderOpenAI:
+		return NewOpenAIClient(cfg.OpenAIBaseURL, cmp.Or(entry.Model, cfg.OpenAIModel), cfg.OpenAIAPIKey, prompts), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider: %s", entry.Provider)
+	}
+}
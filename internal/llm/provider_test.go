@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+)
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		wantName string
+		wantErr  bool
+	}{
+		{name: "ollama provider", provider: "ollama", wantName: ProviderOllama},
+		{name: "openai provider", provider: "openai", wantName: ProviderOpenAI},
+		{name: "unknown provider", provider: "skynet", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				LLMProvider:   tt.provider,
+				OllamaURL:     "http://localhost:11434",
+				OllamaModel:   "llama3",
+				OpenAIBaseURL: "http://localhost:8080/v1",
+				OpenAIModel:   "local-model",
+			}
+			provider, err := NewProvider(cfg, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("NewProvider() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewProvider() returned an error: %v", err)
+			}
+			if provider.Name() != tt.wantName {
+				t.Errorf("Name() = %q, expected %q", provider.Name(), tt.wantName)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+)
+
+// ScriptedProvider is a Provider for tests that answers with scripted responses in order,
+// e.g. a tool call request followed by the final answer. The prompts it was sent are kept.
+type ScriptedProvider struct {
+	Responses []string
+	// Prompts are the prompt contents sent so far
+	Prompts []string
+}
+
+// Name returns the provider identifier
+func (p *ScriptedProvider) Name() string {
+	return "scripted"
+}
+
+// Model returns the model name
+func (p *ScriptedProvider) Model() string {
+	return "scripted-model"
+}
+
+// Close is a no-op
+func (p *ScriptedProvider) Close() error {
+	return nil
+}
+
+// SetRecorder is a no-op, scripted calls aren't recorded
+func (p *ScriptedProvider) SetRecorder(recorder CallRecorder) {}
+
+// SetCache is a no-op, scripted calls aren't cached
+func (p *ScriptedProvider) SetCache(cache ResponseCache) {}
+
+// Generate returns the next scripted response
+func (p *ScriptedProvider) Generate(ctx context.Context, promptKey string, outputLanguage string, promptContent string) (string, error) {
+	p.Prompts = append(p.Prompts, promptContent)
+	if len(p.Prompts) > len(p.Responses) {
+		return "", fmt.Errorf("no scripted response for call %d", len(p.Prompts))
+	}
+	return p.Responses[len(p.Prompts)-1], nil
+}
+
+// GenerateBrief returns the next scripted response
+func (p *ScriptedProvider) GenerateBrief(ctx context.Context, bc *BriefContext) (string, error) {
+	return p.Generate(ctx, "dailyBrief", bc.Language, "")
+}
+
+// GenerateResponse returns the next scripted response
+func (p *ScriptedProvider) GenerateResponse(ctx context.Context, query string, memories []string, outputLanguage string) (string, error) {
+	return p.Generate(ctx, "userQuery", outputLanguage, query)
+}
+
+// ListModels returns the scripted model
+func (p *ScriptedProvider) ListModels(ctx context.Context) ([]string, error) {
+	return []string{p.Model()}, nil
+}
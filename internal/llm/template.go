@@ -0,0 +1,309 @@
+package llm
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// BriefPromptData is the data available to the dailyBrief prompt template
+type BriefPromptData struct {
+	// Brief is the full structured brief context, for templates that loop over days
+	Brief *BriefContext
+	// Context is the preformatted general context, the same as the legacy %CONTEXT%
+	Context string
+	// Notes is the preformatted per-day information, the same as the legacy %NOTES%
+	Notes string
+	// Lang is the output language, the same as the legacy %LANG%
+	Lang string
+}
+
+// ResponsePromptData is the data available to the userQuery prompt template
+type ResponsePromptData struct {
+	// Query is the user's question, the same as the legacy %QUERY%
+	Query string
+	// Memories are the relevant memories as individual strings
+	Memories []string
+	// Notes is the preformatted memory list, the same as the legacy %NOTES%
+	Notes string
+	// Lang is the output language, the same as the legacy %LANG%
+	Lang string
+}
+
+// ChatTurn is a single turn of a chat conversation
+type ChatTurn struct {
+	// Role is "user" or "assistant"
+	Role    string
+	Content string
+}
+
+// ChatPromptData is the data available to the chat prompt template
+type ChatPromptData struct {
+	// Message is the user's new message
+	Message string
+	// History is the recent conversation, oldest first
+	History []ChatTurn
+	// Summary summarizes the conversation before History, empty for short conversations
+	Summary string
+	// Memories are the retrieved memories and calendar events as individual strings
+	Memories []string
+	// Notes is the preformatted memory list
+	Notes string
+	// Lang is the output language
+	Lang string
+}
+
+// ChatSummaryPromptData is the data available to the chatSummary prompt template
+type ChatSummaryPromptData struct {
+	// Summary is the earlier summary to extend, empty if there's none yet
+	Summary string
+	// Turns are the conversation turns to summarize, oldest first
+	Turns []ChatTurn
+	// Lang is the output language
+	Lang string
+}
+
+// MemoryPromptData is the data available to the parseMemory prompt template
+type MemoryPromptData struct {
+	// Text is the memory as the user wrote it
+	Text string
+	// Today is the current date in the configured timezone, e.g. "Friday 2025-04-18"
+	Today string
+	// Timezone is the name of the configured timezone
+	Timezone string
+	// Family are the names of the family members
+	Family []string
+}
+
+// ToolPromptData is the data available to the tools prompt template, which is appended to
+// prompts sent with tools
+type ToolPromptData struct {
+	// Tools are the tools the model can call, empty when it has to answer
+	Tools []Tool
+	// Results are the results of the tool calls so far, oldest first
+	Results []ToolResult
+}
+
+// BriefFeedbackPromptData is the data available to the briefFeedback prompt template, which
+// is appended to the dailyBrief prompt when a brief is regenerated
+type BriefFeedbackPromptData struct {
+	// Issues are the details of the previous brief that weren't found in the context
+	Issues []string
+	// Lang is the output language
+	Lang string
+}
+
+// legacyPlaceholders maps the old %PLACEHOLDER% syntax to the equivalent template actions
+var legacyPlaceholders = strings.NewReplacer(
+	PromptContextPlaceholder, "{{.Context}}",
+	PromptNotesPlaceholder, "{{.Notes}}",
+	PromptLanguagePlaceholder, "{{.Lang}}",
+	PromptQueryPlaceholder, "{{.Query}}",
+)
+
+// promptFuncs are the helper functions available in prompt templates
+var promptFuncs = template.FuncMap{
+	"join":    strings.Join,
+	"bullets": formatMemories,
+	"event":   formatBriefEvent,
+}
+
+// parsePrompt joins the lines of a prompt and parses it as a template. Legacy
+// placeholders are converted to template actions first.
+func parsePrompt(key string, lines []string) (*template.Template, error) {
+	text := legacyPlaceholders.Replace(strings.Join(lines, "\n"))
+
+	tmpl, err := template.New(key).Funcs(promptFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt %q: %w", key, err)
+	}
+
+	return tmpl, nil
+}
+
+// renderPrompt renders the prompt with the given key using data
+func renderPrompt(prompts map[string][]string, key string, data any) (string, error) {
+	lines, ok := prompts[key]
+	if !ok {
+		return "", fmt.Errorf("prompt %q not found", key)
+	}
+
+	tmpl, err := parsePrompt(key, lines)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %q: %w", key, err)
+	}
+
+	return builder.String(), nil
+}
+
+// SampleBriefContext returns a small but complete brief context, used to validate
+// prompt templates without touching the database
+func SampleBriefContext() *BriefContext {
+	now := time.Date(2025, 4, 18, 7, 30, 0, 0, time.UTC)
+	today := time.Date(2025, 4, 18, 0, 0, 0, 0, time.UTC)
+	end := today.Add(10 * time.Hour)
+
+	return &BriefContext{
+		Now:       now,
+		Timezone:  "UTC",
+		Location:  "Helsinki",
+		Language:  "English",
+		Family:    []string{"Alice", "Bob"},
+		Birthdays: []Birthday{{Name: "Alice", Age: 40}},
+		Ongoing: []BriefEvent{
+			{Summary: "Spring break", Start: today.AddDate(0, 0, -2), Source: "calendar:Family"},
+		},
+		Days: []BriefDay{
+			{
+				Date:           today,
+				Weather:        "partly cloudy, temperature 3-11°C",
+				HourlyForecast: "Hourly forecast for today: 08:00: 4°C (partlycloudy_day)",
+				Events: []BriefEvent{
+					{Summary: "Dentist", Start: today.Add(9 * time.Hour), End: &end, Location: "Clinic", Source: "calendar:Family"},
+				},
+				Meals:  []string{"Lounas: Kalakeitto\nKasvislounas: Kasviskeitto"},
+				Prices: "Electricity prices on 2025-04-18 (CHEAP DAY - avg 3.1 c/kWh).",
+				Notes:  []string{"Return library books [Source: manual]"},
+			},
+			{
+				Date:    today.AddDate(0, 0, 1),
+				Weather: "rain, temperature 2-6°C",
+			},
+		},
+		Notes: []string{"Bob is allergic to nuts [Source: manual]"},
+	}
+}
+
+// PromptVariantSeparator separates a prompt key from the variant name in the keys of
+// prompt variants, e.g. "dailyBrief:concise"
+const PromptVariantSeparator = ":"
+
+// baseKey returns the prompt key without the variant name
+func baseKey(key string) string {
+	base, _, _ := strings.Cut(key, PromptVariantSeparator)
+	return base
+}
+
+// PromptVariants returns the key and the keys of its variants defined in the prompts, the
+// key itself first and the variants sorted by name
+func PromptVariants(prompts map[string][]string, key string) []string {
+	var variants []string
+	for k := range prompts {
+		if strings.HasPrefix(k, key+PromptVariantSeparator) {
+			variants = append(variants, k)
+		}
+	}
+	slices.Sort(variants)
+
+	if _, ok := prompts[key]; ok {
+		variants = append([]string{key}, variants...)
+	}
+	return variants
+}
+
+// samplePromptData returns sample template data for the prompt with the given key.
+// Variants are rendered with the data of their base prompt.
+func samplePromptData(key string) (any, bool) {
+	switch baseKey(key) {
+	case "dailyBrief":
+		return briefPromptData(SampleBriefContext()), true
+	case "userQuery":
+		memories := []string{
+			"Current date and time: Friday, 18 April 2025 07:30 (UTC)",
+			"Calendar Event: Dentist on Tuesday 2025-04-22 from 09:00 to 10:00 at Clinic [Source: calendar:Family]",
+			"Return library books (relevant on 2025-04-18) [Source: manual]",
+		}
+		return ResponsePromptData{
+			Query:    "What do I need to remember today?",
+			Memories: memories,
+			Notes:    formatMemories(memories),
+			Lang:     "English",
+		}, true
+	case "chat":
+		memories := []string{"Current date and time: Friday, 18 April 2025 07:30 (UTC)"}
+		return ChatPromptData{
+			Message: "And what about Thursday?",
+			History: []ChatTurn{
+				{Role: "user", Content: "What is on the calendar on Tuesday?"},
+				{Role: "assistant", Content: "A dentist visit at 09:00."},
+			},
+			Summary:  "The user asked about the school lunch.",
+			Memories: memories,
+			Notes:    formatMemories(memories),
+			Lang:     "English",
+		}, true
+	case "chatSummary":
+		return ChatSummaryPromptData{
+			Summary: "The user asked about the school lunch.",
+			Turns: []ChatTurn{
+				{Role: "user", Content: "What is on the calendar on Tuesday?"},
+				{Role: "assistant", Content: "A dentist visit at 09:00."},
+			},
+			Lang: "English",
+		}, true
+	case "parseMemory":
+		return MemoryPromptData{
+			Text:     "Maija's parent-teacher meeting next Tuesday at 18",
+			Today:    "Friday 2025-04-18",
+			Timezone: "Europe/Helsinki",
+			Family:   []string{"Maija", "Pekka"},
+		}, true
+	case "tools":
+		return ToolPromptData{
+			Tools: []Tool{
+				{
+					Name:        "get_weather",
+					Description: "Get the weather forecast for a day",
+					Parameters:  []ToolParameter{{Name: "date", Description: "Date as YYYY-MM-DD", Required: true}},
+				},
+			},
+			Results: []ToolResult{
+				{Call: ToolCall{Name: "get_weather", Args: map[string]string{"date": "2025-04-19"}}, Result: "rain, temperature 2-6°C"},
+			},
+		}, true
+	case "briefFeedback":
+		return BriefFeedbackPromptData{
+			Issues: []string{"time 09:30 isn't in the calendar or notes"},
+			Lang:   "Finnish",
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// PromptValidation is the result of validating a single prompt template
+type PromptValidation struct {
+	Key string
+	Err error
+}
+
+// ValidatePrompts renders every prompt against sample data and returns the result for
+// each prompt key, sorted by key
+func ValidatePrompts(prompts map[string][]string) []PromptValidation {
+	keys := make([]string, 0, len(prompts))
+	for key := range prompts {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var results []PromptValidation
+	for _, key := range keys {
+		data, ok := samplePromptData(key)
+		if !ok {
+			results = append(results, PromptValidation{Key: key, Err: fmt.Errorf("unknown prompt key %q", key)})
+			continue
+		}
+
+		_, err := renderPrompt(prompts, key, data)
+		results = append(results, PromptValidation{Key: key, Err: err})
+	}
+
+	return results
+}
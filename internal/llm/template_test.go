@@ -0,0 +1,144 @@
+package llm
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+)
+
+func TestRenderPrompt(t *testing.T) {
+	data := ResponsePromptData{
+		Query:    "When is the dentist?",
+		Memories: []string{"Dentist on Friday"},
+		Notes:    "- Dentist on Friday\n",
+		Lang:     "English",
+	}
+
+	tests := []struct {
+		name     string
+		lines    []string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "legacy placeholders",
+			lines:    []string{"Answer in %LANG%: %QUERY%", "%NOTES%"},
+			expected: "Answer in English: When is the dentist?\n- Dentist on Friday\n",
+		},
+		{
+			name:     "template actions",
+			lines:    []string{"{{.Query}}", "{{range .Memories}}* {{.}}{{end}}"},
+			expected: "When is the dentist?\n* Dentist on Friday",
+		},
+		{
+			name:    "parse error",
+			lines:   []string{"{{range .Memories}}"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			lines:   []string{"{{.Missing}}"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prompts := map[string][]string{"userQuery": test.lines}
+			got, err := renderPrompt(prompts, "userQuery", data)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("renderPrompt() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if !test.wantErr && got != test.expected {
+				t.Errorf("renderPrompt() = %q, expected %q", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestRenderPromptMissingKey(t *testing.T) {
+	if _, err := renderPrompt(map[string][]string{}, "dailyBrief", nil); err == nil {
+		t.Error("expected an error for a missing prompt")
+	}
+}
+
+func TestBriefPromptTemplateLoopsOverDays(t *testing.T) {
+	prompts := map[string][]string{
+		"dailyBrief": {`{{range .Brief.Days}}{{.Date.Format "Mon"}}: {{.Weather}}{{range .Events}} | {{event .}}{{end}}` + "\n" + `{{end}}`},
+	}
+
+	got, err := BuildBriefPrompt(prompts, SampleBriefContext())
+	if err != nil {
+		t.Fatalf("BuildBriefPrompt() error = %v", err)
+	}
+
+	if !strings.Contains(got, "Fri: partly cloudy, temperature 3-11°C | Calendar Event: Dentist from 09:00 to 10:00 at Clinic") {
+		t.Errorf("unexpected prompt:\n%s", got)
+	}
+	if !strings.Contains(got, "Sat: rain, temperature 2-6°C") {
+		t.Errorf("unexpected prompt:\n%s", got)
+	}
+}
+
+func TestValidatePrompts(t *testing.T) {
+	prompts := map[string][]string{
+		"dailyBrief":         {"%CONTEXT% {{len .Brief.Days}}"},
+		"dailyBrief:concise": {"{{.Notes}} in {{.Lang}}"},
+		"dailyBrief:broken":  {"{{.Query}}"},
+		"userQuery":          {"{{.Query"},
+		"unknown":            {"text"},
+	}
+
+	results := ValidatePrompts(prompts)
+	if len(results) != 5 {
+		t.Fatalf("expected 5 results, got %d", len(results))
+	}
+
+	expectErr := map[string]bool{
+		"dailyBrief":         false,
+		"dailyBrief:concise": false,
+		"dailyBrief:broken":  true,
+		"unknown":            true,
+		"userQuery":          true,
+	}
+	for _, result := range results {
+		if (result.Err != nil) != expectErr[result.Key] {
+			t.Errorf("%s: error = %v, expected error %v", result.Key, result.Err, expectErr[result.Key])
+		}
+	}
+}
+
+func TestPromptVariants(t *testing.T) {
+	prompts := map[string][]string{
+		"dailyBrief:short":   {"short"},
+		"dailyBrief":         {"default"},
+		"dailyBrief:concise": {"concise"},
+		"dailyBriefing":      {"other"},
+		"userQuery:short":    {"query"},
+	}
+
+	got := PromptVariants(prompts, "dailyBrief")
+	expected := []string{"dailyBrief", "dailyBrief:concise", "dailyBrief:short"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("PromptVariants() = %q, expected %q", got, expected)
+	}
+
+	if got := PromptVariants(prompts, "chat"); len(got) != 0 {
+		t.Errorf("PromptVariants() for a missing key = %q, expected none", got)
+	}
+}
+
+func TestDefaultPromptsValidate(t *testing.T) {
+	prompts, err := config.DefaultPrompts()
+	if err != nil {
+		t.Fatalf("DefaultPrompts() error = %v", err)
+	}
+
+	for _, result := range ValidatePrompts(prompts) {
+		if result.Err != nil {
+			t.Errorf("embedded prompt %s failed validation: %v", result.Key, result.Err)
+		}
+	}
+}
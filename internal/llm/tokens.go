@@ -0,0 +1,53 @@
+package llm
+
+import (
+	"cmp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+)
+
+// charsPerToken is the rough number of characters per token used for estimates. Real
+// tokenizers vary by model and language, but this is close enough for budgeting.
+const charsPerToken = 4
+
+// EstimateTokens estimates the number of tokens in the text
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// EstimateBriefContextTokens estimates the tokens of the brief context as it's given to
+// the dailyBrief prompt, without the prompt's own instructions
+func EstimateBriefContextTokens(bc *BriefContext) int {
+	return EstimateTokens(formatBriefHeader(bc)) + EstimateTokens(formatBriefDays(bc))
+}
+
+// ContextTokenBudget returns the token budget for the brief context sent to the model:
+// its llm_context_budgets entry, or llm_context_tokens for other models. 0 means no limit.
+func ContextTokenBudget(cfg *config.Config, model string) int {
+	for _, budget := range cfg.LLMContextBudgets {
+		if strings.EqualFold(budget.Model, model) {
+			return budget.Tokens
+		}
+	}
+	return cfg.LLMContextTokens
+}
+
+// PrimaryModel returns the model tried first with the configuration: the first llm_chain
+// entry, or the model of llm_provider
+func PrimaryModel(cfg *config.Config) string {
+	entry := config.LLMChainEntry{Provider: cfg.LLMProvider}
+	if len(cfg.LLMChain) > 0 {
+		entry = cfg.LLMChain[0]
+	}
+
+	switch entry.Provider {
+	case ProviderOllama:
+		return cmp.Or(entry.Model, cfg.OllamaModel)
+	case ProviderOpenAI:
+		return cmp.Or(entry.Model, cfg.OpenAIModel)
+	default:
+		return cmp.Or(entry.Model, cfg.GeminiModel)
+	}
+}
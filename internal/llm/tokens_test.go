@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"äöåäöå", 2}, // Characters, not bytes
+	}
+
+	for _, test := range tests {
+		if got := EstimateTokens(test.text); got != test.expected {
+			t.Errorf("EstimateTokens(%q) = %d, expected %d", test.text, got, test.expected)
+		}
+	}
+}
+
+func TestContextTokenBudget(t *testing.T) {
+	cfg := &config.Config{
+		LLMContextTokens:  8000,
+		LLMContextBudgets: []config.ContextBudget{{Model: "llama3.2", Tokens: 2000}},
+	}
+
+	if got := ContextTokenBudget(cfg, "Llama3.2"); got != 2000 {
+		t.Errorf("ContextTokenBudget() = %d, expected the per-model budget 2000", got)
+	}
+	if got := ContextTokenBudget(cfg, "gemini-2.5-flash"); got != 8000 {
+		t.Errorf("ContextTokenBudget() = %d, expected the default budget 8000", got)
+	}
+}
+
+func TestPrimaryModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.Config
+		expected string
+	}{
+		{"gemini", config.Config{GeminiModel: "gemini-2.5-flash"}, "gemini-2.5-flash"},
+		{"ollama", config.Config{LLMProvider: ProviderOllama, OllamaModel: "llama3.2"}, "llama3.2"},
+		{"chain entry model", config.Config{LLMChain: []config.LLMChainEntry{{Provider: ProviderOpenAI, Model: "qwen"}}}, "qwen"},
+		{"chain entry default", config.Config{OllamaModel: "llama3.2", LLMChain: []config.LLMChainEntry{{Provider: ProviderOllama}}}, "llama3.2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := PrimaryModel(&test.cfg); got != test.expected {
+				t.Errorf("PrimaryModel() = %q, expected %q", got, test.expected)
+			}
+		})
+	}
+}
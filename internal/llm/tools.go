@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// maxToolResultLength caps a single tool result so one broad call can't flood the prompt
+const maxToolResultLength = 4000
+
+// ToolParameter is a string parameter of a tool
+type ToolParameter struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// Tool is a function the model can call to look up information while answering
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  []ToolParameter
+	// Call runs the tool with the arguments given by the model and returns the result as text
+	Call func(ctx context.Context, args map[string]string) (string, error)
+}
+
+// ToolCall is a tool call requested by the model
+type ToolCall struct {
+	Name string            `json:"name"`
+	Args map[string]string `json:"args"`
+}
+
+// ToolResult is the result of a tool call, given back to the model
+type ToolResult struct {
+	Call   ToolCall
+	Result string
+}
+
+// toolResponse is the reply format for requesting tool calls
+type toolResponse struct {
+	ToolCalls []ToolCall `json:"tool_calls"`
+}
+
+// Toolbox lets the model call tools while generating. The model is told about the tools
+// with the tools prompt and asks for calls by replying with a JSON object; the results
+// are added to the prompt and the model is asked again, up to maxRounds times. This works
+// through Generate, so every provider, the fallback chain, the budget, the call ledger
+// and the response cache work with tools as is.
+type Toolbox struct {
+	prompts   map[string][]string
+	tools     []Tool
+	maxRounds int
+	// results are the tool results of the latest Generate call
+	results []ToolResult
+}
+
+// NewToolbox creates a toolbox with the given tools, allowing up to maxRounds rounds of
+// tool calls before the model has to answer
+func NewToolbox(prompts map[string][]string, tools []Tool, maxRounds int) *Toolbox {
+	return &Toolbox{
+		prompts:   prompts,
+		tools:     tools,
+		maxRounds: maxRounds,
+	}
+}
+
+// GenerateBrief generates a daily brief with the tools available
+func (t *Toolbox) GenerateBrief(ctx context.Context, provider Provider, bc *BriefContext) (string, error) {
+	promptContent, err := BuildBriefPrompt(t.prompts, bc)
+	if err != nil {
+		return "", err
+	}
+	return t.Generate(ctx, provider, "dailyBrief", bc.Language, promptContent)
+}
+
+// GenerateResponse generates a response to a user query with the tools available
+func (t *Toolbox) GenerateResponse(ctx context.Context, provider Provider, query string, memories []string, outputLanguage string) (string, error) {
+	promptContent, err := BuildResponsePrompt(t.prompts, query, memories, outputLanguage)
+	if err != nil {
+		return "", err
+	}
+	return t.Generate(ctx, provider, "userQuery", outputLanguage, promptContent)
+}
+
+// Generate runs the tool-call loop for the prompt and returns the model's final answer
+func (t *Toolbox) Generate(ctx context.Context, provider Provider, promptKey, outputLanguage, promptContent string) (string, error) {
+	t.results = nil
+
+	for round := 0; ; round++ {
+		// On the last round the tools are left out so the model has to answer
+		tools := t.tools
+		if round >= t.maxRounds {
+			tools = nil
+		}
+
+		instructions, err := renderPrompt(t.prompts, "tools", ToolPromptData{Tools: tools, Results: t.results})
+		if err != nil {
+			return "", err
+		}
+
+		response, err := provider.Generate(ctx, promptKey, outputLanguage, promptContent+"\n\n"+instructions)
+		if err != nil {
+			return "", err
+		}
+
+		calls, ok := parseToolCalls(response)
+		if !ok {
+			return response, nil
+		}
+		if tools == nil {
+			return "", fmt.Errorf("model kept calling tools after %d rounds", t.maxRounds)
+		}
+
+		for _, call := range calls {
+			t.results = append(t.results, ToolResult{Call: call, Result: t.call(ctx, call)})
+		}
+	}
+}
+
+// Results returns the tool results the model got during the latest Generate call
+func (t *Toolbox) Results() []ToolResult {
+	return t.results
+}
+
+// call runs a single tool call. Errors are given to the model as the result, so it can
+// correct the arguments or answer without the information.
+func (t *Toolbox) call(ctx context.Context, call ToolCall) string {
+	for _, tool := range t.tools {
+		if tool.Name != call.Name {
+			continue
+		}
+
+		for _, param := range tool.Parameters {
+			if param.Required && call.Args[param.Name] == "" {
+				return fmt.Sprintf("Error: missing required argument %q", param.Name)
+			}
+		}
+
+		result, err := tool.Call(ctx, call.Args)
+		if err != nil {
+			slog.Warn("Tool call failed", "tool", call.Name, "args", call.Args, "error", err)
+			return "Error: " + err.Error()
+		}
+
+		slog.Debug("Tool called", "tool", call.Name, "args", call.Args, "result_length", len(result))
+		if runes := []rune(result); len(runes) > maxToolResultLength {
+			result = string(runes[:maxToolResultLength]) + "\n(result truncated)"
+		}
+		// The results contain imported text, so they're fenced like the rest of the context
+		return FenceUntrusted(result)
+	}
+
+	slog.Warn("Model called an unknown tool", "tool", call.Name)
+	return fmt.Sprintf("Error: unknown tool %q", call.Name)
+}
+
+// parseToolCalls returns the tool calls requested in the response, or false if the
+// response is an answer instead
+func parseToolCalls(response string) ([]ToolCall, bool) {
+	trimmed := strings.TrimSpace(response)
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil, false
+	}
+
+	var resp toolResponse
+	if err := json.Unmarshal([]byte(trimmed), &resp); err != nil || len(resp.ToolCalls) == 0 {
+		return nil, false
+	}
+
+	return resp.ToolCalls, true
+}
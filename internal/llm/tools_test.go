@@ -0,0 +1,144 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// newTestToolbox creates a toolbox with a weather tool that records its calls
+func newTestToolbox(t *testing.T, maxRounds int) (*Toolbox, *[]map[string]string) {
+	t.Helper()
+
+	var calls []map[string]string
+	tools := []Tool{
+		{
+			Name:        "get_weather",
+			Description: "Get the weather forecast for a day",
+			Parameters:  []ToolParameter{{Name: "date", Description: "Date as YYYY-MM-DD", Required: true}},
+			Call: func(ctx context.Context, args map[string]string) (string, error) {
+				calls = append(calls, args)
+				if args["date"] == "2025-13-01" {
+					return "", errors.New("invalid date")
+				}
+				return "rain, temperature 2-6°C", nil
+			},
+		},
+	}
+
+	prompts := map[string][]string{
+		"tools": {
+			"{{range .Tools}}TOOL {{.Name}}{{end}}{{if not .Tools}}ANSWER NOW{{end}}",
+			"{{range .Results}}RESULT {{.Call.Name}}: {{.Result}}",
+			"{{end}}",
+		},
+	}
+
+	return NewToolbox(prompts, tools, maxRounds), &calls
+}
+
+func TestToolboxGenerate(t *testing.T) {
+	toolbox, calls := newTestToolbox(t, 3)
+	provider := &ScriptedProvider{Responses: []string{
+		`{"tool_calls": [{"name": "get_weather", "args": {"date": "2025-04-19"}}]}`,
+		"Tomorrow it will rain.",
+	}}
+
+	got, err := toolbox.Generate(context.Background(), provider, "userQuery", "English", "Will it rain tomorrow?")
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got != "Tomorrow it will rain." {
+		t.Errorf("Generate() = %q, expected the final answer", got)
+	}
+
+	if len(*calls) != 1 || (*calls)[0]["date"] != "2025-04-19" {
+		t.Errorf("unexpected tool calls: %v", *calls)
+	}
+
+	// The second prompt carries the tool result
+	if len(provider.Prompts) != 2 {
+		t.Fatalf("expected 2 prompts, got %d", len(provider.Prompts))
+	}
+	if !strings.HasPrefix(provider.Prompts[1], "Will it rain tomorrow?") ||
+		!strings.Contains(provider.Prompts[1], "RESULT get_weather: <data>\nrain, temperature 2-6°C\n</data>") {
+		t.Errorf("unexpected second prompt:\n%s", provider.Prompts[1])
+	}
+}
+
+func TestToolboxErrorsGivenToModel(t *testing.T) {
+	toolbox, _ := newTestToolbox(t, 3)
+	provider := &ScriptedProvider{Responses: []string{
+		`{"tool_calls": [{"name": "get_weather", "args": {"date": "2025-13-01"}}, {"name": "get_news", "args": {}}, {"name": "get_weather", "args": {}}]}`,
+		"I couldn't find the forecast.",
+	}}
+
+	if _, err := toolbox.Generate(context.Background(), provider, "userQuery", "English", "prompt"); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"RESULT get_weather: Error: invalid date",
+		`RESULT get_news: Error: unknown tool "get_news"`,
+		`RESULT get_weather: Error: missing required argument "date"`,
+	} {
+		if !strings.Contains(provider.Prompts[1], want) {
+			t.Errorf("second prompt missing %q:\n%s", want, provider.Prompts[1])
+		}
+	}
+}
+
+func TestToolboxRoundLimit(t *testing.T) {
+	toolCall := `{"tool_calls": [{"name": "get_weather", "args": {"date": "2025-04-19"}}]}`
+
+	t.Run("answers on the last round", func(t *testing.T) {
+		toolbox, calls := newTestToolbox(t, 2)
+		provider := &ScriptedProvider{Responses: []string{toolCall, toolCall, "Rain."}}
+
+		got, err := toolbox.Generate(context.Background(), provider, "userQuery", "English", "prompt")
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if got != "Rain." || len(*calls) != 2 {
+			t.Errorf("Generate() = %q after %d tool calls, expected the answer after 2", got, len(*calls))
+		}
+		if !strings.Contains(provider.Prompts[2], "ANSWER NOW") {
+			t.Errorf("expected the last prompt without tools:\n%s", provider.Prompts[2])
+		}
+	})
+
+	t.Run("keeps calling tools", func(t *testing.T) {
+		toolbox, calls := newTestToolbox(t, 2)
+		provider := &ScriptedProvider{Responses: []string{toolCall, toolCall, toolCall}}
+
+		if _, err := toolbox.Generate(context.Background(), provider, "userQuery", "English", "prompt"); err == nil {
+			t.Error("Generate() expected an error when the model doesn't stop calling tools")
+		}
+		if len(*calls) != 2 {
+			t.Errorf("expected 2 tool calls, got %d", len(*calls))
+		}
+	})
+}
+
+func TestParseToolCalls(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  string
+		wantCalls int
+	}{
+		{"tool call", `{"tool_calls": [{"name": "get_events", "args": {"date_range": "2025-04-18"}}]}`, 1},
+		{"plain answer", "It will rain tomorrow.", 0},
+		{"JSON brief", `{"greeting": "Good morning!", "sections": []}`, 0},
+		{"empty tool calls", `{"tool_calls": []}`, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls, ok := parseToolCalls(test.response)
+			if ok != (test.wantCalls > 0) || len(calls) != test.wantCalls {
+				t.Errorf("parseToolCalls() = %v, %v, expected %d calls", calls, ok, test.wantCalls)
+			}
+		})
+	}
+}
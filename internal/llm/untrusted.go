@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	// untrustedStart and untrustedEnd fence imported text in prompts, which tell the model
+	// to treat everything between them as data
+	untrustedStart = "<data>"
+	untrustedEnd   = "</data>"
+	// removedText replaces instruction-like text removed from untrusted text
+	removedText = "[removed]"
+)
+
+// instructionPatterns match text trying to give the model instructions, in English and
+// Finnish, and markup that could close the data fence or pose as a chat role
+var instructionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\s+(all\s+|any\s+)?(the\s+|your\s+|of\s+the\s+)?(previous\s+|prior\s+|above\s+|earlier\s+|preceding\s+|system\s+)?(instructions|prompts?|rules|directions)\b`),
+	regexp.MustCompile(`(?i)\b(unohda|ohita|älä\s+välitä)\s+(kaikki\s+)?(aiemmat\s+|edelliset\s+|yllä\s+olevat\s+)?(ohjeet|ohjeista|ohjeita|käskyt)\b`),
+	regexp.MustCompile(`(?i)\b(new|updated|system)\s+(instructions?|prompt)\s*:`),
+	regexp.MustCompile(`(?i)\b(you\s+are\s+now|from\s+now\s+on\s+you|pretend\s+to\s+be)\b`),
+	regexp.MustCompile(`(?im)^\s*(system|assistant|user|developer)\s*:`),
+	regexp.MustCompile(`(?i)<\s*/?\s*(system|assistant|user|instructions?|data)\s*>`),
+	regexp.MustCompile("```"),
+}
+
+// RemoveControlCharacters removes control and invisible formatting characters, such as
+// zero-width spaces and bidirectional overrides, keeping newlines and tabs
+func RemoveControlCharacters(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// SanitizeUntrusted cleans imported text before it's given to the model: control
+// characters are removed and instruction-like patterns are replaced. It reports whether
+// anything instruction-like was found.
+func SanitizeUntrusted(text string) (string, bool) {
+	text = RemoveControlCharacters(text)
+
+	found := false
+	for _, pattern := range instructionPatterns {
+		if pattern.MatchString(text) {
+			found = true
+			text = pattern.ReplaceAllString(text, removedText)
+		}
+	}
+
+	return text, found
+}
+
+// FenceUntrusted marks the text as data for the model. An empty text isn't fenced.
+func FenceUntrusted(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return text
+	}
+	return untrustedStart + "\n" + strings.TrimRight(text, "\n") + "\n" + untrustedEnd + "\n"
+}
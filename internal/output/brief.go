@@ -0,0 +1,230 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Brief is a daily brief. A structured brief has a greeting, sections and a closing; a
+// brief the LLM didn't return as JSON (or the template-based brief) only has Plain text.
+type Brief struct {
+	Greeting string         `json:"greeting"`
+	Sections []BriefSection `json:"sections"`
+	Closing  string         `json:"closing"`
+
+	// Plain is the free-form text of an unstructured brief
+	Plain string `json:"-"`
+
+	// Warnings are shown above the brief in every channel, e.g. when the LLM budget has
+	// run out and the brief was generated without the LLM
+	Warnings []string `json:"-"`
+}
+
+// BriefSection is a single titled section of a structured brief
+type BriefSection struct {
+	// Key identifies the kind of section (e.g. "weather", "school") so channels can
+	// exclude it regardless of the language of the title
+	Key   string   `json:"key,omitempty"`
+	Title string   `json:"title"`
+	Emoji string   `json:"emoji,omitempty"`
+	Items []string `json:"items"`
+}
+
+// PlainBrief creates an unstructured brief from free-form text
+func PlainBrief(text string) *Brief {
+	return &Brief{Plain: text}
+}
+
+// ParseBrief parses the JSON brief returned by the LLM and validates it. If the text isn't
+// a JSON object at all, it's returned as a plain brief so free-form prompts keep working.
+func ParseBrief(text string) (*Brief, error) {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") {
+		return PlainBrief(text), nil
+	}
+
+	var brief Brief
+	if err := json.Unmarshal([]byte(trimmed), &brief); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON brief: %w", err)
+	}
+
+	if err := brief.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid JSON brief: %w", err)
+	}
+
+	return &brief, nil
+}
+
+// Validate checks that a structured brief has all the required fields
+func (b *Brief) Validate() error {
+	var errs []error
+
+	if strings.TrimSpace(b.Greeting) == "" {
+		errs = append(errs, errors.New("greeting is empty"))
+	}
+
+	if len(b.Sections) == 0 {
+		errs = append(errs, errors.New("no sections"))
+	}
+
+	for i, section := range b.Sections {
+		if strings.TrimSpace(section.Title) == "" {
+			errs = append(errs, fmt.Errorf("section %d has no title", i+1))
+		}
+		if len(section.Items) == 0 {
+			errs = append(errs, fmt.Errorf("section %d (%s) has no items", i+1, section.Title))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsPlain reports whether the brief is unstructured free-form text
+func (b *Brief) IsPlain() bool {
+	return b.Plain != ""
+}
+
+// WithoutSections returns a copy of the brief without the sections whose key or title
+// matches one of the given names (case-insensitive). Plain briefs are returned as is.
+func (b *Brief) WithoutSections(names []string) *Brief {
+	if len(names) == 0 || b.IsPlain() {
+		return b
+	}
+
+	filtered := *b
+	filtered.Sections = slices.DeleteFunc(slices.Clone(b.Sections), func(section BriefSection) bool {
+		return slices.ContainsFunc(names, func(name string) bool {
+			return strings.EqualFold(name, section.Key) || strings.EqualFold(name, section.Title)
+		})
+	})
+
+	return &filtered
+}
+
+// sectionHeading returns the section title prefixed with its emoji, if any
+func sectionHeading(section BriefSection) string {
+	if section.Emoji == "" {
+		return section.Title
+	}
+	return section.Emoji + " " + section.Title
+}
+
+// AddWarning adds a warning that's shown above the brief
+func (b *Brief) AddWarning(warning string) {
+	b.Warnings = append(b.Warnings, warning)
+}
+
+// withWarnings prefixes the rendered brief with the warnings, escaped with escape
+func (b *Brief) withWarnings(rendered string, escape func(string) string) string {
+	if len(b.Warnings) == 0 {
+		return rendered
+	}
+
+	var sb strings.Builder
+	for _, warning := range b.Warnings {
+		fmt.Fprintf(&sb, "⚠️ %s\n", escape(warning))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(rendered)
+
+	return sb.String()
+}
+
+// noEscape returns the text unchanged, for formats that need no escaping
+func noEscape(text string) string {
+	return text
+}
+
+// RenderText renders the brief as plain text, used for the command line and email
+func (b *Brief) RenderText() string {
+	return b.withWarnings(b.renderText(), noEscape)
+}
+
+// renderText renders the brief without warnings as plain text
+func (b *Brief) renderText() string {
+	if b.IsPlain() {
+		return b.Plain
+	}
+
+	var sb strings.Builder
+	sb.WriteString(b.Greeting)
+	for _, section := range b.Sections {
+		fmt.Fprintf(&sb, "\n\n%s\n", sectionHeading(section))
+		for _, item := range section.Items {
+			fmt.Fprintf(&sb, "- %s\n", item)
+		}
+	}
+	if b.Closing != "" {
+		fmt.Fprintf(&sb, "\n%s", b.Closing)
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// RenderMarkdown renders the brief as Discord-flavored markdown
+func (b *Brief) RenderMarkdown() string {
+	return b.withWarnings(b.renderMarkdown(), noEscape)
+}
+
+// renderMarkdown renders the brief without warnings as Discord-flavored markdown
+func (b *Brief) renderMarkdown() string {
+	if b.IsPlain() {
+		return b.Plain
+	}
+
+	var sb strings.Builder
+	sb.WriteString(b.Greeting)
+	for _, section := range b.Sections {
+		fmt.Fprintf(&sb, "\n\n**%s**\n", sectionHeading(section))
+		for _, item := range section.Items {
+			fmt.Fprintf(&sb, "- %s\n", item)
+		}
+	}
+	if b.Closing != "" {
+		fmt.Fprintf(&sb, "\n*%s*", b.Closing)
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// RenderTelegram renders the brief in Telegram's MarkdownV2 format. Structured briefs are
+// fully escaped since all formatting is added here; plain briefs go through escapeMarkdownV2.
+func (b *Brief) RenderTelegram() string {
+	return b.withWarnings(b.renderTelegram(), escapeMarkdownV2Text)
+}
+
+// renderTelegram renders the brief without warnings in Telegram's MarkdownV2 format
+func (b *Brief) renderTelegram() string {
+	if b.IsPlain() {
+		return escapeMarkdownV2(b.Plain)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(escapeMarkdownV2Text(b.Greeting))
+	for _, section := range b.Sections {
+		fmt.Fprintf(&sb, "\n\n*%s*\n", escapeMarkdownV2Text(sectionHeading(section)))
+		for _, item := range section.Items {
+			fmt.Fprintf(&sb, "• %s\n", escapeMarkdownV2Text(item))
+		}
+	}
+	if b.Closing != "" {
+		fmt.Fprintf(&sb, "\n_%s_", escapeMarkdownV2Text(b.Closing))
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// markdownV2Escaper escapes every character that has a special meaning in MarkdownV2
+var markdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+	"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// escapeMarkdownV2Text escapes literal text for Telegram's MarkdownV2 format
+func escapeMarkdownV2Text(text string) string {
+	return markdownV2Escaper.Replace(text)
+}
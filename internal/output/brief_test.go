@@ -0,0 +1,109 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+const validBriefJSON = `{
+  "greeting": "Good morning, today is Monday 10.3.",
+  "sections": [
+    {"key": "weather", "title": "Weather", "emoji": "☀️", "items": ["Sunny, 1-5°C"]},
+    {"key": "school", "title": "School (Alice)", "items": ["09:30 KS", "11:00 MA"]}
+  ],
+  "closing": "Respectfully, your butler."
+}`
+
+func TestParseBrief(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantPlain bool
+		wantErr   bool
+	}{
+		{name: "valid JSON", input: validBriefJSON},
+		{name: "free-form text", input: "Good morning!\nToday is sunny.", wantPlain: true},
+		{name: "malformed JSON", input: `{"greeting": `, wantErr: true},
+		{name: "missing greeting", input: `{"sections": [{"title": "Weather", "items": ["Sunny"]}]}`, wantErr: true},
+		{name: "no sections", input: `{"greeting": "Hello", "sections": []}`, wantErr: true},
+		{name: "section without items", input: `{"greeting": "Hello", "sections": [{"title": "Weather", "items": []}]}`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			brief, err := ParseBrief(test.input)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseBrief() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if err == nil && brief.IsPlain() != test.wantPlain {
+				t.Errorf("ParseBrief() plain = %v, want %v", brief.IsPlain(), test.wantPlain)
+			}
+		})
+	}
+}
+
+func TestWithoutSections(t *testing.T) {
+	brief, err := ParseBrief(validBriefJSON)
+	if err != nil {
+		t.Fatalf("ParseBrief() error = %v", err)
+	}
+
+	// Sections can be excluded by key or title, case-insensitively
+	for _, name := range []string{"school", "SCHOOL (alice)"} {
+		filtered := brief.WithoutSections([]string{name})
+		if len(filtered.Sections) != 1 || filtered.Sections[0].Key != "weather" {
+			t.Errorf("WithoutSections(%q) = %+v", name, filtered.Sections)
+		}
+	}
+
+	plain := PlainBrief("text")
+	if plain.WithoutSections([]string{"school"}) != plain {
+		t.Error("expected plain brief to be returned as is")
+	}
+}
+
+func TestBriefRender(t *testing.T) {
+	brief, err := ParseBrief(validBriefJSON)
+	if err != nil {
+		t.Fatalf("ParseBrief() error = %v", err)
+	}
+
+	text := brief.RenderText()
+	if !strings.Contains(text, "☀️ Weather\n- Sunny, 1-5°C") || !strings.HasSuffix(text, "Respectfully, your butler.") {
+		t.Errorf("unexpected text rendering:\n%s", text)
+	}
+
+	markdown := brief.RenderMarkdown()
+	if !strings.Contains(markdown, "**☀️ Weather**\n- Sunny, 1-5°C") || !strings.HasSuffix(markdown, "*Respectfully, your butler.*") {
+		t.Errorf("unexpected markdown rendering:\n%s", markdown)
+	}
+
+	telegram := brief.RenderTelegram()
+	for _, want := range []string{
+		"Good morning, today is Monday 10\\.3\\.",
+		"*School \\(Alice\\)*\n• 09:30 KS",
+		"• Sunny, 1\\-5°C",
+		"_Respectfully, your butler\\._",
+	} {
+		if !strings.Contains(telegram, want) {
+			t.Errorf("telegram rendering missing %q:\n%s", want, telegram)
+		}
+	}
+
+	plain := PlainBrief("# Hello.")
+	if plain.RenderText() != "# Hello." || plain.RenderTelegram() != "**Hello\\.**" {
+		t.Errorf("unexpected plain rendering: %q / %q", plain.RenderText(), plain.RenderTelegram())
+	}
+}
+
+func TestBriefRenderWarnings(t *testing.T) {
+	brief := PlainBrief("Daily brief")
+	brief.AddWarning("Budget reached.")
+
+	if got := brief.RenderText(); got != "⚠️ Budget reached.\n\nDaily brief" {
+		t.Errorf("RenderText() = %q", got)
+	}
+	if got := brief.RenderTelegram(); !strings.HasPrefix(got, "⚠️ Budget reached\\.\n\n") {
+		t.Errorf("RenderTelegram() = %q", got)
+	}
+}
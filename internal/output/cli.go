@@ -13,8 +13,8 @@ func NewCLIOutputter() *CLIOutputter {
 	return &CLIOutputter{}
 }
 
-// Send prints the content to the command line
-func (o *CLIOutputter) Send(ctx context.Context, content string) error {
-	fmt.Println(content)
+// Send prints the brief to the command line as plain text
+func (o *CLIOutputter) Send(ctx context.Context, brief *Brief) error {
+	fmt.Println(brief.RenderText())
 	return nil
 }
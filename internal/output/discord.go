@@ -28,8 +28,9 @@ type discordMessage struct {
 	Content string `json:"content"`
 }
 
-// Send sends the content to a Discord webhook
-func (o *DiscordOutputter) Send(ctx context.Context, content string) error {
+// Send renders the brief as markdown and sends it to a Discord webhook
+func (o *DiscordOutputter) Send(ctx context.Context, brief *Brief) error {
+	content := brief.RenderMarkdown()
 	slog.Info("Sending message to Discord webhook", "content_length", len(content))
 
 	// Create the message
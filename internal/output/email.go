@@ -0,0 +1,99 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultEmailSubject is used when no subject is configured
+const defaultEmailSubject = "Hovimestari daily brief"
+
+// EmailOutputter sends the brief as a plain text email over SMTP
+type EmailOutputter struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+	Subject  string
+
+	// sendMail sends the message, replaceable in tests
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailOutputter creates a new email outputter. Authentication is only used if a
+// username is given.
+func NewEmailOutputter(host string, port int, username, password, from string, to []string, subject string) *EmailOutputter {
+	if subject == "" {
+		subject = defaultEmailSubject
+	}
+
+	return &EmailOutputter{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+		To:       to,
+		Subject:  subject,
+		sendMail: smtp.SendMail,
+	}
+}
+
+// Send renders the brief as plain text and sends it by email
+func (o *EmailOutputter) Send(ctx context.Context, brief *Brief) error {
+	slog.Info("Sending brief by email", "host", o.Host, "recipients", len(o.To))
+
+	message, err := buildEmailMessage(o.From, o.To, o.Subject, brief.RenderText(), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+
+	var auth smtp.Auth
+	if o.Username != "" {
+		auth = smtp.PlainAuth("", o.Username, o.Password, o.Host)
+	}
+
+	addr := net.JoinHostPort(o.Host, strconv.Itoa(o.Port))
+	if err := o.sendMail(addr, auth, o.From, o.To, message); err != nil {
+		slog.Error("Failed to send email", "host", o.Host, "error", err)
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	slog.Info("Successfully sent brief by email", "recipients", len(o.To))
+	return nil
+}
+
+// buildEmailMessage builds a UTF-8 plain text email with a quoted-printable body
+func buildEmailMessage(from string, to []string, subject, body string, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+
+	writer := quotedprintable.NewWriter(&buf)
+	if _, err := writer.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return nil, fmt.Errorf("failed to encode email body: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode email body: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,58 @@
+package output
+
+import (
+	"context"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildEmailMessage(t *testing.T) {
+	date := time.Date(2025, 3, 10, 7, 0, 0, 0, time.UTC)
+	message, err := buildEmailMessage("butler@example.com", []string{"a@example.com", "b@example.com"}, "Päivän katsaus", "Hyvää huomenta\nline two", date)
+	if err != nil {
+		t.Fatalf("buildEmailMessage() error = %v", err)
+	}
+
+	got := string(message)
+	for _, want := range []string{
+		"From: butler@example.com\r\n",
+		"To: a@example.com, b@example.com\r\n",
+		"Subject: =?utf-8?q?P=C3=A4iv=C3=A4n_katsaus?=\r\n",
+		"Content-Type: text/plain; charset=UTF-8\r\n",
+		"\r\n\r\nHyv=C3=A4=C3=A4 huomenta\r\nline two",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("message missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestEmailOutputterSend(t *testing.T) {
+	outputter := NewEmailOutputter("smtp.example.com", 587, "user", "secret", "butler@example.com", []string{"a@example.com"}, "")
+
+	var gotAddr string
+	var gotAuth smtp.Auth
+	var gotMessage string
+	outputter.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr = addr
+		gotAuth = a
+		gotMessage = string(msg)
+		return nil
+	}
+
+	if err := outputter.Send(context.Background(), PlainBrief("Good morning")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if gotAddr != "smtp.example.com:587" {
+		t.Errorf("unexpected address %q", gotAddr)
+	}
+	if gotAuth == nil {
+		t.Error("expected authentication when a username is set")
+	}
+	if !strings.Contains(gotMessage, "Subject: "+defaultEmailSubject) || !strings.Contains(gotMessage, "Good morning") {
+		t.Errorf("unexpected message:\n%s", gotMessage)
+	}
+}
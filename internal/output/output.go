@@ -4,8 +4,28 @@ import (
 	"context"
 )
 
-// Outputter is an interface for sending brief content to various destinations
+// Outputter is an interface for sending briefs to various destinations
 type Outputter interface {
-	// Send sends the content to the destination
-	Send(ctx context.Context, content string) error
+	// Send renders the brief for the destination and sends it
+	Send(ctx context.Context, brief *Brief) error
+}
+
+// sectionFilter is an outputter that drops sections before passing the brief on
+type sectionFilter struct {
+	next    Outputter
+	exclude []string
+}
+
+// WithExcludedSections wraps an outputter so that the sections matching the given keys or
+// titles are left out of the briefs it sends
+func WithExcludedSections(outputter Outputter, exclude []string) Outputter {
+	if len(exclude) == 0 {
+		return outputter
+	}
+	return &sectionFilter{next: outputter, exclude: exclude}
+}
+
+// Send sends the brief without the excluded sections
+func (f *sectionFilter) Send(ctx context.Context, brief *Brief) error {
+	return f.next.Send(ctx, brief.WithoutSections(f.exclude))
 }
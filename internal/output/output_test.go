@@ -7,7 +7,7 @@ import (
 
 func TestCLIOutputter(t *testing.T) {
 	outputter := NewCLIOutputter()
-	err := outputter.Send(context.Background(), "Test message")
+	err := outputter.Send(context.Background(), PlainBrief("Test message"))
 	if err != nil {
 		t.Errorf("CLIOutputter.Send() returned an error: %v", err)
 	}
@@ -66,6 +66,42 @@ func TestEscapeMarkdownV2(t *testing.T) {
 	}
 }
 
+// recordingOutputter records the briefs it's asked to send
+type recordingOutputter struct {
+	sent []*Brief
+}
+
+func (o *recordingOutputter) Send(ctx context.Context, brief *Brief) error {
+	o.sent = append(o.sent, brief)
+	return nil
+}
+
+func TestWithExcludedSections(t *testing.T) {
+	brief := &Brief{
+		Greeting: "Good morning",
+		Sections: []BriefSection{
+			{Key: "weather", Title: "Weather", Items: []string{"Sunny"}},
+			{Key: "school", Title: "School schedule", Items: []string{"09:30 KS"}},
+		},
+	}
+
+	recorder := &recordingOutputter{}
+	if err := WithExcludedSections(recorder, []string{"school"}).Send(context.Background(), brief); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if len(recorder.sent) != 1 || len(recorder.sent[0].Sections) != 1 || recorder.sent[0].Sections[0].Key != "weather" {
+		t.Errorf("expected only the weather section to be sent, got %+v", recorder.sent)
+	}
+	if len(brief.Sections) != 2 {
+		t.Error("the original brief should not be modified")
+	}
+
+	if WithExcludedSections(recorder, nil) != Outputter(recorder) {
+		t.Error("expected the outputter to be returned as is without exclusions")
+	}
+}
+
 // Note: We don't test the Discord and Telegram outputters here because they require
 // actual API calls. In a real-world scenario, we would mock the HTTP client to test
 // these outputters without making actual API calls.
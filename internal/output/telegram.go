@@ -67,16 +67,15 @@ func escapeMarkdownV2(text string) string {
 	return result
 }
 
-// Send sends the content to a Telegram chat with markdown formatting
-func (o *TelegramOutputter) Send(ctx context.Context, content string) error {
-	slog.Info("Sending message to Telegram", "chat_id", o.ChatID, "content_length", len(content))
+// Send renders the brief in MarkdownV2 format and sends it to a Telegram chat
+func (o *TelegramOutputter) Send(ctx context.Context, brief *Brief) error {
+	// Render and escape the content for MarkdownV2 format
+	escapedContent := brief.RenderTelegram()
+	slog.Info("Sending message to Telegram", "chat_id", o.ChatID, "content_length", len(escapedContent))
 
 	// Construct the Telegram Bot API URL
 	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", o.BotToken)
 
-	// Escape the content for MarkdownV2 format
-	escapedContent := escapeMarkdownV2(content)
-
 	// Create the message payload
 	payload := map[string]string{
 		"chat_id":    o.ChatID,
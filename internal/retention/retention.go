@@ -0,0 +1,115 @@
+// Package retention prunes the memories that imports keep adding, following the
+// per-source retention rules of the configuration.
+package retention
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+	"github.com/lepinkainen/hovimestari/internal/store"
+)
+
+// Result is the number of memories pruned from a single source
+type Result struct {
+	Source string
+	Rule   config.RetentionRule
+	Pruned int64
+}
+
+// Pruner applies retention rules to the stored memories
+type Pruner struct {
+	store *store.Store
+	rules []config.RetentionRule
+}
+
+// NewPruner creates a pruner with the retention rules
+func NewPruner(store *store.Store, rules []config.RetentionRule) *Pruner {
+	return &Pruner{store: store, rules: rules}
+}
+
+// Prune applies the most specific rule to each memory source, counting the memories
+// relevant before now minus max_age_days as expired. With dryRun nothing is deleted and
+// the results hold the number of memories that would be. Sources without a rule and
+// rules that prune nothing aren't included in the results.
+func (p *Pruner) Prune(now time.Time, dryRun bool) ([]Result, error) {
+	if len(p.rules) == 0 {
+		return nil, nil
+	}
+
+	sources, err := p.store.GetMemorySources()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, source := range sources {
+		rule, ok := RuleFor(p.rules, source)
+		if !ok {
+			continue
+		}
+
+		pruned, err := p.store.PruneMemories(pruneRule(rule, source, now), dryRun)
+		if err != nil {
+			return results, fmt.Errorf("failed to prune %s: %w", source, err)
+		}
+		if pruned > 0 {
+			results = append(results, Result{Source: source, Rule: rule, Pruned: pruned})
+		}
+	}
+
+	return results, nil
+}
+
+// RuleFor returns the most specific rule for the source. A rule matches its source
+// exactly or as a prefix followed by ":", so "weather-metno" covers
+// "weather-metno:Helsinki".
+func RuleFor(rules []config.RetentionRule, source string) (config.RetentionRule, bool) {
+	var best config.RetentionRule
+	found := false
+	for _, rule := range rules {
+		if rule.Source != source && !strings.HasPrefix(source, rule.Source+":") {
+			continue
+		}
+		if !found || len(rule.Source) > len(best.Source) {
+			best, found = rule, true
+		}
+	}
+	return best, found
+}
+
+// pruneRule converts a retention rule to the store rule for a single source
+func pruneRule(rule config.RetentionRule, source string, now time.Time) store.PruneRule {
+	pruneRule := store.PruneRule{Source: source, KeepLatestPerDate: rule.KeepLatestPerDate}
+	if rule.MaxAgeDays > 0 {
+		// Whole days, so a memory relevant today isn't pruned in the middle of it
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		before := midnight.AddDate(0, 0, -rule.MaxAgeDays)
+		pruneRule.Before = &before
+	}
+	return pruneRule
+}
+
+// AutoPrune applies the configured retention rules after an import if auto_prune is
+// enabled. Failures are logged rather than returned so they don't fail the import.
+func AutoPrune(cfg *config.Config, store *store.Store) {
+	if !cfg.AutoPrune {
+		return
+	}
+
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		slog.Error("Failed to prune memories", "error", fmt.Errorf("failed to load timezone: %w", err))
+		return
+	}
+
+	results, err := NewPruner(store, cfg.Retention).Prune(time.Now().In(loc), false)
+	if err != nil {
+		slog.Error("Failed to prune memories", "error", err)
+	}
+	for _, result := range results {
+		slog.Info("Pruned memories", "source", result.Source, "count", result.Pruned)
+	}
+}
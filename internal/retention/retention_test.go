@@ -0,0 +1,54 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lepinkainen/hovimestari/internal/config"
+)
+
+func TestRuleFor(t *testing.T) {
+	rules := []config.RetentionRule{
+		{Source: "weather-metno", KeepLatestPerDate: true},
+		{Source: "electricity", MaxAgeDays: 30},
+		{Source: "electricity:10YFI-1--------U", MaxAgeDays: 7},
+	}
+
+	tests := []struct {
+		source     string
+		wantSource string
+		wantFound  bool
+	}{
+		{"weather-metno:Helsinki", "weather-metno", true},
+		{"weather-metno", "weather-metno", true},
+		{"electricity:10YSE-1--------K", "electricity", true},
+		{"electricity:10YFI-1--------U", "electricity:10YFI-1--------U", true},
+		{"weather-metnox", "", false},
+		{"manual", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			rule, found := RuleFor(rules, tt.source)
+			if found != tt.wantFound || rule.Source != tt.wantSource {
+				t.Errorf("RuleFor(%q) = %q, %v, want %q, %v", tt.source, rule.Source, found, tt.wantSource, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestPruneRule(t *testing.T) {
+	loc := time.FixedZone("EET", 2*60*60)
+	now := time.Date(2025, 4, 20, 15, 30, 0, 0, loc)
+
+	rule := pruneRule(config.RetentionRule{Source: "electricity", MaxAgeDays: 30}, "electricity:FI", now)
+	want := time.Date(2025, 3, 21, 0, 0, 0, 0, loc)
+	if rule.Source != "electricity:FI" || rule.Before == nil || !rule.Before.Equal(want) || rule.KeepLatestPerDate {
+		t.Errorf("pruneRule() = %+v, want source electricity:FI before %v", rule, want)
+	}
+
+	rule = pruneRule(config.RetentionRule{Source: "weather-metno", KeepLatestPerDate: true}, "weather-metno:Helsinki", now)
+	if rule.Before != nil || !rule.KeepLatestPerDate {
+		t.Errorf("pruneRule() = %+v, want only keep latest per date", rule)
+	}
+}
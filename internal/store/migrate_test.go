@@ -0,0 +1,293 @@
+package store
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestParseMigrationName(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileName    string
+		wantVersion int
+		wantName    string
+		wantErr     bool
+	}{
+		{name: "valid", fileName: "0001_initial_schema.sql", wantVersion: 1, wantName: "initial_schema"},
+		{name: "no padding", fileName: "12_memory_ranges.sql", wantVersion: 12, wantName: "memory_ranges"},
+		{name: "missing name", fileName: "0003.sql", wantErr: true},
+		{name: "empty name", fileName: "0003_.sql", wantErr: true},
+		{name: "not a number", fileName: "first_schema.sql", wantErr: true},
+		{name: "zero version", fileName: "0000_schema.sql", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, name, err := parseMigrationName(tt.fileName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMigrationName(%q) error = %v, wantErr %v", tt.fileName, err, tt.wantErr)
+			}
+			if version != tt.wantVersion || name != tt.wantName {
+				t.Errorf("parseMigrationName(%q) = %d, %q, want %d, %q", tt.fileName, version, name, tt.wantVersion, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0010_later.sql":  {Data: []byte("ALTER TABLE b ADD COLUMN c TEXT;")},
+		"migrations/0002_second.sql": {Data: []byte("CREATE TABLE b (id INTEGER);")},
+		"migrations/0001_first.sql":  {Data: []byte("CREATE TABLE a (id INTEGER);")},
+		"migrations/README.md":       {Data: []byte("not a migration")},
+	}
+
+	migrations, err := loadMigrations(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("loadMigrations() error = %v", err)
+	}
+
+	want := []Migration{
+		{Version: 1, Name: "first", SQL: "CREATE TABLE a (id INTEGER);"},
+		{Version: 2, Name: "second", SQL: "CREATE TABLE b (id INTEGER);"},
+		{Version: 10, Name: "later", SQL: "ALTER TABLE b ADD COLUMN c TEXT;"},
+	}
+	if len(migrations) != len(want) {
+		t.Fatalf("loadMigrations() returned %d migrations, want %d", len(migrations), len(want))
+	}
+	for i := range want {
+		if migrations[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, migrations[i], want[i])
+		}
+	}
+}
+
+func TestLoadMigrationsDuplicateVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_first.sql": {Data: []byte("SELECT 1;")},
+		"migrations/1_again.sql":    {Data: []byte("SELECT 2;")},
+	}
+
+	_, err := loadMigrations(fsys, "migrations")
+	if err == nil || !strings.Contains(err.Error(), "duplicate migration version 1") {
+		t.Errorf("loadMigrations() error = %v, want duplicate version error", err)
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations() error = %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Migrations() returned no migrations")
+	}
+	if migrations[0].Version != 1 || !strings.Contains(migrations[0].SQL, "CREATE TABLE IF NOT EXISTS memories") {
+		t.Errorf("first migration = %d %s, want the initial schema", migrations[0].Version, migrations[0].Name)
+	}
+}
+
+// newTestStore creates a migrated store backed by a database file in a temporary
+// directory, closed when the test ends
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	s := openTestStore(t)
+	if err := s.Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	return s
+}
+
+// openTestStore opens a store on an empty database file in a temporary directory, closed
+// when the test ends
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	s, err := NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if err := s.Close(); err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+	})
+	return s
+}
+
+// preMigrationSchema is the memories and calendar_events schema Initialize created before
+// migrations were introduced
+const preMigrationSchema = `
+CREATE TABLE memories (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	content TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	relevance_date TIMESTAMP,
+	source TEXT NOT NULL,
+	uid TEXT
+);
+CREATE INDEX idx_memories_relevance_date ON memories(relevance_date);
+CREATE INDEX idx_memories_source ON memories(source);
+CREATE INDEX idx_memories_source_uid ON memories(source, uid);
+
+CREATE TABLE calendar_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	uid TEXT NOT NULL,
+	summary TEXT NOT NULL,
+	start_time TIMESTAMP NOT NULL,
+	end_time TIMESTAMP,
+	location TEXT,
+	description TEXT,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	source TEXT NOT NULL
+);
+CREATE INDEX idx_calendar_events_start_time ON calendar_events(start_time);
+CREATE INDEX idx_calendar_events_end_time ON calendar_events(end_time);
+CREATE INDEX idx_calendar_events_source ON calendar_events(source);
+CREATE INDEX idx_calendar_events_source_uid ON calendar_events(source, uid);
+CREATE INDEX idx_calendar_events_uid ON calendar_events(uid);
+`
+
+func TestMigrateNewDatabase(t *testing.T) {
+	s := openTestStore(t)
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations() error = %v", err)
+	}
+
+	applied, err := s.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("Migrate() applied %d migrations, want all %d", len(applied), len(migrations))
+	}
+
+	// A migrated database has nothing left to apply
+	applied, err = s.Migrate()
+	if err != nil {
+		t.Fatalf("second Migrate() error = %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("second Migrate() applied %d migrations, want none", len(applied))
+	}
+
+	statuses, err := s.MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus() error = %v", err)
+	}
+	for _, status := range statuses {
+		if !status.Applied() {
+			t.Errorf("migration %d (%s) is pending after Migrate()", status.Version, status.Name)
+		}
+	}
+
+	if _, err := s.AddMemory("Dentist appointment", nil, "manual", nil); err != nil {
+		t.Errorf("AddMemory() on a migrated database error = %v", err)
+	}
+}
+
+func TestMigrationStatusLeavesDatabaseUnchanged(t *testing.T) {
+	s := openTestStore(t)
+
+	statuses, err := s.MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus() error = %v", err)
+	}
+	for _, status := range statuses {
+		if status.Applied() {
+			t.Errorf("migration %d (%s) is applied on an empty database", status.Version, status.Name)
+		}
+	}
+
+	exists, err := s.migrationsTableExists()
+	if err != nil {
+		t.Fatalf("migrationsTableExists() error = %v", err)
+	}
+	if exists {
+		t.Error("MigrationStatus() created the schema_migrations table")
+	}
+}
+
+func TestMigrateNewerDatabase(t *testing.T) {
+	s := newTestStore(t)
+
+	if _, err := s.db.Exec(`INSERT INTO schema_migrations (version, name) VALUES (9999, 'from_the_future')`); err != nil {
+		t.Fatalf("failed to record future migration: %v", err)
+	}
+
+	if _, err := s.Migrate(); err == nil || !strings.Contains(err.Error(), "newer than the latest known version") {
+		t.Errorf("Migrate() error = %v, want a newer schema error", err)
+	}
+}
+
+func TestMigratePreMigrationDatabase(t *testing.T) {
+	s := openTestStore(t)
+
+	if _, err := s.db.Exec(preMigrationSchema); err != nil {
+		t.Fatalf("failed to create pre-migration schema: %v", err)
+	}
+
+	// Stored the way the old AddMemory and AddCalendarEvent stored them
+	relevanceDate := time.Date(2025, 4, 22, 0, 0, 0, 0, time.UTC)
+	if _, err := s.db.Exec(`INSERT INTO memories (content, relevance_date, source) VALUES (?, ?, ?)`,
+		"Parent-teacher meeting", relevanceDate, "manual"); err != nil {
+		t.Fatalf("failed to insert dated memory: %v", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO memories (content, source) VALUES (?, ?)`,
+		"The car is serviced at Autohuolto", "manual"); err != nil {
+		t.Fatalf("failed to insert undated memory: %v", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO calendar_events (uid, summary, start_time, source) VALUES (?, ?, ?, ?)`,
+		"event-1", "Swimming lesson", time.Date(2025, 4, 23, 17, 0, 0, 0, time.UTC), "calendar:Family"); err != nil {
+		t.Fatalf("failed to insert calendar event: %v", err)
+	}
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations() error = %v", err)
+	}
+	applied, err := s.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("Migrate() applied %d migrations, want all %d", len(applied), len(migrations))
+	}
+
+	memories, err := s.ListMemories(MemoryFilter{})
+	if err != nil {
+		t.Fatalf("ListMemories() error = %v", err)
+	}
+	if len(memories) != 2 {
+		t.Fatalf("ListMemories() returned %d memories, want 2", len(memories))
+	}
+	for _, memory := range memories {
+		switch memory.Content {
+		case "Parent-teacher meeting":
+			if memory.RelevanceStart == nil || !memory.RelevanceStart.Equal(relevanceDate) || !memory.RelevanceEnd.Equal(relevanceDate) {
+				t.Errorf("dated memory relevance = %v to %v, want %v on both", memory.RelevanceStart, memory.RelevanceEnd, relevanceDate)
+			}
+		default:
+			if memory.RelevanceStart != nil || memory.RelevanceEnd != nil {
+				t.Errorf("undated memory relevance = %v to %v, want none", memory.RelevanceStart, memory.RelevanceEnd)
+			}
+		}
+	}
+
+	// The full-text indexes cover the rows that existed before the upgrade
+	for query, kind := range map[string]string{"teacher": SearchKindMemory, "swimming": SearchKindEvent} {
+		results, err := s.Search(query, SearchFilter{})
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", query, err)
+		}
+		if len(results) != 1 || results[0].Kind != kind {
+			t.Errorf("Search(%q) = %+v, want one %s", query, results, kind)
+		}
+	}
+}
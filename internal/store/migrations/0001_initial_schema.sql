@@ -0,0 +1,83 @@
+-- The schema created by Initialize before migrations were introduced. Every statement is
+-- conditional, so this also applies cleanly to databases created back then.
+
+CREATE TABLE IF NOT EXISTS memories (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	content TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	relevance_date TIMESTAMP,
+	source TEXT NOT NULL,
+	uid TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_memories_relevance_date ON memories(relevance_date);
+CREATE INDEX IF NOT EXISTS idx_memories_source ON memories(source);
+CREATE INDEX IF NOT EXISTS idx_memories_source_uid ON memories(source, uid);
+
+CREATE TABLE IF NOT EXISTS calendar_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	uid TEXT NOT NULL,
+	summary TEXT NOT NULL,
+	start_time TIMESTAMP NOT NULL,
+	end_time TIMESTAMP,
+	location TEXT,
+	description TEXT,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	source TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_calendar_events_start_time ON calendar_events(start_time);
+CREATE INDEX IF NOT EXISTS idx_calendar_events_end_time ON calendar_events(end_time);
+CREATE INDEX IF NOT EXISTS idx_calendar_events_source ON calendar_events(source);
+CREATE INDEX IF NOT EXISTS idx_calendar_events_source_uid ON calendar_events(source, uid);
+CREATE INDEX IF NOT EXISTS idx_calendar_events_uid ON calendar_events(uid);
+
+CREATE TABLE IF NOT EXISTS briefs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	content TEXT NOT NULL,
+	provider TEXT NOT NULL,
+	model TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_briefs_created_at ON briefs(created_at);
+
+CREATE TABLE IF NOT EXISTS llm_calls (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	provider TEXT NOT NULL,
+	model TEXT NOT NULL,
+	prompt_key TEXT NOT NULL,
+	prompt_hash TEXT NOT NULL,
+	input_tokens INTEGER NOT NULL DEFAULT 0,
+	output_tokens INTEGER NOT NULL DEFAULT 0,
+	latency_ms INTEGER NOT NULL DEFAULT 0,
+	error TEXT,
+	cost REAL NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_llm_calls_created_at ON llm_calls(created_at);
+
+CREATE TABLE IF NOT EXISTS llm_cache (
+	provider TEXT NOT NULL,
+	model TEXT NOT NULL,
+	prompt_hash TEXT NOT NULL,
+	response TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (provider, model, prompt_hash)
+);
+CREATE INDEX IF NOT EXISTS idx_llm_cache_created_at ON llm_cache(created_at);
+
+CREATE TABLE IF NOT EXISTS conversations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT NOT NULL,
+	role TEXT NOT NULL,
+	content TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_conversations_session_id ON conversations(session_id, id);
+
+CREATE TABLE IF NOT EXISTS memory_embeddings (
+	memory_id INTEGER NOT NULL,
+	model TEXT NOT NULL,
+	content_hash TEXT NOT NULL,
+	vector BLOB NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (memory_id, model)
+);
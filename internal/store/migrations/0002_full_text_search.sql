@@ -0,0 +1,50 @@
+-- Full-text indexes over memories and calendar events. The FTS5 tables only hold the
+-- index, the text is read from the original tables, and triggers keep them in sync.
+
+CREATE VIRTUAL TABLE memories_fts USING fts5(
+	content,
+	content = 'memories',
+	content_rowid = 'id'
+);
+
+CREATE TRIGGER memories_fts_insert AFTER INSERT ON memories BEGIN
+	INSERT INTO memories_fts (rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER memories_fts_delete AFTER DELETE ON memories BEGIN
+	INSERT INTO memories_fts (memories_fts, rowid, content) VALUES ('delete', old.id, old.content);
+END;
+
+CREATE TRIGGER memories_fts_update AFTER UPDATE OF content ON memories BEGIN
+	INSERT INTO memories_fts (memories_fts, rowid, content) VALUES ('delete', old.id, old.content);
+	INSERT INTO memories_fts (rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE VIRTUAL TABLE calendar_events_fts USING fts5(
+	summary,
+	location,
+	description,
+	content = 'calendar_events',
+	content_rowid = 'id'
+);
+
+CREATE TRIGGER calendar_events_fts_insert AFTER INSERT ON calendar_events BEGIN
+	INSERT INTO calendar_events_fts (rowid, summary, location, description)
+	VALUES (new.id, new.summary, new.location, new.description);
+END;
+
+CREATE TRIGGER calendar_events_fts_delete AFTER DELETE ON calendar_events BEGIN
+	INSERT INTO calendar_events_fts (calendar_events_fts, rowid, summary, location, description)
+	VALUES ('delete', old.id, old.summary, old.location, old.description);
+END;
+
+CREATE TRIGGER calendar_events_fts_update AFTER UPDATE OF summary, location, description ON calendar_events BEGIN
+	INSERT INTO calendar_events_fts (calendar_events_fts, rowid, summary, location, description)
+	VALUES ('delete', old.id, old.summary, old.location, old.description);
+	INSERT INTO calendar_events_fts (rowid, summary, location, description)
+	VALUES (new.id, new.summary, new.location, new.description);
+END;
+
+-- Index the existing rows
+INSERT INTO memories_fts (memories_fts) VALUES ('rebuild');
+INSERT INTO calendar_events_fts (calendar_events_fts) VALUES ('rebuild');
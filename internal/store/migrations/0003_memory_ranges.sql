@@ -0,0 +1,12 @@
+-- Memories are relevant from relevance_start to relevance_end, both inclusive dates,
+-- replacing the single relevance_date. Existing dated memories cover a single day.
+
+ALTER TABLE memories ADD COLUMN relevance_start TIMESTAMP;
+ALTER TABLE memories ADD COLUMN relevance_end TIMESTAMP;
+
+UPDATE memories SET relevance_start = relevance_date, relevance_end = relevance_date;
+
+DROP INDEX idx_memories_relevance_date;
+ALTER TABLE memories DROP COLUMN relevance_date;
+
+CREATE INDEX idx_memories_relevance ON memories(relevance_start, relevance_end);
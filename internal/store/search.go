@@ -0,0 +1,213 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// SearchKindMemory and SearchKindEvent are the kinds of search results
+	SearchKindMemory = "memory"
+	SearchKindEvent  = "event"
+
+	// HighlightStart and HighlightEnd surround the matching words in search snippets
+	HighlightStart = "**"
+	HighlightEnd   = "**"
+
+	// searchSnippetTokens is the length of a search snippet in words
+	searchSnippetTokens = 16
+	// defaultSearchLimit is the number of results returned if the filter has no limit
+	defaultSearchLimit = 20
+)
+
+// SearchFilter narrows down full-text search results. Empty fields don't filter.
+type SearchFilter struct {
+	// Kind limits the results to SearchKindMemory or SearchKindEvent
+	Kind string
+	// Source matches the source exactly or as a prefix followed by ":"
+	Source string
+	// From and To select the memories relevant on any day between their dates and the
+	// events starting between midnight of From and the midnight after To, in the locations
+	// of From and To. Memories relevant on all dates are left out when either is set.
+	From *time.Time
+	To   *time.Time
+	// Limit is the maximum number of results, 20 if not set
+	Limit int
+}
+
+// SearchResult is a memory or calendar event matching a full-text search
+type SearchResult struct {
+	Kind   string
+	ID     int64
+	Source string
+	// Date is the first relevance date of a memory or the start time of an event, nil for
+	// memories relevant on all dates
+	Date *time.Time
+	// EndDate is the last relevance date of a memory, nil for events
+	EndDate *time.Time
+	// Title is the summary of an event, empty for memories
+	Title string
+	// Snippet is the matching part of the text with the matching words between
+	// HighlightStart and HighlightEnd
+	Snippet string
+	// Rank is the bm25 score of the result, lower is a better match
+	Rank float64
+}
+
+// Search finds the memories and calendar events matching the words of the query, best
+// matches first. Every word must match, as a prefix, in any order.
+func (s *Store) Search(query string, filter SearchFilter) ([]SearchResult, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, errors.New("search query has no words")
+	}
+
+	var selects []string
+	var args []any
+	if filter.Kind == "" || filter.Kind == SearchKindMemory {
+		// The bm25 scores of the two tables aren't strictly comparable, but close enough
+		// for ordering a combined list
+		where, whereArgs := searchWhere("memories_fts", "m.source", "m.relevance_start", "m.relevance_end", match, filter)
+		selects = append(selects, `
+	SELECT 'memory', m.id, m.source, m.relevance_start, m.relevance_end, '',
+		snippet(memories_fts, 0, ?, ?, '…', ?), bm25(memories_fts) AS rank
+	FROM memories_fts
+	JOIN memories m ON m.id = memories_fts.rowid
+	`+where)
+		args = append(args, HighlightStart, HighlightEnd, searchSnippetTokens)
+		args = append(args, whereArgs...)
+	}
+	filterEvents := false
+	if filter.Kind == "" || filter.Kind == SearchKindEvent {
+		// Event start times are instants stored in the zone of the calendar, which the
+		// stored text can't be compared across, so they're filtered by date after the query
+		eventFilter := filter
+		eventFilter.From, eventFilter.To = nil, nil
+		filterEvents = filter.From != nil || filter.To != nil
+
+		// A match in the summary counts more than one in the location or description
+		where, whereArgs := searchWhere("calendar_events_fts", "e.source", "e.start_time", "e.start_time", match, eventFilter)
+		selects = append(selects, `
+	SELECT 'event', e.id, e.source, e.start_time, NULL, e.summary,
+		snippet(calendar_events_fts, -1, ?, ?, '…', ?), bm25(calendar_events_fts, 10.0, 2.0, 1.0) AS rank
+	FROM calendar_events_fts
+	JOIN calendar_events e ON e.id = calendar_events_fts.rowid
+	`+where)
+		args = append(args, HighlightStart, HighlightEnd, searchSnippetTokens)
+		args = append(args, whereArgs...)
+	}
+	if len(selects) == 0 {
+		return nil, fmt.Errorf("unknown search result kind %q", filter.Kind)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	sqlQuery := strings.Join(selects, "UNION ALL") + `
+	ORDER BY rank
+	`
+	// With events filtered afterwards the limit is applied to the filtered results
+	if !filterEvents {
+		sqlQuery += "LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close database rows", "error", err)
+		}
+	}()
+
+	var results []SearchResult
+	for rows.Next() {
+		var result SearchResult
+		var date, endDate sql.NullTime
+		if err := rows.Scan(&result.Kind, &result.ID, &result.Source, &date, &endDate, &result.Title, &result.Snippet, &result.Rank); err != nil {
+			return nil, fmt.Errorf("failed to scan search result row: %w", err)
+		}
+		if date.Valid {
+			result.Date = &date.Time
+		}
+		if endDate.Valid {
+			result.EndDate = &endDate.Time
+		}
+		if result.Kind == SearchKindEvent && filterEvents && result.Date != nil && !eventInRange(*result.Date, filter) {
+			continue
+		}
+		results = append(results, result)
+		if len(results) == limit {
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search result rows: %w", err)
+	}
+
+	return results, nil
+}
+
+// eventInRange reports whether an event starting at start is on the dates of the filter:
+// from midnight of From up to the midnight after To, in the locations of From and To
+func eventInRange(start time.Time, filter SearchFilter) bool {
+	if filter.From != nil && start.Before(startOfDay(*filter.From)) {
+		return false
+	}
+	if filter.To != nil && !start.Before(startOfDay(*filter.To).AddDate(0, 0, 1)) {
+		return false
+	}
+	return true
+}
+
+// startOfDay returns midnight at the start of the day of t, in the location of t
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// searchWhere builds the WHERE clause and arguments of a search over the FTS table with
+// the filter applied to the source column and the range between the date columns
+func searchWhere(table, sourceColumn, startColumn, endColumn, match string, filter SearchFilter) (string, []any) {
+	conditions := []string{table + " MATCH ?"}
+	args := []any{match}
+
+	if filter.Source != "" {
+		condition, sourceArgs := sourceCondition(sourceColumn, filter.Source)
+		conditions = append(conditions, condition)
+		args = append(args, sourceArgs...)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, dateOf(endColumn)+" >= ?")
+		args = append(args, filter.From.Format(dateLayout))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, dateOf(startColumn)+" <= ?")
+		args = append(args, filter.To.Format(dateLayout))
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND ") + "\n", args
+}
+
+// ftsQuery turns free text into an FTS5 query matching every word as a prefix. Words are
+// quoted, so the FTS5 operators and punctuation in the text are matched literally instead
+// of being parsed. It returns an empty string if the text has no words.
+func ftsQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = `"` + word + `"*`
+	}
+	return strings.Join(terms, " ")
+}
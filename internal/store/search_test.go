@@ -0,0 +1,175 @@
+package store
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "single word", text: "dentist", want: `"dentist"*`},
+		{name: "several words", text: "school trip", want: `"school"* "trip"*`},
+		{name: "finnish letters", text: "Hammaslääkäri", want: `"Hammaslääkäri"*`},
+		{name: "operators and quotes", text: `trip OR "bus" -NEAR(x)`, want: `"trip"* "OR"* "bus"* "NEAR"* "x"*`},
+		{name: "punctuation only", text: `*:"()`, want: ""},
+		{name: "empty", text: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ftsQuery(tt.text); got != tt.want {
+				t.Errorf("ftsQuery(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchWhere(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	where, args := searchWhere("memories_fts", "m.source", "m.relevance_start", "m.relevance_end", `"trip"*`, SearchFilter{Source: "manual", From: &from, To: &to})
+
+	wantWhere := "WHERE memories_fts MATCH ? AND (m.source = ? OR substr(m.source, 1, ?) = ?) AND substr(m.relevance_end, 1, 10) >= ? AND substr(m.relevance_start, 1, 10) <= ?\n"
+	if where != wantWhere {
+		t.Errorf("searchWhere() where = %q, want %q", where, wantWhere)
+	}
+	wantArgs := []any{`"trip"*`, "manual", 7, "manual:", "2024-01-01", "2024-12-31"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("searchWhere() args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestSearchEventRanking(t *testing.T) {
+	s := newTestStore(t)
+
+	start := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	location := "Birthday hall"
+	description := "Bring a birthday card, the birthday boy likes cars"
+	if _, err := s.AddCalendarEvent("party", "Party", start, nil, &location, &description, "calendar:Family"); err != nil {
+		t.Fatalf("AddCalendarEvent() error = %v", err)
+	}
+	longDescription := "Cake and coffee with the whole family at grandma's place, remember to buy flowers on the way"
+	if _, err := s.AddCalendarEvent("grandma", "Grandma's birthday", start.AddDate(0, 0, 1), nil, nil, &longDescription, "calendar:Family"); err != nil {
+		t.Fatalf("AddCalendarEvent() error = %v", err)
+	}
+
+	for _, kind := range []string{"", SearchKindEvent} {
+		results, err := s.Search("birthday", SearchFilter{Kind: kind})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(results) != 2 {
+			t.Fatalf("Search() with kind %q returned %d results, want 2", kind, len(results))
+		}
+		// A match in the summary ranks above matches in the location and description
+		if results[0].Title != "Grandma's birthday" {
+			t.Errorf("Search() with kind %q ranked %q first, want the summary match", kind, results[0].Title)
+		}
+		if results[0].Rank > results[1].Rank {
+			t.Errorf("Search() with kind %q results aren't ordered by rank: %v > %v", kind, results[0].Rank, results[1].Rank)
+		}
+	}
+}
+
+func TestSearchEventLocalDates(t *testing.T) {
+	s := newTestStore(t)
+
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	// 01:00 on October 16th in Helsinki is still October 15th in UTC
+	if _, err := s.AddCalendarEvent("early", "Early flight", time.Date(2026, 10, 15, 22, 0, 0, 0, time.UTC), nil, nil, nil, "calendar:Family"); err != nil {
+		t.Fatalf("AddCalendarEvent() error = %v", err)
+	}
+	if _, err := s.AddCalendarEvent("late", "Late flight", time.Date(2026, 10, 16, 23, 30, 0, 0, helsinki), nil, nil, nil, "calendar:Family"); err != nil {
+		t.Fatalf("AddCalendarEvent() error = %v", err)
+	}
+
+	day := func(d int) *time.Time {
+		date := time.Date(2026, 10, d, 0, 0, 0, 0, helsinki)
+		return &date
+	}
+	tests := []struct {
+		name   string
+		filter SearchFilter
+		want   []string
+	}{
+		{"no dates", SearchFilter{}, []string{"Early flight", "Late flight"}},
+		{"on the local day", SearchFilter{From: day(16), To: day(16)}, []string{"Early flight", "Late flight"}},
+		{"from the local day", SearchFilter{From: day(16)}, []string{"Early flight", "Late flight"}},
+		{"to the day before", SearchFilter{To: day(15)}, nil},
+		{"from the day after", SearchFilter{From: day(17)}, nil},
+		// The limit applies after the dates, so either one of the two events is returned
+		{"limited", SearchFilter{From: day(16), Limit: 1}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Kind = SearchKindEvent
+			results, err := s.Search("flight", tt.filter)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			var got []string
+			for _, result := range results {
+				got = append(got, result.Title)
+			}
+			slices.Sort(got)
+			if tt.filter.Limit > 0 {
+				if len(got) != tt.filter.Limit {
+					t.Errorf("Search() returned %v, want %d results", got, tt.filter.Limit)
+				}
+				return
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Search() returned %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchFollowsMemoryChanges(t *testing.T) {
+	s := newTestStore(t)
+
+	id, err := s.AddMemory("The car is serviced at Autohuolto", nil, "manual", nil)
+	if err != nil {
+		t.Fatalf("AddMemory() error = %v", err)
+	}
+
+	searchCount := func(query string) int {
+		t.Helper()
+		results, err := s.Search(query, SearchFilter{})
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", query, err)
+		}
+		return len(results)
+	}
+
+	if got := searchCount("autohuolto"); got != 1 {
+		t.Errorf("Search() after adding found %d memories, want 1", got)
+	}
+
+	if err := s.UpdateMemory(id, "The bike is serviced at Pyörähuolto", nil, nil); err != nil {
+		t.Fatalf("UpdateMemory() error = %v", err)
+	}
+	if got := searchCount("autohuolto"); got != 0 {
+		t.Errorf("Search() for the old content after editing found %d memories, want 0", got)
+	}
+	if got := searchCount("pyörähuolto"); got != 1 {
+		t.Errorf("Search() for the new content after editing found %d memories, want 1", got)
+	}
+
+	if err := s.DeleteMemory(id); err != nil {
+		t.Fatalf("DeleteMemory() error = %v", err)
+	}
+	if got := searchCount("pyörähuolto"); got != 0 {
+		t.Errorf("Search() after deleting found %d memories, want 0", got)
+	}
+}
@@ -2,8 +2,12 @@ package store
 
 import (
 	"database/sql"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
@@ -24,14 +28,65 @@ type CalendarEvent struct {
 
 // Memory represents a single memory entry in the database
 type Memory struct {
-	ID            int64
-	Content       string
-	CreatedAt     time.Time
-	RelevanceDate *time.Time // Pointer to allow NULL values
-	Source        string
-	UID           *string // Pointer to allow NULL values, used for unique identification (e.g., calendar event UID)
+	ID        int64
+	Content   string
+	CreatedAt time.Time
+	// RelevanceStart and RelevanceEnd are the first and last day the memory is relevant on,
+	// the same day for most memories. Both are nil for memories relevant on all dates.
+	RelevanceStart *time.Time
+	RelevanceEnd   *time.Time
+	Source         string
+	UID            *string // Pointer to allow NULL values, used for unique identification (e.g., calendar event UID)
 }
 
+// LLMCall represents a single LLM API call in the call ledger
+type LLMCall struct {
+	ID           int64
+	Provider     string
+	Model        string
+	PromptKey    string
+	PromptHash   string
+	InputTokens  int
+	OutputTokens int
+	LatencyMS    int64
+	Error        *string // Pointer to allow NULL values, set if the call failed
+	Cost         float64 // Estimated cost in euros
+	CreatedAt    time.Time
+}
+
+// LLMUsage holds the aggregated LLM usage for a single day or month
+type LLMUsage struct {
+	Period       string // "2006-01-02" for days, "2006-01" for months
+	Calls        int
+	Errors       int
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+}
+
+// ConversationTurn represents a single turn of a chat session in the database
+type ConversationTurn struct {
+	ID        int64
+	SessionID string
+	Role      string // "user", "assistant" or "summary"
+	Content   string
+	CreatedAt time.Time
+}
+
+// MemoryEmbedding is the embedding vector of a memory for one embedding model
+type MemoryEmbedding struct {
+	MemoryID    int64
+	Model       string
+	ContentHash string // Hash of the embedded content, to notice edited memories
+	Vector      []float32
+}
+
+// Usage periods for GetLLMUsage
+const (
+	UsagePeriodDay   = "day"
+	UsagePeriodMonth = "month"
+)
+
 // Store handles database operations
 type Store struct {
 	db *sql.DB
@@ -52,64 +107,362 @@ func (s *Store) Close() error {
 	return s.db.Close()
 }
 
-// Initialize creates the necessary tables if they don't exist
+// Initialize brings the database schema up to date by applying the pending migrations,
+// creating the tables on a new database
 func (s *Store) Initialize() error {
-	// Create memories table
-	memoriesQuery := `
-	CREATE TABLE IF NOT EXISTS memories (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		content TEXT NOT NULL,
-		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		relevance_date TIMESTAMP,
-		source TEXT NOT NULL,
-		uid TEXT
-	);
-	CREATE INDEX IF NOT EXISTS idx_memories_relevance_date ON memories(relevance_date);
-	CREATE INDEX IF NOT EXISTS idx_memories_source ON memories(source);
-	CREATE INDEX IF NOT EXISTS idx_memories_source_uid ON memories(source, uid);
+	if _, err := s.Migrate(); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return nil
+}
+
+// AddLLMCall records a single LLM API call in the call ledger
+func (s *Store) AddLLMCall(call LLMCall) (int64, error) {
+	query := `
+	INSERT INTO llm_calls (provider, model, prompt_key, prompt_hash, input_tokens, output_tokens, latency_ms, error, cost)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := s.db.Exec(memoriesQuery)
-	if err != nil {
-		return fmt.Errorf("failed to create memories table: %w", err)
-	}
-
-	// Create calendar_events table
-	calendarEventsQuery := `
-	CREATE TABLE IF NOT EXISTS calendar_events (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		uid TEXT NOT NULL,
-		summary TEXT NOT NULL,
-		start_time TIMESTAMP NOT NULL,
-		end_time TIMESTAMP,
-		location TEXT,
-		description TEXT,
-		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		source TEXT NOT NULL
-	);
-	CREATE INDEX IF NOT EXISTS idx_calendar_events_start_time ON calendar_events(start_time);
-	CREATE INDEX IF NOT EXISTS idx_calendar_events_end_time ON calendar_events(end_time);
-	CREATE INDEX IF NOT EXISTS idx_calendar_events_source ON calendar_events(source);
-	CREATE INDEX IF NOT EXISTS idx_calendar_events_source_uid ON calendar_events(source, uid);
-	CREATE INDEX IF NOT EXISTS idx_calendar_events_uid ON calendar_events(uid);
+	result, err := s.db.Exec(query, call.Provider, call.Model, call.PromptKey, call.PromptHash,
+		call.InputTokens, call.OutputTokens, call.LatencyMS, call.Error, call.Cost)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add LLM call: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// AddConversationTurn adds a turn to a chat session
+func (s *Store) AddConversationTurn(sessionID, role, content string) (int64, error) {
+	query := `
+	INSERT INTO conversations (session_id, role, content)
+	VALUES (?, ?, ?)
 	`
 
-	_, err = s.db.Exec(calendarEventsQuery)
+	result, err := s.db.Exec(query, sessionID, role, content)
 	if err != nil {
-		return fmt.Errorf("failed to create calendar_events table: %w", err)
+		return 0, fmt.Errorf("failed to add conversation turn: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetConversationTurns retrieves all turns of a chat session, oldest first
+func (s *Store) GetConversationTurns(sessionID string) ([]ConversationTurn, error) {
+	query := `
+	SELECT id, session_id, role, content, created_at
+	FROM conversations
+	WHERE session_id = ?
+	ORDER BY id ASC
+	`
+
+	rows, err := s.db.Query(query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversation turns: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close database rows", "error", err)
+		}
+	}()
+
+	var turns []ConversationTurn
+	for rows.Next() {
+		var turn ConversationTurn
+		if err := rows.Scan(&turn.ID, &turn.SessionID, &turn.Role, &turn.Content, &turn.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation turn row: %w", err)
+		}
+		turns = append(turns, turn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating conversation turn rows: %w", err)
+	}
+
+	return turns, nil
+}
+
+// CompactConversation replaces the turns of a chat session up to and including upToID
+// with a single summary turn, keeping the order of the remaining turns
+func (s *Store) CompactConversation(sessionID string, upToID int64, summary string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// The last summarized turn becomes the summary so it stays before the remaining turns
+	_, err = tx.Exec(`UPDATE conversations SET role = 'summary', content = ? WHERE session_id = ? AND id = ?`, summary, sessionID, upToID)
+	if err != nil {
+		return fmt.Errorf("failed to store conversation summary: %w", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM conversations WHERE session_id = ? AND id < ?`, sessionID, upToID)
+	if err != nil {
+		return fmt.Errorf("failed to delete summarized conversation turns: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit conversation summary: %w", err)
 	}
 
 	return nil
 }
 
-// AddMemory adds a new memory to the database
-func (s *Store) AddMemory(content string, relevanceDate *time.Time, source string, uid *string) (int64, error) {
+// DeleteConversation deletes all turns of a chat session
+func (s *Store) DeleteConversation(sessionID string) error {
+	query := `DELETE FROM conversations WHERE session_id = ?`
+
+	_, err := s.db.Exec(query, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete conversation: %w", err)
+	}
+
+	return nil
+}
+
+// GetMemoryEmbeddings retrieves the stored embeddings of existing memories for an
+// embedding model, keyed by memory ID
+func (s *Store) GetMemoryEmbeddings(model string) (map[int64]MemoryEmbedding, error) {
 	query := `
-	INSERT INTO memories (content, relevance_date, source, uid)
+	SELECT e.memory_id, e.model, e.content_hash, e.vector
+	FROM memory_embeddings e
+	JOIN memories m ON m.id = e.memory_id
+	WHERE e.model = ?
+	`
+
+	rows, err := s.db.Query(query, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memory embeddings: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close database rows", "error", err)
+		}
+	}()
+
+	embeddings := make(map[int64]MemoryEmbedding)
+	for rows.Next() {
+		var embedding MemoryEmbedding
+		var vector []byte
+		if err := rows.Scan(&embedding.MemoryID, &embedding.Model, &embedding.ContentHash, &vector); err != nil {
+			return nil, fmt.Errorf("failed to scan memory embedding row: %w", err)
+		}
+		embedding.Vector = decodeVector(vector)
+		embeddings[embedding.MemoryID] = embedding
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating memory embedding rows: %w", err)
+	}
+
+	return embeddings, nil
+}
+
+// SetMemoryEmbedding stores the embedding of a memory, replacing any earlier embedding
+// from the same model
+func (s *Store) SetMemoryEmbedding(embedding MemoryEmbedding) error {
+	query := `
+	INSERT OR REPLACE INTO memory_embeddings (memory_id, model, content_hash, vector)
 	VALUES (?, ?, ?, ?)
 	`
 
-	result, err := s.db.Exec(query, content, relevanceDate, source, uid)
+	_, err := s.db.Exec(query, embedding.MemoryID, embedding.Model, embedding.ContentHash, encodeVector(embedding.Vector))
+	if err != nil {
+		return fmt.Errorf("failed to store memory embedding: %w", err)
+	}
+
+	return nil
+}
+
+// encodeVector encodes a vector as little-endian float32 values
+func encodeVector(vector []float32) []byte {
+	buf := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(value))
+	}
+	return buf
+}
+
+// decodeVector decodes a vector encoded by encodeVector
+func decodeVector(buf []byte) []float32 {
+	vector := make([]float32, len(buf)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vector
+}
+
+// GetCachedResponse returns the cached LLM response for the prompt hash, if one was stored
+// at or after notBefore
+func (s *Store) GetCachedResponse(provider, model, promptHash string, notBefore time.Time) (string, bool, error) {
+	query := `
+	SELECT response
+	FROM llm_cache
+	WHERE provider = ? AND model = ? AND prompt_hash = ? AND created_at >= ?
+	`
+
+	var response string
+	err := s.db.QueryRow(query, provider, model, promptHash, notBefore.UTC().Format(time.DateTime)).Scan(&response)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to query cached response: %w", err)
+	}
+
+	return response, true, nil
+}
+
+// PutCachedResponse stores the LLM response for the prompt hash, replacing any earlier one
+func (s *Store) PutCachedResponse(provider, model, promptHash, response string) error {
+	query := `
+	INSERT OR REPLACE INTO llm_cache (provider, model, prompt_hash, response, created_at)
+	VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	_, err := s.db.Exec(query, provider, model, promptHash, response)
+	if err != nil {
+		return fmt.Errorf("failed to cache response: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCachedResponsesBefore deletes the cached LLM responses stored before the given time
+func (s *Store) DeleteCachedResponsesBefore(before time.Time) (int64, error) {
+	query := `DELETE FROM llm_cache WHERE created_at < ?`
+
+	result, err := s.db.Exec(query, before.UTC().Format(time.DateTime))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete cached responses: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// GetLLMCostSince returns the total estimated cost of the LLM calls made since the given time
+func (s *Store) GetLLMCostSince(since time.Time) (float64, error) {
+	query := `SELECT COALESCE(SUM(cost), 0) FROM llm_calls WHERE created_at >= ?`
+
+	var cost float64
+	err := s.db.QueryRow(query, since.UTC().Format(time.DateTime)).Scan(&cost)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query LLM cost: %w", err)
+	}
+
+	return cost, nil
+}
+
+// GetLLMUsage returns the LLM usage totals per day or month (see UsagePeriodDay and
+// UsagePeriodMonth) for calls made since the given time, in local time and newest first
+func (s *Store) GetLLMUsage(period string, since time.Time) ([]LLMUsage, error) {
+	var format string
+	switch period {
+	case UsagePeriodDay:
+		format = "%Y-%m-%d"
+	case UsagePeriodMonth:
+		format = "%Y-%m"
+	default:
+		return nil, fmt.Errorf("unknown usage period: %s", period)
+	}
+
+	// created_at is stored in UTC by CURRENT_TIMESTAMP
+	query := `
+	SELECT strftime(?, created_at, 'localtime') AS period,
+		COUNT(*),
+		COUNT(error),
+		COALESCE(SUM(input_tokens), 0),
+		COALESCE(SUM(output_tokens), 0),
+		COALESCE(SUM(cost), 0)
+	FROM llm_calls
+	WHERE created_at >= ?
+	GROUP BY period
+	ORDER BY period DESC
+	`
+
+	rows, err := s.db.Query(query, format, since.UTC().Format(time.DateTime))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query LLM usage: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close database rows", "error", err)
+		}
+	}()
+
+	var usage []LLMUsage
+	for rows.Next() {
+		var u LLMUsage
+		if err := rows.Scan(&u.Period, &u.Calls, &u.Errors, &u.InputTokens, &u.OutputTokens, &u.Cost); err != nil {
+			return nil, fmt.Errorf("failed to scan LLM usage row: %w", err)
+		}
+		usage = append(usage, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating LLM usage rows: %w", err)
+	}
+
+	return usage, nil
+}
+
+// AddBrief records a generated brief together with the provider and model that produced it
+func (s *Store) AddBrief(content, provider, model string) (int64, error) {
+	query := `
+	INSERT INTO briefs (content, provider, model)
+	VALUES (?, ?, ?)
+	`
+
+	result, err := s.db.Exec(query, content, provider, model)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add brief: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// AddMemory adds a new memory to the database, relevant on a single day or, with a nil
+// relevance date, on all dates
+func (s *Store) AddMemory(content string, relevanceDate *time.Time, source string, uid *string) (int64, error) {
+	return s.AddMemoryRange(content, relevanceDate, relevanceDate, source, uid)
+}
+
+// AddMemoryRange adds a new memory to the database, relevant on every day from start to
+// end. Both are nil for a memory relevant on all dates.
+func (s *Store) AddMemoryRange(content string, start, end *time.Time, source string, uid *string) (int64, error) {
+	if (start == nil) != (end == nil) {
+		return 0, errors.New("relevance start and end must both be set or both be empty")
+	}
+
+	query := `
+	INSERT INTO memories (content, relevance_start, relevance_end, source, uid)
+	VALUES (?, ?, ?, ?, ?)
+	`
+
+	result, err := s.db.Exec(query, content, start, end, source, uid)
 	if err != nil {
 		return 0, fmt.Errorf("failed to add memory: %w", err)
 	}
@@ -122,16 +475,18 @@ func (s *Store) AddMemory(content string, relevanceDate *time.Time, source strin
 	return id, nil
 }
 
-// GetRelevantMemories retrieves memories relevant for a specific date range
+// GetRelevantMemories retrieves the memories relevant on any day of a date range: the
+// ones whose relevance range overlaps it and the ones relevant on all dates. The days are
+// the calendar dates of startDate and endDate in their own time zones.
 func (s *Store) GetRelevantMemories(startDate, endDate time.Time) ([]Memory, error) {
 	query := `
-	SELECT id, content, created_at, relevance_date, source, uid
+	SELECT ` + memoryColumns + `
 	FROM memories
-	WHERE (relevance_date IS NULL OR (relevance_date >= ? AND relevance_date <= ?))
-	ORDER BY CASE WHEN relevance_date IS NULL THEN 1 ELSE 0 END, relevance_date ASC
+	WHERE (relevance_start IS NULL OR (` + dateOf("relevance_start") + ` <= ? AND ` + dateOf("relevance_end") + ` >= ?))
+	ORDER BY CASE WHEN relevance_start IS NULL THEN 1 ELSE 0 END, relevance_start ASC, relevance_end ASC
 	`
 
-	rows, err := s.db.Query(query, startDate, endDate)
+	rows, err := s.db.Query(query, endDate.Format(dateLayout), startDate.Format(dateLayout))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query memories: %w", err)
 	}
@@ -143,23 +498,10 @@ func (s *Store) GetRelevantMemories(startDate, endDate time.Time) ([]Memory, err
 
 	var memories []Memory
 	for rows.Next() {
-		var memory Memory
-		var relevanceDate sql.NullTime
-
-		var uid sql.NullString
-		err := rows.Scan(&memory.ID, &memory.Content, &memory.CreatedAt, &relevanceDate, &memory.Source, &uid)
+		memory, err := scanMemory(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan memory row: %w", err)
+			return nil, err
 		}
-
-		if relevanceDate.Valid {
-			memory.RelevanceDate = &relevanceDate.Time
-		}
-
-		if uid.Valid {
-			memory.UID = &uid.String
-		}
-
 		memories = append(memories, memory)
 	}
 
@@ -170,9 +512,10 @@ func (s *Store) GetRelevantMemories(startDate, endDate time.Time) ([]Memory, err
 	return memories, nil
 }
 
-// DeleteMemoriesBySourceAndDate deletes all memories with the given source and relevance date
+// DeleteMemoriesBySourceAndDate deletes all memories with the given source starting on the
+// relevance date
 func (s *Store) DeleteMemoriesBySourceAndDate(source string, relevanceDate time.Time) error {
-	query := `DELETE FROM memories WHERE source = ? AND relevance_date = ?`
+	query := `DELETE FROM memories WHERE source = ? AND relevance_start = ?`
 	_, err := s.db.Exec(query, source, relevanceDate)
 	if err != nil {
 		return fmt.Errorf("failed to delete memories: %w", err)
@@ -180,12 +523,13 @@ func (s *Store) DeleteMemoriesBySourceAndDate(source string, relevanceDate time.
 	return nil
 }
 
-// MemoryExists checks if a memory with the given source, uid, and relevance date already exists
+// MemoryExists checks if a memory with the given source and uid starting on the relevance
+// date already exists
 func (s *Store) MemoryExists(source string, uid string, relevanceDate time.Time) (bool, error) {
 	query := `
 	SELECT COUNT(*)
 	FROM memories
-	WHERE source = ? AND uid = ? AND relevance_date = ?
+	WHERE source = ? AND uid = ? AND relevance_start = ?
 	`
 
 	var count int
@@ -200,7 +544,7 @@ func (s *Store) MemoryExists(source string, uid string, relevanceDate time.Time)
 // GetMemoriesBySource retrieves memories from a specific source
 func (s *Store) GetMemoriesBySource(source string) ([]Memory, error) {
 	query := `
-	SELECT id, content, created_at, relevance_date, source, uid
+	SELECT ` + memoryColumns + `
 	FROM memories
 	WHERE source = ?
 	ORDER BY created_at DESC
@@ -218,23 +562,126 @@ func (s *Store) GetMemoriesBySource(source string) ([]Memory, error) {
 
 	var memories []Memory
 	for rows.Next() {
-		var memory Memory
-		var relevanceDate sql.NullTime
-
-		var uid sql.NullString
-		err := rows.Scan(&memory.ID, &memory.Content, &memory.CreatedAt, &relevanceDate, &memory.Source, &uid)
+		memory, err := scanMemory(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan memory row: %w", err)
+			return nil, err
 		}
+		memories = append(memories, memory)
+	}
 
-		if relevanceDate.Valid {
-			memory.RelevanceDate = &relevanceDate.Time
-		}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating memory rows: %w", err)
+	}
 
-		if uid.Valid {
-			memory.UID = &uid.String
+	return memories, nil
+}
+
+// ErrMemoryNotFound is returned when no memory has the requested ID
+var ErrMemoryNotFound = errors.New("memory not found")
+
+// MemoryFilter selects memories to list. Empty fields don't filter.
+type MemoryFilter struct {
+	// Source matches the source exactly or as a prefix followed by ":", so "calendar"
+	// covers "calendar:Family"
+	Source string
+	// From and To select the memories relevant on any day between their dates, both
+	// inclusive. Memories relevant on all dates are left out when either is set.
+	From *time.Time
+	To   *time.Time
+	// EndsBefore selects the memories whose relevance ended before its date, leaving out
+	// the ones still relevant on it and the ones relevant on all dates
+	EndsBefore *time.Time
+	// Text matches memories containing it, ignoring case
+	Text  string
+	Limit int
+}
+
+// memoryFilterWhere builds the WHERE clause and arguments selecting the memories
+// matching the filter, or an empty clause if the filter matches every memory
+func memoryFilterWhere(filter MemoryFilter) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if filter.Source != "" {
+		condition, sourceArgs := sourceCondition("source", filter.Source)
+		conditions = append(conditions, condition)
+		args = append(args, sourceArgs...)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, dateOf("relevance_end")+" >= ?")
+		args = append(args, filter.From.Format(dateLayout))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, dateOf("relevance_start")+" <= ?")
+		args = append(args, filter.To.Format(dateLayout))
+	}
+	if filter.EndsBefore != nil {
+		conditions = append(conditions, dateOf("relevance_end")+" < ?")
+		args = append(args, filter.EndsBefore.Format(dateLayout))
+	}
+	if filter.Text != "" {
+		conditions = append(conditions, `content LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(filter.Text)+"%")
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// sourceCondition builds a condition matching the source column exactly or as a prefix
+// followed by ":"
+func sourceCondition(column, source string) (string, []any) {
+	prefix := source + ":"
+	return fmt.Sprintf("(%[1]s = ? OR substr(%[1]s, 1, ?) = ?)", column), []any{source, len(prefix), prefix}
+}
+
+// dateLayout is the layout of the calendar dates compared with dateOf
+const dateLayout = "2006-01-02"
+
+// dateOf returns an expression for the calendar date of a time column. Times are stored as
+// text starting with the date in the zone they were given in, so comparing dates instead
+// of the text compares the days the user meant regardless of the zone.
+func dateOf(column string) string {
+	return "substr(" + column + ", 1, 10)"
+}
+
+// escapeLike escapes the LIKE wildcards in the text so it matches literally
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+}
+
+// ListMemories retrieves the memories matching the filter, newest first
+func (s *Store) ListMemories(filter MemoryFilter) ([]Memory, error) {
+	where, args := memoryFilterWhere(filter)
+	query := `
+	SELECT ` + memoryColumns + `
+	FROM memories
+	` + where + `
+	ORDER BY created_at DESC, id DESC
+	`
+	if filter.Limit > 0 {
+		query += "LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memories: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close database rows", "error", err)
 		}
+	}()
 
+	var memories []Memory
+	for rows.Next() {
+		memory, err := scanMemory(rows)
+		if err != nil {
+			return nil, err
+		}
 		memories = append(memories, memory)
 	}
 
@@ -245,6 +692,240 @@ func (s *Store) GetMemoriesBySource(source string) ([]Memory, error) {
 	return memories, nil
 }
 
+// GetMemory retrieves a single memory, returning ErrMemoryNotFound if there's no memory
+// with the ID
+func (s *Store) GetMemory(id int64) (Memory, error) {
+	query := `
+	SELECT ` + memoryColumns + `
+	FROM memories
+	WHERE id = ?
+	`
+
+	memory, err := scanMemory(s.db.QueryRow(query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Memory{}, ErrMemoryNotFound
+	}
+	return memory, err
+}
+
+// memoryColumns are the columns of a memory in the order scanMemory reads them
+const memoryColumns = "id, content, created_at, relevance_start, relevance_end, source, uid"
+
+// scanMemory scans a memory from a row selecting memoryColumns
+func scanMemory(row interface{ Scan(...any) error }) (Memory, error) {
+	var memory Memory
+	var relevanceStart, relevanceEnd sql.NullTime
+	var uid sql.NullString
+
+	err := row.Scan(&memory.ID, &memory.Content, &memory.CreatedAt, &relevanceStart, &relevanceEnd, &memory.Source, &uid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Memory{}, err
+	}
+	if err != nil {
+		return Memory{}, fmt.Errorf("failed to scan memory row: %w", err)
+	}
+
+	if relevanceStart.Valid && relevanceEnd.Valid {
+		memory.RelevanceStart = &relevanceStart.Time
+		memory.RelevanceEnd = &relevanceEnd.Time
+	}
+	if uid.Valid {
+		memory.UID = &uid.String
+	}
+
+	return memory, nil
+}
+
+// UpdateMemory changes the content and relevance range of a memory, returning
+// ErrMemoryNotFound if there's no memory with the ID. A nil start and end make the memory
+// relevant for all dates. The embeddings are refreshed on the next retrieval since the
+// content hash changes.
+func (s *Store) UpdateMemory(id int64, content string, start, end *time.Time) error {
+	if (start == nil) != (end == nil) {
+		return errors.New("relevance start and end must both be set or both be empty")
+	}
+
+	query := `UPDATE memories SET content = ?, relevance_start = ?, relevance_end = ? WHERE id = ?`
+	result, err := s.db.Exec(query, content, start, end, id)
+	if err != nil {
+		return fmt.Errorf("failed to update memory: %w", err)
+	}
+
+	return requireAffected(result, ErrMemoryNotFound)
+}
+
+// DeleteMemory deletes a memory and its embeddings, returning ErrMemoryNotFound if there's
+// no memory with the ID
+func (s *Store) DeleteMemory(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.Exec(`DELETE FROM memories WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete memory: %w", err)
+	}
+	if err := requireAffected(result, ErrMemoryNotFound); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM memory_embeddings WHERE memory_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete memory embeddings: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit memory deletion: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteMemories deletes the memories matching the filter and their embeddings, returning
+// the number of memories deleted. The limit of the filter is ignored.
+func (s *Store) DeleteMemories(filter MemoryFilter) (int64, error) {
+	where, args := memoryFilterWhere(filter)
+	return s.deleteMemoriesWhere(where, args)
+}
+
+// deleteMemoriesWhere deletes the memories matching the WHERE clause and their
+// embeddings in a single transaction, returning the number of memories deleted
+func (s *Store) deleteMemoriesWhere(where string, args []any) (int64, error) {
+	idQuery := "SELECT id FROM memories " + where
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Embeddings first, while the memories to delete can still be selected
+	if _, err := tx.Exec(`DELETE FROM memory_embeddings WHERE memory_id IN (`+idQuery+`)`, args...); err != nil {
+		return 0, fmt.Errorf("failed to delete memory embeddings: %w", err)
+	}
+
+	result, err := tx.Exec(`DELETE FROM memories WHERE id IN (`+idQuery+`)`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete memories: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get deleted memory count: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit memory deletion: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// PruneRule selects the memories of a single source to prune
+type PruneRule struct {
+	Source string // Exact source, e.g. "weather-metno:Helsinki"
+	// Before prunes the memories whose relevance ends before its date, or added before it
+	// if they're relevant on all dates. Nil doesn't prune by age.
+	Before *time.Time
+	// KeepLatestPerDate prunes all but the newest memory for each relevance start date
+	KeepLatestPerDate bool
+}
+
+// pruneWhere builds the WHERE clause and arguments selecting the memories the rule
+// prunes, or an empty clause if it prunes nothing
+func pruneWhere(rule PruneRule) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if rule.Before != nil {
+		// created_at is set by SQLite as UTC text, relevance_end is a stored time
+		conditions = append(conditions, "(relevance_end IS NOT NULL AND "+dateOf("relevance_end")+" < ?) OR (relevance_end IS NULL AND created_at < ?)")
+		args = append(args, rule.Before.Format(dateLayout), rule.Before.UTC().Format(time.DateTime))
+	}
+	if rule.KeepLatestPerDate {
+		// IDs grow in insertion order, so the newest memory of a day has the highest ID
+		conditions = append(conditions, `relevance_start IS NOT NULL AND id NOT IN (
+		SELECT MAX(id) FROM memories
+		WHERE source = ? AND relevance_start IS NOT NULL
+		GROUP BY `+dateOf("relevance_start")+`
+	)`)
+		args = append(args, rule.Source)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	for i, condition := range conditions {
+		conditions[i] = "(" + condition + ")"
+	}
+	return "WHERE source = ? AND (" + strings.Join(conditions, " OR ") + ")", append([]any{rule.Source}, args...)
+}
+
+// PruneMemories deletes the memories selected by the rule, returning the number deleted.
+// With dryRun nothing is deleted and the number that would be deleted is returned.
+func (s *Store) PruneMemories(rule PruneRule, dryRun bool) (int64, error) {
+	where, args := pruneWhere(rule)
+	if where == "" {
+		return 0, nil
+	}
+
+	if dryRun {
+		var count int64
+		if err := s.db.QueryRow("SELECT COUNT(*) FROM memories "+where, args...).Scan(&count); err != nil {
+			return 0, fmt.Errorf("failed to count memories to prune: %w", err)
+		}
+		return count, nil
+	}
+
+	return s.deleteMemoriesWhere(where, args)
+}
+
+// GetMemorySources returns the distinct sources of the stored memories, sorted
+func (s *Store) GetMemorySources() ([]string, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT source FROM memories ORDER BY source`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memory sources: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close database rows", "error", err)
+		}
+	}()
+
+	var sources []string
+	for rows.Next() {
+		var source string
+		if err := rows.Scan(&source); err != nil {
+			return nil, fmt.Errorf("failed to scan memory source row: %w", err)
+		}
+		sources = append(sources, source)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating memory source rows: %w", err)
+	}
+
+	return sources, nil
+}
+
+// requireAffected returns notFound if the statement didn't change any rows
+func requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
 // AddCalendarEvent adds a new calendar event to the database
 func (s *Store) AddCalendarEvent(uid, summary string, startTime time.Time, endTime *time.Time, location, description *string, source string) (int64, error) {
 	query := `
@@ -0,0 +1,280 @@
+package store
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMemoryFilterWhere(t *testing.T) {
+	from := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		filter    MemoryFilter
+		wantWhere string
+		wantArgs  []any
+	}{
+		{
+			name:   "no filter",
+			filter: MemoryFilter{Limit: 10},
+		},
+		{
+			name:      "source",
+			filter:    MemoryFilter{Source: "calendar"},
+			wantWhere: "WHERE (source = ? OR substr(source, 1, ?) = ?)",
+			wantArgs:  []any{"calendar", 9, "calendar:"},
+		},
+		{
+			name:      "date range",
+			filter:    MemoryFilter{From: &from, To: &to},
+			wantWhere: "WHERE substr(relevance_end, 1, 10) >= ? AND substr(relevance_start, 1, 10) <= ?",
+			wantArgs:  []any{"2025-04-01", "2025-04-30"},
+		},
+		{
+			name:      "ends before",
+			filter:    MemoryFilter{Source: "manual", EndsBefore: &from},
+			wantWhere: "WHERE (source = ? OR substr(source, 1, ?) = ?) AND substr(relevance_end, 1, 10) < ?",
+			wantArgs:  []any{"manual", 7, "manual:", "2025-04-01"},
+		},
+		{
+			name:      "text with wildcards",
+			filter:    MemoryFilter{Source: "manual", Text: "50%_off"},
+			wantWhere: `WHERE (source = ? OR substr(source, 1, ?) = ?) AND content LIKE ? ESCAPE '\'`,
+			wantArgs:  []any{"manual", 7, "manual:", `%50\%\_off%`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := memoryFilterWhere(tt.filter)
+			if where != tt.wantWhere {
+				t.Errorf("memoryFilterWhere() where = %q, want %q", where, tt.wantWhere)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("memoryFilterWhere() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestPruneWhere(t *testing.T) {
+	before := time.Date(2025, 3, 1, 12, 0, 0, 0, time.FixedZone("EET", 2*60*60))
+	keepLatest := `relevance_start IS NOT NULL AND id NOT IN (
+		SELECT MAX(id) FROM memories
+		WHERE source = ? AND relevance_start IS NOT NULL
+		GROUP BY substr(relevance_start, 1, 10)
+	)`
+	byAge := "(relevance_end IS NOT NULL AND substr(relevance_end, 1, 10) < ?) OR (relevance_end IS NULL AND created_at < ?)"
+
+	tests := []struct {
+		name      string
+		rule      PruneRule
+		wantWhere string
+		wantArgs  []any
+	}{
+		{
+			name: "nothing to prune",
+			rule: PruneRule{Source: "manual"},
+		},
+		{
+			name:      "by age",
+			rule:      PruneRule{Source: "electricity:FI", Before: &before},
+			wantWhere: "WHERE source = ? AND ((" + byAge + "))",
+			wantArgs:  []any{"electricity:FI", "2025-03-01", "2025-03-01 10:00:00"},
+		},
+		{
+			name:      "latest per date",
+			rule:      PruneRule{Source: "weather-metno:Helsinki", KeepLatestPerDate: true},
+			wantWhere: "WHERE source = ? AND ((" + keepLatest + "))",
+			wantArgs:  []any{"weather-metno:Helsinki", "weather-metno:Helsinki"},
+		},
+		{
+			name:      "both",
+			rule:      PruneRule{Source: "schoollunch:Kilo", Before: &before, KeepLatestPerDate: true},
+			wantWhere: "WHERE source = ? AND ((" + byAge + ") OR (" + keepLatest + "))",
+			wantArgs:  []any{"schoollunch:Kilo", "2025-03-01", "2025-03-01 10:00:00", "schoollunch:Kilo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := pruneWhere(tt.rule)
+			if where != tt.wantWhere {
+				t.Errorf("pruneWhere() where = %q, want %q", where, tt.wantWhere)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("pruneWhere() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestPruneMemories(t *testing.T) {
+	s := newTestStore(t)
+
+	day := func(d int) *time.Time {
+		date := time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	add := func(content string, date *time.Time, source string) {
+		t.Helper()
+		if _, err := s.AddMemory(content, date, source, nil); err != nil {
+			t.Fatalf("AddMemory() error = %v", err)
+		}
+	}
+
+	add("Old forecast for the 1st", day(1), "weather-metno:Helsinki")
+	add("New forecast for the 1st", day(1), "weather-metno:Helsinki")
+	add("Forecast for the 2nd", day(2), "weather-metno:Helsinki")
+	add("Prices for the 1st", day(1), "electricity:FI")
+	add("Prices for the 5th", day(5), "electricity:FI")
+	add("Undated note", nil, "electricity:FI")
+
+	before := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		rule     PruneRule
+		pruned   int64
+		remained []string
+	}{
+		{
+			rule:     PruneRule{Source: "weather-metno:Helsinki", KeepLatestPerDate: true},
+			pruned:   1,
+			remained: []string{"Forecast for the 2nd", "New forecast for the 1st"},
+		},
+		{
+			rule:     PruneRule{Source: "electricity:FI", Before: &before},
+			pruned:   1,
+			remained: []string{"Undated note", "Prices for the 5th"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule.Source, func(t *testing.T) {
+			count, err := s.PruneMemories(tt.rule, true)
+			if err != nil {
+				t.Fatalf("PruneMemories() dry run error = %v", err)
+			}
+			if count != tt.pruned {
+				t.Errorf("PruneMemories() dry run = %d, want %d", count, tt.pruned)
+			}
+
+			pruned, err := s.PruneMemories(tt.rule, false)
+			if err != nil {
+				t.Fatalf("PruneMemories() error = %v", err)
+			}
+			if pruned != tt.pruned {
+				t.Errorf("PruneMemories() = %d, want %d", pruned, tt.pruned)
+			}
+
+			memories, err := s.ListMemories(MemoryFilter{Source: tt.rule.Source})
+			if err != nil {
+				t.Fatalf("ListMemories() error = %v", err)
+			}
+			var remained []string
+			for _, memory := range memories {
+				remained = append(remained, memory.Content)
+			}
+			if !reflect.DeepEqual(remained, tt.remained) {
+				t.Errorf("memories after pruning = %v, want %v", remained, tt.remained)
+			}
+		})
+	}
+}
+
+func TestGetRelevantMemoriesRange(t *testing.T) {
+	s := newTestStore(t)
+
+	// Dates given on the command line are parsed as UTC midnight
+	start := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	if _, err := s.AddMemoryRange("Grandma visits", &start, &end, "manual", nil); err != nil {
+		t.Fatalf("AddMemoryRange() error = %v", err)
+	}
+
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %v", err)
+	}
+
+	tests := []struct {
+		day      int
+		relevant bool
+	}{
+		{day: 11, relevant: false},
+		{day: 12, relevant: true},
+		{day: 15, relevant: true},
+		{day: 18, relevant: true},
+		{day: 19, relevant: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("October %d", tt.day), func(t *testing.T) {
+			dayStart := time.Date(2026, 10, tt.day, 0, 0, 0, 0, helsinki)
+			dayEnd := dayStart.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+			memories, err := s.GetRelevantMemories(dayStart, dayEnd)
+			if err != nil {
+				t.Fatalf("GetRelevantMemories() error = %v", err)
+			}
+			if got := len(memories) == 1; got != tt.relevant {
+				t.Errorf("GetRelevantMemories() returned %d memories, want relevant = %v", len(memories), tt.relevant)
+			}
+		})
+	}
+}
+
+func TestDeleteMemoriesEndingBefore(t *testing.T) {
+	s := newTestStore(t)
+
+	day := func(d int) *time.Time {
+		date := time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	for _, memory := range []struct {
+		content    string
+		start, end *time.Time
+	}{
+		{"Finished trip", day(5), day(9)},
+		{"Grandma visits", day(12), day(18)},
+		{"Sauna evening", day(20), day(20)},
+		{"Undated note", nil, nil},
+	} {
+		if _, err := s.AddMemoryRange(memory.content, memory.start, memory.end, "manual", nil); err != nil {
+			t.Fatalf("AddMemoryRange() error = %v", err)
+		}
+	}
+
+	// Listing a date selects the ranges overlapping it
+	listed, err := s.ListMemories(MemoryFilter{Source: "manual", To: day(15)})
+	if err != nil {
+		t.Fatalf("ListMemories() error = %v", err)
+	}
+	if len(listed) != 2 {
+		t.Errorf("ListMemories() up to the 15th returned %d memories, want 2", len(listed))
+	}
+
+	// Deleting before a date keeps the range still running on it
+	deleted, err := s.DeleteMemories(MemoryFilter{Source: "manual", EndsBefore: day(15)})
+	if err != nil {
+		t.Fatalf("DeleteMemories() error = %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("DeleteMemories() = %d, want 1", deleted)
+	}
+
+	remaining, err := s.ListMemories(MemoryFilter{Source: "manual"})
+	if err != nil {
+		t.Fatalf("ListMemories() error = %v", err)
+	}
+	for _, memory := range remaining {
+		if memory.Content == "Finished trip" {
+			t.Error("the memory that ended before the date wasn't deleted")
+		}
+	}
+	if len(remaining) != 3 {
+		t.Errorf("%d memories remain, want 3", len(remaining))
+	}
+}